@@ -0,0 +1,175 @@
+package golden
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sivchari/golden/manager"
+)
+
+// archiveEntriesSection is the section listing the entries of an archive.
+const archiveEntriesSection = "<entries>"
+
+// archiveEntry is an entry of an archive, with its content if selected.
+type archiveEntry struct {
+	name string
+	mode fs.FileMode
+	size int64
+	data []byte
+}
+
+// AssertArchive compares the zip, tar or gzipped tar archive at path with
+// the golden file name. The golden file lists every entry with its size and
+// normalized permissions, leaving out timestamps and owners, followed by
+// the contents of the entries matching WithArchiveContents, as sections
+// like those of AssertDir. Contents are scrubbed like the files of
+// AssertDir.
+func (g *Golden) AssertArchive(name, path string) {
+	g.t.Helper()
+
+	filename := g.goldenFilename(name, "txt")
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Reading the snapshotted archive is the point
+	if err != nil {
+		g.t.Fatalf("Failed to read archive %s: %v", path, err)
+	}
+
+	entries, err := g.readArchive(data)
+	if err != nil {
+		g.t.Fatalf("Failed to read archive %s: %v", path, err)
+	}
+
+	g.assertFiles(filename, g.archiveSections(entries))
+}
+
+// readArchive reads the entries of a zip, tar or gzipped tar archive.
+func (g *Golden) readArchive(data []byte) ([]archiveEntry, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		return g.readZip(data)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress: %w", err)
+		}
+
+		return g.readTar(gz)
+	default:
+		return g.readTar(bytes.NewReader(data))
+	}
+}
+
+func (g *Golden) readZip(data []byte) ([]archiveEntry, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip: %w", err)
+	}
+
+	entries := make([]archiveEntry, 0, len(zr.File))
+
+	for _, file := range zr.File {
+		entry := archiveEntry{name: file.Name, mode: file.Mode(), size: int64(file.UncompressedSize64)} //nolint:gosec // Sizes beyond int64 are not realistic
+
+		if g.archiveContentSelected(entry) {
+			rc, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+			}
+
+			entry.data, err = io.ReadAll(rc)
+			_ = rc.Close()
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (g *Golden) readTar(r io.Reader) ([]archiveEntry, error) {
+	tr := tar.NewReader(r)
+
+	var entries []archiveEntry
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar: %w", err)
+		}
+
+		entry := archiveEntry{name: header.Name, mode: header.FileInfo().Mode(), size: header.Size}
+
+		if g.archiveContentSelected(entry) {
+			if entry.data, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+}
+
+// archiveContentSelected reports whether the content of entry is stored.
+func (g *Golden) archiveContentSelected(entry archiveEntry) bool {
+	if !entry.mode.IsRegular() {
+		return false
+	}
+
+	return slices.ContainsFunc(g.options.ArchiveContents, func(pattern string) bool {
+		return matchPath(pattern, strings.TrimSuffix(entry.name, "/"))
+	})
+}
+
+// archiveSections returns the listing of entries followed by the selected
+// contents, sorted by name.
+func (g *Golden) archiveSections(entries []archiveEntry) []manager.Section {
+	slices.SortFunc(entries, func(a, b archiveEntry) int { return strings.Compare(a.name, b.name) })
+
+	var listing strings.Builder
+
+	sections := []manager.Section{{Name: archiveEntriesSection}}
+
+	for _, entry := range entries {
+		fmt.Fprintf(&listing, "%s %10d %s\n", normalizeMode(entry.mode), entry.size, entry.name)
+
+		if entry.data != nil {
+			sections = append(sections, manager.Section{Name: entry.name, Data: g.scrubFile(entry.name, entry.data)})
+		}
+	}
+
+	sections[0].Data = []byte(listing.String())
+
+	return sections
+}
+
+// normalizeMode renders mode without the permission bits that vary with the
+// umask of the machine building the archive; only executability is kept.
+func normalizeMode(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "drwxr-xr-x"
+	case mode&fs.ModeSymlink != 0:
+		return "Lrwxrwxrwx"
+	case mode&0o111 != 0:
+		return "-rwxr-xr-x"
+	default:
+		return "-rw-r--r--"
+	}
+}
@@ -0,0 +1,250 @@
+package golden
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// jsonStyle is the layout JSON golden content is written in.
+type jsonStyle struct {
+	prefix, indent string
+	compact        bool
+	escapeHTML     bool
+}
+
+// defaultJSONStyle indents with two spaces.
+var defaultJSONStyle = jsonStyle{indent: "  "}
+
+// marshal encodes v with encoding/json in style s.
+func (s jsonStyle) marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(s.escapeHTML)
+
+	if !s.compact {
+		enc.SetIndent(s.prefix, s.indent)
+	}
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err //nolint:wrapcheck // Wrapped by marshalJSON
+	}
+
+	// Encode terminates every value with a newline, unlike Marshal
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// marshalJSON marshals v like encoding/json in style. Values encoding/json
+// rejects because of their map keys, e.g. map[Point]int or map[bool]string,
+// are retried after rewriting every map into one keyed by the stringified
+// keys, which encoding/json then writes sorted.
+func marshalJSON(v interface{}, style jsonStyle) ([]byte, error) {
+	data, err := style.marshal(v)
+	if err == nil {
+		return data, nil
+	}
+
+	canonical, canonicalErr := style.marshal(canonicalizer{}.value(reflect.ValueOf(v)))
+	if canonicalErr != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %w", v, err)
+	}
+
+	return canonical, nil
+}
+
+// canonicalizer converts values into ones encoding/json accepts for any map
+// key type. Structs become maps following their json tags, and values that
+// marshal themselves are kept as they are, unless times formats them or
+// stringers renders them with their String or MarshalText method.
+type canonicalizer struct {
+	times     *timeFormat
+	stringers bool
+}
+
+// canonicalize applies WithTimeFormat and WithPreferStringer to the values
+// nested in value. Only serializers going through JSON are affected, since
+// value is rewritten into maps and slices.
+func (g *Golden) canonicalize(value interface{}) interface{} {
+	c := canonicalizer{times: g.timeFormat(), stringers: g.options.PreferStringer}
+	if c.times == nil && !c.stringers {
+		return value
+	}
+
+	switch g.serializer().(type) {
+	case jsonSerializer, yamlSerializer, tomlSerializer:
+		return c.value(reflect.ValueOf(value))
+	default:
+		return value
+	}
+}
+
+func (c canonicalizer) value(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if formatted, ok := c.times.format(v); ok {
+		return formatted
+	}
+
+	if c.stringers {
+		if text, ok := stringValue(v); ok {
+			return text
+		}
+	}
+
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return c.value(v.Elem())
+	case reflect.Map:
+		return c.mapValue(v)
+	case reflect.Slice, reflect.Array:
+		return c.listValue(v)
+	case reflect.Struct:
+		obj := make(map[string]interface{})
+		c.fields(v, obj)
+
+		return obj
+	default:
+		return v.Interface()
+	}
+}
+
+func (c canonicalizer) mapValue(v reflect.Value) interface{} {
+	if v.IsNil() {
+		return nil
+	}
+
+	obj := make(map[string]interface{}, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		obj[mapKeyString(iter.Key())] = c.value(iter.Value())
+	}
+
+	return obj
+}
+
+func (c canonicalizer) listValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+		return v.Interface()
+	}
+
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = c.value(v.Index(i))
+	}
+
+	return items
+}
+
+// fields stores the exported fields of the struct v in obj under their JSON
+// names. Untagged embedded structs are flattened into obj.
+func (c canonicalizer) fields(v reflect.Value, obj map[string]interface{}) {
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		value := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					continue
+				}
+
+				value = value.Elem()
+			}
+
+			if value.Kind() == reflect.Struct {
+				c.fields(value, obj)
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if strings.Contains(opts, "omitempty") && isEmptyValue(value) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		obj[name] = c.value(value)
+	}
+}
+
+// stringValue renders v with its MarshalText or String method.
+func stringValue(v reflect.Value) (string, bool) {
+	if !v.CanInterface() {
+		return "", false
+	}
+
+	switch value := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil {
+			return "", false
+		}
+
+		return string(text), true
+	case fmt.Stringer:
+		return value.String(), true
+	default:
+		return "", false
+	}
+}
+
+// isEmptyValue reports whether omitempty drops v, as in encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	default:
+		return v.IsZero()
+	}
+}
+
+// mapKeyString stringifies a map key, preferring its text form.
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	return fmt.Sprint(key.Interface())
+}
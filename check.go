@@ -0,0 +1,32 @@
+package golden
+
+import (
+	"testing"
+)
+
+// Check compares actual with the golden file name like Assert, but reports a
+// mismatch with Errorf instead of stopping the test, so that table-driven
+// tests report every failing case in one run. It returns whether actual
+// matched:
+//
+//	for _, tt := range tests {
+//		g.Check(tt.name, render(tt.input))
+//	}
+func (g *Golden) Check(name string, actual any) bool {
+	g.t.Helper()
+
+	ctb := newCaptureTB(g.t, nil)
+	clone := *g
+	clone.t = ctb
+
+	ctb.run(func(testing.TB) {
+		clone.Assert(name, actual)
+	})
+
+	// Failures are reported from the test goroutine, at the caller's line
+	for _, msg := range ctb.messages() {
+		g.t.Errorf("%s", msg)
+	}
+
+	return !ctb.Failed()
+}
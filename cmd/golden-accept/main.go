@@ -0,0 +1,36 @@
+// Command golden-accept handles the golden://accept links of failure output,
+// rendered when the terminal supports hyperlinks, by writing the output of
+// the failed comparison to its golden file.
+//
+// Usage:
+//
+//	go run github.com/sivchari/golden/cmd/golden-accept 'golden://accept?...'
+//
+// Register it as the handler of the golden URL scheme, e.g. with a desktop
+// entry for x-scheme-handler/golden, to accept changes in one click.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sivchari/golden"
+)
+
+func main() {
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: golden-accept golden://accept?...")
+		os.Exit(2)
+	}
+
+	filename, err := golden.Accept(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Accepted %s\n", filename)
+}
@@ -0,0 +1,77 @@
+// Command golden-footprint reports the footprint of golden files per
+// package: file count, total size, largest files and compression
+// candidates.
+//
+// Usage:
+//
+//	go run github.com/sivchari/golden/cmd/golden-footprint [-top n] [-budget bytes] [dir ...]
+//
+// Every testdata directory under the given directories, the current one by
+// default, is reported. With -budget, it exits with status 1 if a golden
+// file exceeds the budget, which suits CI checks.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sivchari/golden/manager"
+)
+
+func main() {
+	top := flag.Int("top", 5, "number of largest files listed per package")
+	budget := flag.Int64("budget", 0, "maximum size in bytes of a golden file (0 disables the check)")
+	flag.Parse()
+
+	roots := flag.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	if err := run(roots, *top, *budget); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run reports the footprints of the golden files under roots and checks
+// them against budget.
+func run(roots []string, top int, budget int64) error {
+	var footprints []*manager.Footprint
+
+	for _, root := range roots {
+		found, err := manager.MeasureTree(root)
+		if err != nil {
+			return err //nolint:wrapcheck // Errors are already wrapped by the manager
+		}
+
+		footprints = append(footprints, found...)
+	}
+
+	if err := manager.WriteFootprints(os.Stdout, footprints, top); err != nil {
+		return err //nolint:wrapcheck // Errors are already wrapped by the manager
+	}
+
+	if budget <= 0 {
+		return nil
+	}
+
+	var over int
+
+	for _, f := range footprints {
+		for _, file := range f.Files {
+			if file.Size > budget {
+				fmt.Fprintf(os.Stderr, "%s is %d bytes, exceeding the budget of %d bytes\n", file.Path, file.Size, budget)
+
+				over++
+			}
+		}
+	}
+
+	if over > 0 {
+		return fmt.Errorf("%d golden file(s) exceed the size budget", over)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// Command golden-report summarizes a run report written by golden.WriteReport
+// (e.g. through GOLDEN_REPORT), listing the failed golden files most changed
+// first, so that the most substantive regressions are looked at first.
+//
+// Usage:
+//
+//	go run github.com/sivchari/golden/cmd/golden-report [-previous report.tar.gz] report.tar.gz
+//
+// With -previous, e.g. the report downloaded from the previous CI run, it
+// lists instead which golden files newly broke, which still fail and which
+// were fixed. It exits with status 1 if the report has failures.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sivchari/golden"
+)
+
+// errFailures is returned when the report has failures.
+var errFailures = errors.New("golden files failed")
+
+func main() {
+	previous := flag.String("previous", "", "report of a previous run to compare the failures with")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: golden-report [-previous report.tar.gz] report.tar.gz")
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *previous); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run summarizes the failures of the report archive path, compared with
+// those of the report archive previous if not empty.
+func run(path, previous string) error {
+	report, err := golden.ReadReport(path)
+	if err != nil {
+		return err //nolint:wrapcheck // Errors are already wrapped by golden
+	}
+
+	if previous != "" {
+		before, err := golden.ReadReport(previous)
+		if err != nil {
+			return err //nolint:wrapcheck // Errors are already wrapped by golden
+		}
+
+		err = golden.CompareReports(before, report).Write(os.Stdout)
+		if err != nil {
+			return err //nolint:wrapcheck // Errors are already wrapped by golden
+		}
+	} else if len(report.Failures) > 0 {
+		if err := report.WriteFailures(os.Stdout); err != nil {
+			return err //nolint:wrapcheck // Errors are already wrapped by golden
+		}
+	}
+
+	if len(report.Failures) > 0 {
+		return errFailures
+	}
+
+	return nil
+}
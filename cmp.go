@@ -0,0 +1,61 @@
+package golden
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// usesCmp reports whether actual is compared with go-cmp.
+func (g *Golden) usesCmp(actual interface{}) bool {
+	if len(g.options.CmpOptions) == 0 {
+		return false
+	}
+
+	switch actual.(type) {
+	case []byte, string, nil, GoldenMarshaler:
+		return false
+	default:
+		return true
+	}
+}
+
+// assertCmp decodes the golden file into a value of the type of actual and
+// compares both with go-cmp, so that existing cmp option sets apply.
+func (g *Golden) assertCmp(name string, actual interface{}) {
+	actualBytes := g.formatValue(actual)
+	filename := g.goldenFilename(name, g.contentExtension(actualBytes))
+
+	if g.options.Update {
+		g.checkMutable(filename)
+		g.writeGolden(filename, actualBytes)
+
+		return
+	}
+
+	expectedBytes, err := g.readGolden(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			g.t.Fatalf("Golden file %s does not exist. Run with update mode to create it.", filename)
+		}
+
+		g.t.Fatalf("Failed to read golden file %s: %v", filename, err)
+	}
+
+	expected := reflect.New(reflect.TypeOf(actual))
+	if err := json.Unmarshal(expectedBytes, expected.Interface()); err != nil {
+		g.t.Fatalf("Failed to decode golden file %s into %T: %v", filename, actual, err)
+	}
+
+	cmpDiff := cmp.Diff(expected.Elem().Interface(), actual, g.options.CmpOptions...)
+	if cmpDiff == "" {
+		return
+	}
+
+	diff := g.differ.Diff(expectedBytes, actualBytes)
+	diffOutput := g.differ.Format(diff) + "\ngo-cmp (-golden +actual):\n" + cmpDiff
+
+	g.t.Fatalf("%s", g.formatDiffError(filename, "", diffOutput, nil))
+}
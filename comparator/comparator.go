@@ -11,23 +11,102 @@ import (
 	"strings"
 )
 
+// whitespacePattern matches runs of whitespace collapsed by IgnoreWhitespace.
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
 // Comparator handles advanced comparison logic.
 type Comparator struct {
-	options Options
+	options        Options
+	ignorePaths    []FieldPath
+	timestampRules []timestampRule
+	fieldComparers []fieldComparer
+	orderKeys      []orderKey
+	fieldDecoders  []fieldDecoder
+	valuePatterns  []valuePattern
+	records        bool  // Comparing NDJSON, whose records are the top-level array
+	err            error // Invalid options, reported by Compare
 }
 
 // Options configures comparison behavior.
+// IgnoreFields accepts bare field names, which are ignored at any depth, and
+// path expressions like "data.user.created_at" or "items[*].id".
 type Options struct {
 	IgnoreOrder       bool
 	IgnoreWhitespace  bool
+	CaseInsensitive   bool
+	Locale            string // BCP 47 tag selecting case rules for CaseInsensitive, e.g. "tr"
+	SortLines         bool
 	CustomCompareFunc func(expected, actual []byte) bool
 	IgnoreFields      []string
+	Placeholders      bool // Treat <<NAME>> tokens in expected content as wildcards
+	Timestamps        []TimestampRule
+	FieldComparers    []FieldComparer
+	OrderKeys         []OrderKey // Sort arrays of objects by a key instead of by their formatted value
+	StrictJSON        bool       // Reject duplicate keys, invalid escapes and trailing data
+	NullAsMissing     bool       // Treat fields set to null as absent
+	IgnoreExtraFields bool       // Ignore fields of actual objects that expected lacks
+	FieldDecoders     []FieldDecoder
+	HTML              bool     // Compare content as HTML documents
+	StripAttributes   []string // HTML attributes to ignore, e.g. "nonce"
+	IgnoreValues      []string // Regexps of string values to ignore wherever they appear
+	Tokens            bool     // Compare content token by token, ignoring formatting
+	FormatGo          bool     // Run Go source through go/format before comparing tokens
+	YAML              bool     // Compare content as a stream of YAML documents
+	YAMLDocumentKeys  []string // Keys pairing YAML documents, e.g. "kind" and "metadata.name"
+	TOML              bool     // Compare content as a TOML document
+	UnorderedRecords  bool     // Compare NDJSON records regardless of their order, IgnoreOrder applies within records
 }
 
 // CompareResult represents the result of a comparison.
 type CompareResult struct {
-	Equal   bool
-	Details string
+	Equal       bool
+	Details     string
+	Ignored     []Ignored    // Content skipped by ignore rules, sorted by path
+	Differences []Difference // Exact differences found by JSON comparison, sorted by path
+	Err         error        // Why the content could not be compared, e.g. a *StrictError
+}
+
+// Ignored describes content that was skipped during a comparison.
+type Ignored struct {
+	Path string // Location of the skipped content, e.g. "items[0].id"
+	Rule string // Rule that matched, e.g. the IgnoreFields expression
+}
+
+// compareState carries per-comparison bookkeeping through normalization.
+type compareState struct {
+	ignored map[Ignored]bool
+}
+
+// newCompareState creates an empty compareState.
+func newCompareState() *compareState {
+	return &compareState{ignored: make(map[Ignored]bool)}
+}
+
+// ignore records that content at path was skipped by rule.
+func (s *compareState) ignore(path, rule string) {
+	s.ignored[Ignored{Path: path, Rule: rule}] = true
+}
+
+// ignoredList returns the recorded ignored content sorted by path.
+func (s *compareState) ignoredList() []Ignored {
+	if len(s.ignored) == 0 {
+		return nil
+	}
+
+	list := make([]Ignored, 0, len(s.ignored))
+	for item := range s.ignored {
+		list = append(list, item)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+
+		return list[i].Rule < list[j].Rule
+	})
+
+	return list
 }
 
 // New creates a new Comparator with default options.
@@ -42,11 +121,38 @@ func New() *Comparator {
 
 // NewWithOptions creates a new Comparator with custom options.
 func NewWithOptions(opts Options) *Comparator {
-	return &Comparator{options: opts}
+	valuePatterns, err := compileValuePatterns(opts.IgnoreValues)
+
+	return &Comparator{
+		options:        opts,
+		ignorePaths:    ParseFieldPaths(opts.IgnoreFields),
+		timestampRules: parseTimestampRules(opts.Timestamps),
+		fieldComparers: parseFieldComparers(opts.FieldComparers),
+		orderKeys:      parseOrderKeys(opts.OrderKeys),
+		fieldDecoders:  parseFieldDecoders(opts.FieldDecoders),
+		valuePatterns:  valuePatterns,
+		err:            err,
+	}
+}
+
+// invalidOptions returns the result of comparisons with invalid options,
+// or nil if the options are valid.
+func (c *Comparator) invalidOptions() *CompareResult {
+	if c.err == nil {
+		return nil
+	}
+
+	return &CompareResult{Details: "Invalid comparator options", Err: c.err}
 }
 
-// Compare compares two byte arrays with advanced logic.
+// Compare compares two byte arrays with advanced logic. Invalid options,
+// such as an IgnoreValues expression that does not compile, are reported
+// by CompareResult.Err.
 func (c *Comparator) Compare(expected, actual []byte) *CompareResult {
+	if result := c.invalidOptions(); result != nil {
+		return result
+	}
+
 	// Use custom comparison function if provided
 	if c.options.CustomCompareFunc != nil {
 		equal := c.options.CustomCompareFunc(expected, actual)
@@ -57,6 +163,27 @@ func (c *Comparator) Compare(expected, actual []byte) *CompareResult {
 		}
 	}
 
+	if c.options.HTML {
+		return c.compareHTML(expected, actual)
+	}
+
+	if c.options.Tokens {
+		return c.compareTokens(expected, actual)
+	}
+
+	if c.options.YAML {
+		return c.compareYAML(expected, actual)
+	}
+
+	if c.options.TOML {
+		return c.compareTOML(expected, actual)
+	}
+
+	// Newline-delimited JSON also starts like JSON, so it is detected first
+	if IsNDJSON(expected) && IsNDJSON(actual) {
+		return c.compareNDJSON(expected, actual)
+	}
+
 	// Try JSON comparison first
 	if c.isJSON(expected) && c.isJSON(actual) {
 		return c.compareJSON(expected, actual)
@@ -80,32 +207,79 @@ func (c *Comparator) isJSON(data []byte) bool {
 
 // compareJSON performs semantic JSON comparison.
 func (c *Comparator) compareJSON(expected, actual []byte) *CompareResult {
-	var expectedObj, actualObj interface{}
+	if c.options.StrictJSON {
+		if result := strictResult(expected, actual); result != nil {
+			return result
+		}
+	}
 
-	if err := json.Unmarshal(expected, &expectedObj); err != nil {
+	expectedObj, err := DecodeJSON(expected)
+	if err != nil {
 		return &CompareResult{
 			Equal:   false,
 			Details: fmt.Sprintf("Failed to parse expected JSON: %v", err),
 		}
 	}
 
-	if err := json.Unmarshal(actual, &actualObj); err != nil {
+	actualObj, err := DecodeJSON(actual)
+	if err != nil {
 		return &CompareResult{
 			Equal:   false,
 			Details: fmt.Sprintf("Failed to parse actual JSON: %v", err),
 		}
 	}
 
-	// Normalize both objects
-	expectedNorm := c.normalizeValue(expectedObj)
-	actualNorm := c.normalizeValue(actualObj)
+	return c.compareDecoded(expectedObj, actualObj, "JSON semantic comparison")
+}
 
-	equal := c.deepEqual(expectedNorm, actualNorm)
+// compareDecoded semantically compares decoded JSON values.
+func (c *Comparator) compareDecoded(expectedObj, actualObj interface{}, details string) *CompareResult {
+	state := newCompareState()
 
-	return &CompareResult{
-		Equal:   equal,
-		Details: "JSON semantic comparison",
+	if c.options.IgnoreExtraFields {
+		actualObj = dropExtraFields(expectedObj, actualObj, nil, state)
+	}
+
+	if c.reconciles() {
+		actualObj = c.reconcile(expectedObj, actualObj, nil)
+	}
+
+	// Normalize both objects, then sort arrays whose order does not matter
+	expectedNorm := c.normalizeValue(expectedObj, nil, state)
+	actualNorm := c.normalizeValue(actualObj, nil, state)
+
+	result := &CompareResult{
+		Equal:   c.deepEqual(c.sortArrays(expectedNorm, nil), c.sortArrays(actualNorm, nil)),
+		Details: details,
+		Ignored: state.ignoredList(),
+	}
+
+	// Differences are found on the values in their original order, so that
+	// they point at the elements as they appear in the documents
+	if !result.Equal {
+		result.Differences = c.findDifferences(expectedNorm, actualNorm, "", nil)
+		result.Details = fmt.Sprintf("%s: %d difference(s)", details, len(result.Differences))
+	}
+
+	return result
+}
+
+// strictResult returns a failed result if expected or actual is rejected by
+// strict JSON validation.
+func strictResult(expected, actual []byte) *CompareResult {
+	for _, side := range []struct {
+		name string
+		data []byte
+	}{{"expected", expected}, {"actual", actual}} {
+		if err := ValidateStrictJSON(side.data); err != nil {
+			return &CompareResult{
+				Details: fmt.Sprintf("Strict validation of %s JSON failed: %v", side.name, err),
+				Err:     fmt.Errorf("invalid %s JSON: %w", side.name, err),
+			}
+		}
 	}
+
+	return nil
 }
 
 // compareText performs text comparison with preprocessing.
@@ -114,60 +288,150 @@ func (c *Comparator) compareText(expected, actual []byte) *CompareResult {
 	actualStr := string(actual)
 
 	// Apply text preprocessing
-	expectedStr = c.preprocessText(expectedStr)
-	actualStr = c.preprocessText(actualStr)
+	state := newCompareState()
+	expectedStr = c.preprocessText(expectedStr, state)
+	actualStr = c.preprocessText(actualStr, state)
 
 	equal := expectedStr == actualStr
 
+	if !equal && c.options.Placeholders {
+		if re := textPlaceholderRegexp(expectedStr); re != nil {
+			equal = re.MatchString(actualStr)
+		}
+	}
+
 	return &CompareResult{
 		Equal:   equal,
 		Details: "Text comparison with preprocessing",
+		Ignored: state.ignoredList(),
 	}
 }
 
-// normalizeValue normalizes a JSON value for comparison.
-func (c *Comparator) normalizeValue(v interface{}) interface{} {
+// reconciles reports whether any rule may match values other than by equality.
+func (c *Comparator) reconciles() bool {
+	return c.options.Placeholders || len(c.timestampRules) > 0 || len(c.fieldComparers) > 0
+}
+
+// reconcile returns actual with every value that is considered equal to its
+// expected counterpart, through placeholders, timestamp tolerance or field
+// comparers, replaced by the expected value. Objects are matched by key and arrays by index.
+func (c *Comparator) reconcile(expected, actual interface{}, path []string) interface{} {
+	if c.valuesMatch(expected, actual, path) {
+		return expected
+	}
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+
+		reconciled := make(map[string]interface{}, len(act))
+		for key, value := range act {
+			if expValue, exists := exp[key]; exists {
+				value = c.reconcile(expValue, value, Key(path, key))
+			}
+
+			reconciled[key] = value
+		}
+
+		return reconciled
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return actual
+		}
+
+		reconciled := make([]interface{}, len(act))
+		for i, value := range act {
+			if i < len(exp) {
+				value = c.reconcile(exp[i], value, Index(path, i))
+			}
+
+			reconciled[i] = value
+		}
+
+		return reconciled
+	}
+
+	return actual
+}
+
+// valuesMatch reports whether actual matches expected at path through a
+// rule other than plain equality.
+func (c *Comparator) valuesMatch(expected, actual interface{}, path []string) bool {
+	if c.options.Placeholders && placeholderMatch(expected, actual) {
+		return true
+	}
+
+	if c.fieldComparerMatch(expected, actual, path) {
+		return true
+	}
+
+	return c.timestampsMatch(expected, actual, path)
+}
+
+// normalizeValue normalizes a JSON value found at path for comparison.
+func (c *Comparator) normalizeValue(v interface{}, path []string, state *compareState) interface{} {
+	if token, ok := c.normalizeTimestamp(v, path, state); ok {
+		return token
+	}
+
+	if token, ok := c.ignoreValue(v, path, state); ok {
+		return token
+	}
+
+	// Decoded content is normalized like any other value found at path
+	if decoded, ok := c.decodeField(v, path); ok {
+		v = decoded
+	}
+
 	switch val := v.(type) {
 	case map[string]interface{}:
-		return c.normalizeObject(val)
+		return c.normalizeObject(val, path, state)
 	case []interface{}:
-		return c.normalizeArray(val)
+		return c.normalizeArray(val, path, state)
 	case string:
 		return c.normalizeString(val)
+	case json.Number:
+		return canonicalNumber(val)
 	default:
 		return val
 	}
 }
 
 // normalizeObject normalizes a JSON object.
-func (c *Comparator) normalizeObject(obj map[string]interface{}) map[string]interface{} {
+func (c *Comparator) normalizeObject(obj map[string]interface{}, path []string, state *compareState) map[string]interface{} {
 	normalized := make(map[string]interface{})
 
 	for key, value := range obj {
+		fieldPath := Key(path, key)
+
 		// Skip ignored fields
-		if c.shouldIgnoreField(key) {
+		if rule, ok := c.ignoreRule(fieldPath); ok {
+			state.ignore(FormatPath(fieldPath), "IgnoreFields "+rule)
+
+			continue
+		}
+
+		if value == nil && c.options.NullAsMissing {
 			continue
 		}
 
-		normalized[key] = c.normalizeValue(value)
+		normalized[key] = c.normalizeValue(value, fieldPath, state)
 	}
 
 	return normalized
 }
 
-// normalizeArray normalizes a JSON array.
-func (c *Comparator) normalizeArray(arr []interface{}) interface{} {
+// normalizeArray normalizes the elements of a JSON array, keeping their
+// order. Arrays whose order does not matter are sorted by sortArrays.
+func (c *Comparator) normalizeArray(arr []interface{}, path []string, state *compareState) interface{} {
 	normalized := make([]interface{}, len(arr))
 
 	for i, value := range arr {
-		normalized[i] = c.normalizeValue(value)
-	}
-
-	// Sort array if order should be ignored
-	if c.options.IgnoreOrder {
-		sort.Slice(normalized, func(i, j int) bool {
-			return c.compareValues(normalized[i], normalized[j]) < 0
-		})
+		normalized[i] = c.normalizeValue(value, Index(path, i), state)
 	}
 
 	return normalized
@@ -177,34 +441,78 @@ func (c *Comparator) normalizeArray(arr []interface{}) interface{} {
 func (c *Comparator) normalizeString(s string) string {
 	// Ignore whitespace if configured
 	if c.options.IgnoreWhitespace {
-		s = strings.TrimSpace(s)
-		s = regexp.MustCompile(`\s+`).ReplaceAllString(s, " ")
+		s = collapseWhitespace(strings.TrimSpace(s))
+	}
+
+	if c.options.CaseInsensitive {
+		s = c.foldCase(s)
 	}
 
 	return s
 }
 
-// shouldIgnoreField checks if a field should be ignored.
-func (c *Comparator) shouldIgnoreField(field string) bool {
-	for _, ignored := range c.options.IgnoreFields {
-		if field == ignored {
-			return true
+// ignoreRule returns the IgnoreFields expression matching the field at path.
+func (c *Comparator) ignoreRule(path []string) (string, bool) {
+	for i, p := range c.ignorePaths {
+		if p.Matches(path) {
+			return c.options.IgnoreFields[i], true
 		}
 	}
 
-	return false
+	return "", false
 }
 
 // preprocessText applies text preprocessing options.
-func (c *Comparator) preprocessText(s string) string {
+func (c *Comparator) preprocessText(s string, state *compareState) string {
+	s = c.ignoreTextValues(s, state)
+
+	// Sort before collapsing whitespace, which would join the lines
+	if c.options.SortLines {
+		if sorted := sortLines(s); sorted != s {
+			state.ignore("(text)", "SortLines: line order")
+			s = sorted
+		}
+	}
+
 	if c.options.IgnoreWhitespace {
-		s = strings.TrimSpace(s)
-		s = regexp.MustCompile(`\s+`).ReplaceAllString(s, " ")
+		collapsed := strings.TrimSpace(s)
+		collapsed = collapseWhitespace(collapsed)
+
+		if collapsed != s {
+			state.ignore("(text)", "IgnoreWhitespace: whitespace")
+			s = collapsed
+		}
+	}
+
+	if c.options.CaseInsensitive {
+		if folded := c.foldCase(s); folded != s {
+			state.ignore("(text)", "CaseInsensitive: letter case")
+			s = folded
+		}
 	}
 
 	return s
 }
 
+// collapseWhitespace replaces runs of whitespace with a single space,
+// skipping the regexp when there is nothing to collapse.
+func collapseWhitespace(s string) string {
+	if !strings.ContainsAny(s, "\t\n\f\r") && !strings.Contains(s, "  ") {
+		return s
+	}
+
+	return whitespacePattern.ReplaceAllString(s, " ")
+}
+
+// sortLines sorts the lines of s, keeping a trailing newline in place.
+func sortLines(s string) string {
+	trimmed := strings.TrimSuffix(s, "\n")
+	lines := strings.Split(trimmed, "\n")
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\n") + s[len(trimmed):]
+}
+
 // deepEqual performs deep equality comparison.
 func (c *Comparator) deepEqual(a, b interface{}) bool {
 	return reflect.DeepEqual(a, b)
@@ -0,0 +1,869 @@
+package comparator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIgnoreFieldPaths(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte(`{"data": {"user": {"name": "a", "created_at": "1"}, "created_at": "x"}, "items": [{"id": 1, "v": "a"}]}`)
+
+	tests := []struct {
+		name         string
+		ignoreFields []string
+		actual       string
+		equal        bool
+	}{
+		{
+			name:         "nested path ignores only that location",
+			ignoreFields: []string{"data.user.created_at"},
+			actual:       `{"data": {"user": {"name": "a", "created_at": "2"}, "created_at": "x"}, "items": [{"id": 1, "v": "a"}]}`,
+			equal:        true,
+		},
+		{
+			name:         "nested path does not ignore other locations",
+			ignoreFields: []string{"data.user.created_at"},
+			actual:       `{"data": {"user": {"name": "a", "created_at": "1"}, "created_at": "y"}, "items": [{"id": 1, "v": "a"}]}`,
+			equal:        false,
+		},
+		{
+			name:         "array wildcard",
+			ignoreFields: []string{"$.items[*].id"},
+			actual:       `{"data": {"user": {"name": "a", "created_at": "1"}, "created_at": "x"}, "items": [{"id": 2, "v": "a"}]}`,
+			equal:        true,
+		},
+		{
+			name:         "bare name ignores at any depth",
+			ignoreFields: []string{"created_at"},
+			actual:       `{"data": {"user": {"name": "a", "created_at": "2"}, "created_at": "y"}, "items": [{"id": 1, "v": "a"}]}`,
+			equal:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewWithOptions(Options{IgnoreFields: tt.ignoreFields})
+			if result := c.Compare(expected, []byte(tt.actual)); result.Equal != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v", result.Equal, tt.equal)
+			}
+		})
+	}
+}
+
+func TestCompareReportsIgnoredFields(t *testing.T) {
+	t.Parallel()
+
+	c := NewWithOptions(Options{IgnoreFields: []string{"items[*].id", "token"}})
+	result := c.Compare(
+		[]byte(`{"items": [{"id": 1}, {"id": 2}], "token": "a"}`),
+		[]byte(`{"items": [{"id": 3}, {"id": 4}], "token": "b"}`),
+	)
+
+	expected := []Ignored{
+		{Path: "items[0].id", Rule: "IgnoreFields items[*].id"},
+		{Path: "items[1].id", Rule: "IgnoreFields items[*].id"},
+		{Path: "token", Rule: "IgnoreFields token"},
+	}
+
+	if !result.Equal || len(result.Ignored) != len(expected) {
+		t.Fatalf("Compare() = %+v, want equal with %d ignored items", result, len(expected))
+	}
+
+	for i, item := range result.Ignored {
+		if item != expected[i] {
+			t.Errorf("Ignored[%d] = %+v, want %+v", i, item, expected[i])
+		}
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		equal    bool
+	}{
+		{"json uuid", `{"id": "<<UUID>>"}`, `{"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`, true},
+		{"json uuid shape mismatch", `{"id": "<<UUID>>"}`, `{"id": "not-a-uuid"}`, false},
+		{"json rfc3339", `[{"ts": "<<RFC3339>>"}]`, `[{"ts": "2024-01-01T10:00:00Z"}]`, true},
+		{"json any object", `{"meta": "<<ANY>>", "n": 1}`, `{"meta": {"a": [1, 2]}, "n": 1}`, true},
+		{"json other fields still compared", `{"id": "<<UUID>>", "n": 1}`, `{"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "n": 2}`, false},
+		{"text", "created <<RFC3339>> by <<ANY>>\n", "created 2024-01-01T10:00:00+09:00 by alice\n", true},
+		{"text mismatch", "id=<<INT>>\n", "id=abc\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewWithOptions(Options{Placeholders: true})
+			if result := c.Compare([]byte(tt.expected), []byte(tt.actual)); result.Equal != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v", result.Equal, tt.equal)
+			}
+		})
+	}
+}
+
+func TestTimestampRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		rule   TimestampRule
+		actual string
+		equal  bool
+	}{
+		{"within tolerance", TimestampRule{Path: "ts", Tolerance: 2 * time.Second}, `{"ts": "2024-01-01T10:00:01Z", "unix": 1700000000}`, true},
+		{"outside tolerance", TimestampRule{Path: "ts", Tolerance: 2 * time.Second}, `{"ts": "2024-01-01T10:00:05Z", "unix": 1700000000}`, false},
+		{"unix within tolerance", TimestampRule{Path: "unix", Tolerance: time.Second}, `{"ts": "2024-01-01T10:00:00Z", "unix": 1700000001}`, true},
+		{"token", TimestampRule{Path: "ts", Token: "<ts>"}, `{"ts": "2030-06-01T00:00:00+02:00", "unix": 1700000000}`, true},
+		{"token rejects non-timestamps", TimestampRule{Path: "ts", Token: "<ts>"}, `{"ts": "yesterday", "unix": 1700000000}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewWithOptions(Options{Timestamps: []TimestampRule{tt.rule}})
+			result := c.Compare([]byte(`{"ts": "2024-01-01T10:00:00Z", "unix": 1700000000}`), []byte(tt.actual))
+
+			if result.Equal != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v", result.Equal, tt.equal)
+			}
+		})
+	}
+}
+
+func TestCompareReportsDifferences(t *testing.T) {
+	t.Parallel()
+
+	c := NewWithOptions(Options{})
+	result := c.Compare(
+		[]byte(`{"user": {"name": "alice", "a/b": 1}, "tags": ["x", "y"], "gone": true}`),
+		[]byte(`{"user": {"name": "bob", "a/b": 1}, "tags": ["x"], "extra": null}`),
+	)
+
+	want := []Difference{
+		{Path: "/extra", Kind: DifferenceUnexpected},
+		{Path: "/gone", Kind: DifferenceMissing, Expected: true},
+		{Path: "/tags/1", Kind: DifferenceMissing, Expected: "y"},
+		{Path: "/user/name", Kind: DifferenceChanged, Expected: "alice", Actual: "bob"},
+	}
+
+	if result.Equal {
+		t.Fatal("Compare() reported equal documents")
+	}
+
+	if !reflect.DeepEqual(result.Differences, want) {
+		t.Errorf("Differences = %+v, want %+v", result.Differences, want)
+	}
+
+	if got := want[3].String(); got != `/user/name: expected "alice", got "bob"` {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestCompareReportsUnorderedDifferences(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     []Difference
+	}{
+		{
+			"changed element",
+			`{"tags": ["b", "c"]}`, `{"tags": ["z", "c"]}`,
+			[]Difference{{Path: "/tags/0", Kind: DifferenceChanged, Expected: "b", Actual: "z"}},
+		},
+		{
+			"reordered and changed",
+			`{"tags": ["a", "b", "c"]}`, `{"tags": ["c", "a", "z"]}`,
+			[]Difference{{Path: "/tags/1", Kind: DifferenceChanged, Expected: "b", Actual: "z"}},
+		},
+		{
+			"changed object field",
+			`{"items": [{"id": 1, "n": "a"}, {"id": 2, "n": "b"}]}`, `{"items": [{"id": 2, "n": "b"}, {"id": 1, "n": "x"}]}`,
+			[]Difference{{Path: "/items/0/n", Kind: DifferenceChanged, Expected: "a", Actual: "x"}},
+		},
+		{
+			"missing and unexpected",
+			`{"tags": ["a", "b"]}`, `{"tags": ["b", "a", "c"]}`,
+			[]Difference{{Path: "/tags/2", Kind: DifferenceUnexpected, Actual: "c"}},
+		},
+	}
+
+	c := NewWithOptions(Options{IgnoreOrder: true})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := c.Compare([]byte(tt.expected), []byte(tt.actual))
+			if result.Equal {
+				t.Fatal("Compare() reported equal documents")
+			}
+
+			if !reflect.DeepEqual(result.Differences, tt.want) {
+				t.Errorf("Differences = %+v, want %+v", result.Differences, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+
+	schema, err := InferSchema([]byte(`{"id": 1, "name": "a", "tags": ["x"], "meta": null}`))
+	if err != nil {
+		t.Fatalf("InferSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		actual string
+		want   []string
+	}{
+		{"values vary", `{"id": 2.5, "name": "b", "tags": [], "meta": null}`, nil},
+		{"wrong type", `{"id": "1", "name": "a", "tags": [1], "meta": null}`, []string{"/id", "/tags/0"}},
+		{"missing property", `{"id": 1, "name": "a", "tags": []}`, []string{"/meta"}},
+	}
+
+	c := New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := c.ValidateSchema(schema, []byte(tt.actual))
+
+			var got []string
+			for _, difference := range result.Differences {
+				got = append(got, difference.Path)
+			}
+
+			if result.Equal != (len(tt.want) == 0) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateSchema() equal = %v, paths = %v, want %v", result.Equal, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+		actual   string
+		equal    bool
+	}{
+		{"json", Options{CaseInsensitive: true}, `{"title": "Hello World"}`, `{"title": "HELLO world"}`, true},
+		{"text", Options{CaseInsensitive: true}, "Straße", "STRASSE", true},
+		{"case sensitive by default", Options{}, "Hello", "hello", false},
+		{"turkish locale", Options{CaseInsensitive: true, Locale: "tr"}, "ı", "I", true},
+		{"different letters", Options{CaseInsensitive: true}, "hello", "help", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := NewWithOptions(tt.opts).Compare([]byte(tt.expected), []byte(tt.actual))
+			if result.Equal != tt.equal {
+				t.Errorf("Compare(%q, %q) equal = %v, want %v", tt.expected, tt.actual, result.Equal, tt.equal)
+			}
+		})
+	}
+}
+
+func TestOrderKeys(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte(`{"items": [{"id": 2, "v": "b"}, {"id": 10, "v": "c"}, {"id": 1, "v": "a"}]}`)
+
+	tests := []struct {
+		name   string
+		actual string
+		equal  bool
+	}{
+		{"reordered", `{"items": [{"id": 10, "v": "c"}, {"id": 1, "v": "a"}, {"id": 2, "v": "b"}]}`, true},
+		{"changed value", `{"items": [{"id": 10, "v": "c"}, {"id": 1, "v": "x"}, {"id": 2, "v": "b"}]}`, false},
+	}
+
+	c := NewWithOptions(Options{OrderKeys: []OrderKey{{Path: "items", Field: "id"}}})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := c.Compare(expected, []byte(tt.actual))
+			if result.Equal != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v", result.Equal, tt.equal)
+			}
+
+			// Differences point at the element of the golden file with the same key
+			if !tt.equal && (len(result.Differences) != 1 || result.Differences[0].Path != "/items/2/v") {
+				t.Errorf("Differences = %+v, want /items/2/v", result.Differences)
+			}
+		})
+	}
+}
+
+func TestCompareJSONNumberPrecision(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		equal    bool
+	}{
+		{"ids above 2^53", `{"id": 9007199254740993}`, `{"id": 9007199254740992}`, false},
+		{"high precision decimals", `{"v": 0.10000000000000000001}`, `{"v": 0.1}`, false},
+		{"equivalent notations", `{"v": [1, 1.50, 2e3]}`, `{"v": [1.0, 1.5, 2000]}`, true},
+	}
+
+	c := New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := c.Compare([]byte(tt.expected), []byte(tt.actual)).Equal; got != tt.equal {
+				t.Errorf("Compare(%s, %s) equal = %v, want %v", tt.expected, tt.actual, got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestValidateStrictJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		want string // Error message, empty when valid
+	}{
+		{"valid", `{"a": [1, {"a": 2}], "b": "😀"}`, ""},
+		{"duplicate key", "{\n  \"a\": 1,\n  \"a\": 2\n}", `line 3, column 3: duplicate key "a"`},
+		{"duplicate nested key", `[{"k": {"x": 1, "x": 2}}]`, `line 1, column 17: duplicate key "x"`},
+		{"same key in sibling objects", `[{"a": 1}, {"a": 2}]`, ""},
+		{"trailing data", `{"a": 1} {"b": 2}`, "line 1, column 10: trailing data after top-level value"},
+		{"unpaired surrogate", `{"a": "x\ud800"}`, `line 1, column 9: unpaired surrogate escape \ud800`},
+		{"invalid utf-8", "{\"a\": \"\xff\"}", "line 1, column 8: invalid UTF-8 in string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateStrictJSON([]byte(tt.data))
+
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+
+			if got != tt.want {
+				t.Errorf("ValidateStrictJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComposeComparators(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+	always := Func(func(_, _ []byte) *CompareResult { return &CompareResult{Equal: true} })
+	lower := func(data []byte) []byte { return bytes.ToLower(data) }
+
+	tests := []struct {
+		name     string
+		cmp      Interface
+		expected string
+		actual   string
+		equal    bool
+	}{
+		{"first match falls back to text", FirstMatch(Func(c.CompareJSON), Func(c.CompareText)), "plain", "plain", true},
+		{"first match uses json", FirstMatch(Func(c.CompareJSON), Func(c.CompareText)), `{"a": 1}`, `{ "a":1 }`, true},
+		{"first match fails when none match", FirstMatch(Func(c.CompareJSON), Func(c.CompareText)), "a", "b", false},
+		{"chain requires all", Chain(always, Func(c.CompareText)), "a", "b", false},
+		{"chain passes", Chain(always, Func(c.CompareText)), "a", "a", true},
+		{"transform", Transform(lower, Func(c.CompareText)), "Hello", "HELLO", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.cmp.Compare([]byte(tt.expected), []byte(tt.actual)).Equal; got != tt.equal {
+				t.Errorf("Compare(%q, %q) equal = %v, want %v", tt.expected, tt.actual, got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestNullAsMissing(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte(`{"name": "a", "nickname": null, "tags": [null]}`)
+
+	tests := []struct {
+		name   string
+		opts   Options
+		actual string
+		equal  bool
+	}{
+		{"distinct by default", Options{}, `{"name": "a", "tags": [null]}`, false},
+		{"null as missing", Options{NullAsMissing: true}, `{"name": "a", "tags": [null]}`, true},
+		{"array elements are kept", Options{NullAsMissing: true}, `{"name": "a", "tags": []}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := NewWithOptions(tt.opts).Compare(expected, []byte(tt.actual)).Equal; got != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestIgnoreExtraFields(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte(`{"id": 1, "items": [{"name": "a"}]}`)
+	c := NewWithOptions(Options{IgnoreExtraFields: true})
+
+	tests := []struct {
+		name   string
+		actual string
+		equal  bool
+	}{
+		{"extra fields", `{"id": 1, "version": 2, "items": [{"name": "a", "new": true}]}`, true},
+		{"missing field", `{"version": 2, "items": [{"name": "a"}]}`, false},
+		{"changed field", `{"id": 2, "items": [{"name": "a"}]}`, false},
+		{"extra element", `{"id": 1, "items": [{"name": "a"}, {"name": "b"}]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := c.Compare(expected, []byte(tt.actual))
+			if result.Equal != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v", result.Equal, tt.equal)
+			}
+		})
+	}
+
+	result := c.Compare(expected, []byte(`{"id": 1, "version": 2, "items": [{"name": "a", "new": true}]}`))
+
+	want := []Ignored{{Path: "items[0].new", Rule: "IgnoreExtraFields"}, {Path: "version", Rule: "IgnoreExtraFields"}}
+	if !reflect.DeepEqual(result.Ignored, want) {
+		t.Errorf("Ignored = %+v, want %+v", result.Ignored, want)
+	}
+}
+
+func TestFormatTable(t *testing.T) {
+	t.Parallel()
+
+	// Golden ignores array order by default, yet rows point at the elements
+	// as they appear in the golden file
+	result := NewWithOptions(Options{IgnoreOrder: true}).Compare(
+		[]byte(`{"user": {"name": "alice"}, "tags": ["b", "c", "d"], "gone": true}`),
+		[]byte(`{"user": {"name": "bob"}, "tags": ["d", "z", "c"], "extra": 1}`),
+	)
+
+	want := "PATH     KIND        EXPECTED  ACTUAL\n" +
+		"/extra   unexpected  -         1\n" +
+		"/gone    missing     true      -\n" +
+		"/tags/0  changed     \"b\"       \"z\"\n" +
+		"... and 1 more\n"
+
+	if got := FormatTable(result.Differences, 3); got != want {
+		t.Errorf("FormatTable() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFieldDecoders(t *testing.T) {
+	t.Parallel()
+
+	var gz bytes.Buffer
+
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte("hello"))
+	_ = w.Close()
+
+	b64 := base64.StdEncoding.EncodeToString
+	jwt := func(claims string) string {
+		return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".sig"
+	}
+
+	c := NewWithOptions(Options{FieldDecoders: []FieldDecoder{
+		{Path: "data", Decode: DecodeBase64},
+		{Path: "blob", Decode: DecodeGzip},
+		{Path: "token", Decode: DecodeJWT},
+	}})
+
+	expected := fmt.Sprintf(`{"data": %q, "blob": %q, "token": %q}`, b64([]byte("a")), b64(gz.Bytes()), jwt(`{"sub": "1"}`))
+	actual := fmt.Sprintf(`{"data": %q, "blob": "aGVsbG8=", "token": %q}`, b64([]byte("b")), strings.Replace(jwt(`{"sub":"1"}`), "sig", "other", 1))
+
+	result := c.Compare([]byte(expected), []byte(actual))
+
+	want := []Difference{
+		{Path: "/blob", Kind: DifferenceChanged, Expected: "hello", Actual: "aGVsbG8="},
+		{Path: "/data", Kind: DifferenceChanged, Expected: "a", Actual: "b"},
+	}
+	if !reflect.DeepEqual(result.Differences, want) {
+		t.Errorf("Differences = %+v, want %+v", result.Differences, want)
+	}
+}
+
+func TestCompareNDJSON(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte("{\"event\": \"start\", \"n\": 1}\n{\"event\": \"stop\", \"n\": 2}\n")
+
+	tests := []struct {
+		name   string
+		opts   Options
+		actual string
+		equal  bool
+		want   []string
+	}{
+		{"semantic per line", Options{}, "{\"n\":1,\"event\":\"start\"}\n\n{\"n\":2.0,\"event\":\"stop\"}", true, nil},
+		{"order matters by default", Options{}, "{\"event\": \"stop\", \"n\": 2}\n{\"event\": \"start\", \"n\": 1}\n", false, []string{"/0/event", "/0/n", "/1/event", "/1/n"}},
+		{"ignore order within records", Options{IgnoreOrder: true}, "{\"event\": \"stop\", \"n\": 2}\n{\"event\": \"start\", \"n\": 1}\n", false, []string{"/0/event", "/0/n", "/1/event", "/1/n"}},
+		{"unordered records", Options{UnorderedRecords: true}, "{\"event\": \"stop\", \"n\": 2}\n{\"event\": \"start\", \"n\": 1}\n", true, nil},
+		{"changed line", Options{}, "{\"event\": \"start\", \"n\": 1}\n{\"event\": \"stop\", \"n\": 3}\n", false, []string{"/1/n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := NewWithOptions(tt.opts).Compare(expected, []byte(tt.actual))
+
+			var paths []string
+			for _, d := range result.Differences {
+				paths = append(paths, d.Path)
+			}
+
+			if result.Equal != tt.equal || !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("Compare() equal = %v, paths = %v, want %v, %v (%s)", result.Equal, paths, tt.equal, tt.want, result.Details)
+			}
+		})
+	}
+}
+
+func TestCompareHTML(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte(`<div class="a b" id="x"><br/><p>Hello   world</p><input nonce="1" disabled></div>`)
+
+	tests := []struct {
+		name   string
+		actual string
+		equal  bool
+	}{
+		{"formatting only", "<div id=\"x\" class=\"b a\">\n  <br>\n  <p>\n    Hello world\n  </p>\n  <input disabled nonce=\"2\">\n</div>\n", true},
+		{"changed text", `<div class="a b" id="x"><br/><p>Hello there</p><input nonce="1" disabled></div>`, false},
+		{"changed attribute", `<div class="a b" id="y"><br/><p>Hello world</p><input nonce="1" disabled></div>`, false},
+	}
+
+	c := NewWithOptions(Options{HTML: true, StripAttributes: []string{"nonce"}})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := c.Compare(expected, []byte(tt.actual)).Equal; got != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+
+	pre := NewWithOptions(Options{HTML: true}).Compare([]byte("<pre>a  b</pre>"), []byte("<pre>a b</pre>"))
+	if pre.Equal {
+		t.Error("Compare() ignored whitespace inside <pre>")
+	}
+}
+
+func TestInvalidIgnoreValueIsReported(t *testing.T) {
+	t.Parallel()
+
+	c := NewWithOptions(Options{IgnoreValues: []string{"("}})
+
+	for name, result := range map[string]*CompareResult{
+		"Compare":     c.Compare([]byte(`{}`), []byte(`{}`)),
+		"CompareJSON": c.CompareJSON([]byte(`{}`), []byte(`{}`)),
+		"CompareText": c.CompareText([]byte("a"), []byte("a")),
+	} {
+		if result.Equal || result.Err == nil || !strings.Contains(result.Err.Error(), "invalid ignored value pattern") {
+			t.Errorf("%s() = %+v, want the invalid pattern error", name, result)
+		}
+	}
+}
+
+func TestIgnoreValues(t *testing.T) {
+	t.Parallel()
+
+	c := NewWithOptions(Options{IgnoreValues: []string{Placeholders["UUID"].String(), Placeholders["RFC3339"].String()}})
+
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		equal    bool
+	}{
+		{
+			"json values under any key",
+			`{"id": "123e4567-e89b-12d3-a456-426614174000", "nested": {"at": "2024-01-01T00:00:00Z"}}`,
+			`{"id": "00000000-0000-0000-0000-000000000001", "nested": {"at": "2025-06-30T12:34:56.789+02:00"}}`,
+			true,
+		},
+		{"shape changed", `{"id": "123e4567-e89b-12d3-a456-426614174000"}`, `{"id": "42"}`, false},
+		{"text", "created 123e4567-e89b-12d3-a456-426614174000 at 2024-01-01T00:00:00Z\n", "created 00000000-0000-0000-0000-000000000001 at 2025-01-01T00:00:00Z\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := c.Compare([]byte(tt.expected), []byte(tt.actual)).Equal; got != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func BenchmarkCompareTextIgnoreWhitespace(b *testing.B) {
+	line := "lorem   ipsum\tdolor sit amet, consectetur adipiscing elit\n"
+	expected := []byte(strings.Repeat(line, 1000))
+	actual := []byte(strings.Repeat(strings.ReplaceAll(line, "   ", " "), 1000))
+
+	c := NewWithOptions(Options{IgnoreWhitespace: true})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Compare(expected, actual)
+	}
+}
+
+func BenchmarkCompareJSONIgnoreWhitespace(b *testing.B) {
+	var buf strings.Builder
+
+	buf.WriteString("[")
+
+	for i := range 1000 {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		fmt.Fprintf(&buf, `{"id": %d, "text": "  value   number %d  "}`, i, i)
+	}
+
+	buf.WriteString("]")
+
+	data := []byte(buf.String())
+	c := NewWithOptions(Options{IgnoreWhitespace: true})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Compare(data, data)
+	}
+}
+
+func TestCompareTokens(t *testing.T) {
+	t.Parallel()
+
+	expected := "package p\n\nvar x = map[string]int{\n\t\"a\":   1,\n\t\"bcd\": 2, // two\n}\n"
+
+	tests := []struct {
+		name     string
+		formatGo bool
+		actual   string
+		equal    bool
+	}{
+		{"formatting only", false, "package p\nvar x = map[string]int{\"a\": 1, \"bcd\": 2, //   two\n}", true},
+		{"string literal spacing matters", false, "package p\nvar x = map[string]int{\"a \": 1, \"bcd\": 2, // two\n}", false},
+		{"changed number", false, "package p\nvar x = map[string]int{\"a\": 1.5, \"bcd\": 2, // two\n}", false},
+		{"go/format", true, "package p\nvar x = map[string]int{\n\"a\": 1,\n\"bcd\": 2, // two\n}\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewWithOptions(Options{Tokens: true, FormatGo: tt.formatGo})
+			if result := c.Compare([]byte(expected), []byte(tt.actual)); result.Equal != tt.equal {
+				t.Errorf("Compare() equal = %v, want %v (%s)", result.Equal, tt.equal, result.Details)
+			}
+		})
+	}
+}
+
+func TestMatchSemver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"1.2.3", "v1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+		{">=1.2.0 <2", "1.9.12", true},
+		{">=1.2.0 <2", "2.0.0", false},
+		{">=1.2.0 <2", "2.0.0-rc.1", true},
+		{"~1.4", "1.4.7", true},
+		{"~1.4", "1.5.0", false},
+		{"^1.4.2", "1.9.0", true},
+		{"<1.0.0 || >=3", "3.1.0+build.5", true},
+		{">1.0.0-alpha.2", "1.0.0-alpha.10", true},
+		{">1.0.0-alpha", "1.0.0-alpha.beta", true},
+		{">=1", "not-a-version", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			t.Parallel()
+
+			if got := MatchSemver(tt.constraint, tt.version); got != tt.want {
+				t.Errorf("MatchSemver(%q, %q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+			}
+		})
+	}
+
+	c := NewWithOptions(Options{FieldComparers: []FieldComparer{{Path: "build.version", Equal: MatchSemver}}})
+	if !c.Compare([]byte(`{"build": {"version": ">=1.2.0 <2"}}`), []byte(`{"build": {"version": "1.3.0"}}`)).Equal {
+		t.Error("Compare() did not apply the semver constraint")
+	}
+}
+
+func TestCompareYAMLStream(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte(`kind: Service
+metadata:
+  name: web
+spec:
+  ports: [80]
+---
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`)
+
+	tests := []struct {
+		name   string
+		keys   []string
+		actual string
+		want   []string
+	}{
+		{"equal", nil, "kind: Service\nmetadata: {name: web}\nspec: {ports: [80]}\n---\nkind: Deployment\nmetadata: {name: web}\nspec: {replicas: 2.0}\n", nil},
+		{"by index", nil, "kind: Deployment\nmetadata: {name: web}\nspec: {replicas: 2}\n---\nkind: Service\nmetadata: {name: web}\nspec: {ports: [80]}\n", []string{"/0/kind", "/0/spec/ports", "/0/spec/replicas", "/1/kind", "/1/spec/ports", "/1/spec/replicas"}},
+		{"by key", []string{"kind", "metadata.name"}, "kind: Deployment\nmetadata: {name: web}\nspec: {replicas: 3}\n---\nkind: Service\nmetadata: {name: web}\nspec: {ports: [80]}\n", []string{"/1/spec/replicas"}},
+		{"missing document", []string{"kind", "metadata.name"}, "kind: Service\nmetadata: {name: web}\nspec: {ports: [80]}\n", []string{"/1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := NewWithOptions(Options{YAML: true, YAMLDocumentKeys: tt.keys}).Compare(expected, []byte(tt.actual))
+
+			var paths []string
+			for _, d := range result.Differences {
+				paths = append(paths, d.Path)
+			}
+
+			if result.Equal != (tt.want == nil) || !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("Compare() equal = %v, paths = %v, want %v (%s)", result.Equal, paths, tt.want, result.Details)
+			}
+		})
+	}
+}
+
+func TestCompareTOML(t *testing.T) {
+	t.Parallel()
+
+	expected := []byte(`# generated
+title = "app"
+
+[server]
+host = "localhost"
+port = 8080
+started = 2024-01-02T03:04:05Z
+`)
+
+	tests := []struct {
+		name   string
+		actual string
+		want   []string
+	}{
+		{"equal", "server = { port = 8080, host = \"localhost\", started = 2024-01-02T03:04:05Z }\ntitle = \"app\"\n", nil},
+		{"changed", "title = \"app\"\n[server]\nhost = \"localhost\"\nport = 9090\nstarted = 2024-01-02T03:04:05Z\n", []string{"/server/port"}},
+		{"invalid", "title = ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := NewWithOptions(Options{TOML: true}).Compare(expected, []byte(tt.actual))
+
+			var paths []string
+			for _, d := range result.Differences {
+				paths = append(paths, d.Path)
+			}
+
+			if wantEqual := tt.name == "equal"; result.Equal != wantEqual || !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("Compare() equal = %v, paths = %v, want %v (%s)", result.Equal, paths, tt.want, result.Details)
+			}
+		})
+	}
+}
+
+func TestCompareTOMLFallsBackToText(t *testing.T) {
+	t.Parallel()
+
+	c := NewWithOptions(Options{TOML: true})
+
+	if result := c.Compare([]byte("hello world"), []byte("hello world")); !result.Equal {
+		t.Errorf("Compare() of identical non-TOML content is not equal: %s", result.Details)
+	}
+
+	result := c.Compare([]byte("hello world"), []byte("hello there"))
+	if result.Equal {
+		t.Fatal("Compare() of different non-TOML content is equal")
+	}
+
+	if !strings.Contains(result.Details, "failed to parse expected TOML") {
+		t.Errorf("Details = %q, want the parse error", result.Details)
+	}
+}
@@ -0,0 +1,92 @@
+package comparator
+
+// Interface is implemented by anything that compares golden content with
+// actual content, including *Comparator and the compositions below.
+type Interface interface {
+	Compare(expected, actual []byte) *CompareResult
+}
+
+// Func adapts a function to Interface, e.g. Func(c.CompareJSON).
+type Func func(expected, actual []byte) *CompareResult
+
+// Compare calls f.
+func (f Func) Compare(expected, actual []byte) *CompareResult {
+	return f(expected, actual)
+}
+
+// Chain returns an Interface reporting equality only if every comparator
+// does. Comparators run in order and the first failing result is returned;
+// ignored content of passing comparators is accumulated.
+func Chain(comparators ...Interface) Interface {
+	return Func(func(expected, actual []byte) *CompareResult {
+		combined := &CompareResult{Equal: true, Details: "Chain"}
+
+		for _, c := range comparators {
+			result := c.Compare(expected, actual)
+			if !result.Equal {
+				result.Ignored = append(combined.Ignored, result.Ignored...)
+
+				return result
+			}
+
+			combined.Ignored = append(combined.Ignored, result.Ignored...)
+		}
+
+		return combined
+	})
+}
+
+// FirstMatch returns an Interface reporting equality if any comparator does,
+// trying them in order and returning the first passing result. When none
+// passes, the result of the first comparator is returned, which should be
+// the most specific one, e.g. JSON semantic comparison before plain text.
+func FirstMatch(comparators ...Interface) Interface {
+	return Func(func(expected, actual []byte) *CompareResult {
+		var first *CompareResult
+
+		for _, c := range comparators {
+			result := c.Compare(expected, actual)
+			if result.Equal {
+				return result
+			}
+
+			if first == nil {
+				first = result
+			}
+		}
+
+		if first == nil {
+			return &CompareResult{Details: "FirstMatch: no comparators"}
+		}
+
+		return first
+	})
+}
+
+// Transform returns an Interface applying fn to both sides before comparing
+// them with c, e.g. to scrub volatile content.
+func Transform(fn func([]byte) []byte, c Interface) Interface {
+	return Func(func(expected, actual []byte) *CompareResult {
+		return c.Compare(fn(expected), fn(actual))
+	})
+}
+
+// CompareJSON compares expected and actual as JSON documents, failing if
+// either is not valid JSON.
+func (c *Comparator) CompareJSON(expected, actual []byte) *CompareResult {
+	if result := c.invalidOptions(); result != nil {
+		return result
+	}
+
+	return c.compareJSON(expected, actual)
+}
+
+// CompareText compares expected and actual as text, applying the text
+// preprocessing options.
+func (c *Comparator) CompareText(expected, actual []byte) *CompareResult {
+	if result := c.invalidOptions(); result != nil {
+		return result
+	}
+
+	return c.compareText(expected, actual)
+}
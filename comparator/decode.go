@@ -0,0 +1,147 @@
+package comparator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDecodedSize bounds the output of decompressing decoders.
+const maxDecodedSize = 10 << 20
+
+// errNotJWT is returned when a value is not a JWT.
+var errNotJWT = errors.New("not a JWT: expected three dot-separated segments")
+
+// Decoder decodes an encoded string field into the value to compare.
+type Decoder func(value string) (interface{}, error)
+
+// FieldDecoder decodes the string values at Path before comparison, so that
+// embedded blobs are compared, and shown in differences, by their content.
+// Values that fail to decode are compared as they are.
+type FieldDecoder struct {
+	Path   string // Field expression, e.g. "attachment.data"
+	Decode Decoder
+}
+
+// fieldDecoder is a FieldDecoder with its path parsed.
+type fieldDecoder struct {
+	FieldDecoder
+
+	path FieldPath
+}
+
+// parseFieldDecoders parses the paths of decoders.
+func parseFieldDecoders(decoders []FieldDecoder) []fieldDecoder {
+	parsed := make([]fieldDecoder, len(decoders))
+	for i, decoder := range decoders {
+		parsed[i] = fieldDecoder{FieldDecoder: decoder, path: ParseFieldPath(decoder.Path)}
+	}
+
+	return parsed
+}
+
+// decodeField decodes v with the decoder registered for path.
+func (c *Comparator) decodeField(v interface{}, path []string) (interface{}, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, false
+	}
+
+	for _, decoder := range c.fieldDecoders {
+		if !decoder.path.Matches(path) {
+			continue
+		}
+
+		decoded, err := decoder.Decode(s)
+		if err != nil {
+			return nil, false
+		}
+
+		return decoded, true
+	}
+
+	return nil, false
+}
+
+// DecodeBase64 decodes standard or URL-safe base64, padded or not.
+func DecodeBase64(value string) (interface{}, error) {
+	data, err := decodeBase64(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodedText(data), nil
+}
+
+// DecodeGzip decodes base64 encoded gzip data.
+func DecodeGzip(value string) (interface{}, error) {
+	data, err := decodeBase64(value)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip header: %w", err)
+	}
+
+	data, err = io.ReadAll(io.LimitReader(r, maxDecodedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gzip data: %w", err)
+	}
+
+	return decodedText(data), nil
+}
+
+// DecodeJWT decodes the payload of a JWT into its JSON claims. The signature
+// is not verified.
+func DecodeJWT(value string) (interface{}, error) {
+	segments := strings.Split(value, ".")
+	if len(segments) != 3 {
+		return nil, errNotJWT
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segments[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWT payload: %w", err)
+	}
+
+	claims, err := DecodeJSON(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT payload: %w", err)
+	}
+
+	return claims, nil
+}
+
+// decodeBase64 decodes any base64 variant.
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(value, "=")
+
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(value, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+
+	data, err := encoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+
+	return data, nil
+}
+
+// decodedText returns decoded data as a string, or as hexadecimal when it is
+// not valid UTF-8.
+func decodedText(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	return fmt.Sprintf("hex:%x", data)
+}
@@ -0,0 +1,318 @@
+package comparator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// DifferenceKind classifies a Difference.
+type DifferenceKind string
+
+const (
+	// DifferenceChanged means both sides hold a value at the path, but they differ.
+	DifferenceChanged DifferenceKind = "changed"
+	// DifferenceMissing means the expected value has no counterpart in actual.
+	DifferenceMissing DifferenceKind = "missing"
+	// DifferenceUnexpected means actual holds a value that is not expected.
+	DifferenceUnexpected DifferenceKind = "unexpected"
+	// DifferenceSchema means actual violates a JSON Schema constraint.
+	DifferenceSchema DifferenceKind = "schema"
+)
+
+// Difference describes a single difference found by a JSON comparison.
+type Difference struct {
+	Path     string // JSON pointer (RFC 6901) to the value, "" for the root
+	Kind     DifferenceKind
+	Expected interface{} // nil when Kind is DifferenceUnexpected
+	Actual   interface{} // nil when Kind is DifferenceMissing
+	Reason   string      // Violated constraint when Kind is DifferenceSchema
+}
+
+// String renders the difference on a single line.
+func (d Difference) String() string {
+	path := d.Path
+	if path == "" {
+		path = "/"
+	}
+
+	switch d.Kind {
+	case DifferenceMissing:
+		return fmt.Sprintf("%s: missing, expected %s", path, formatDifferenceValue(d.Expected))
+	case DifferenceUnexpected:
+		return fmt.Sprintf("%s: unexpected %s", path, formatDifferenceValue(d.Actual))
+	case DifferenceSchema:
+		return fmt.Sprintf("%s: %s", path, d.Reason)
+	default:
+		return fmt.Sprintf("%s: expected %s, got %s", path, formatDifferenceValue(d.Expected), formatDifferenceValue(d.Actual))
+	}
+}
+
+// maxTableValue limits the width of values in a difference table.
+const maxTableValue = 40
+
+// FormatTable renders up to limit differences as an aligned table with the
+// columns PATH, KIND, EXPECTED and ACTUAL, noting how many were left out.
+// For schema violations, EXPECTED holds the violated constraint.
+func FormatTable(differences []Difference, limit int) string {
+	var buf strings.Builder
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tKIND\tEXPECTED\tACTUAL")
+
+	for i, d := range differences {
+		if i == limit {
+			break
+		}
+
+		path := d.Path
+		if path == "" {
+			path = "/"
+		}
+
+		expected, actual := tableValue(d.Expected), tableValue(d.Actual)
+
+		switch d.Kind {
+		case DifferenceMissing:
+			actual = "-"
+		case DifferenceUnexpected:
+			expected = "-"
+		case DifferenceSchema:
+			expected = d.Reason
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", path, d.Kind, expected, actual)
+	}
+
+	_ = w.Flush()
+
+	if len(differences) > limit {
+		fmt.Fprintf(&buf, "... and %d more\n", len(differences)-limit)
+	}
+
+	return buf.String()
+}
+
+// tableValue renders a value for a difference table on a single short line.
+func tableValue(v interface{}) string {
+	s := strings.NewReplacer("\t", " ", "\n", " ").Replace(formatDifferenceValue(v))
+	if runes := []rune(s); len(runes) > maxTableValue {
+		return string(runes[:maxTableValue-1]) + "…"
+	}
+
+	return s
+}
+
+// formatDifferenceValue renders a decoded JSON value compactly.
+func formatDifferenceValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return strconv.Quote(val)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// findDifferences lists the differences between two normalized JSON values
+// found at path, ordered by path. Arrays whose order does not matter are
+// matched as multisets, and differences point at the original indices.
+func (c *Comparator) findDifferences(expected, actual interface{}, pointer string, path []string) []Difference {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		if act, ok := actual.(map[string]interface{}); ok {
+			return c.objectDifferences(exp, act, pointer, path)
+		}
+	case []interface{}:
+		if act, ok := actual.([]interface{}); ok {
+			if c.unordered(path) {
+				return c.unorderedDifferences(exp, act, pointer, path)
+			}
+
+			return c.arrayDifferences(exp, act, pointer, path)
+		}
+	}
+
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+
+	return []Difference{{Path: pointer, Kind: DifferenceChanged, Expected: expected, Actual: actual}}
+}
+
+// objectDifferences lists the differences between two JSON objects.
+func (c *Comparator) objectDifferences(expected, actual map[string]interface{}, pointer string, path []string) []Difference {
+	keys := make([]string, 0, len(expected)+len(actual))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+
+	for key := range actual {
+		if _, ok := expected[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	var differences []Difference
+
+	for _, key := range keys {
+		child := pointer + "/" + escapePointer(key)
+		expValue, inExpected := expected[key]
+		actValue, inActual := actual[key]
+
+		switch {
+		case !inActual:
+			differences = append(differences, Difference{Path: child, Kind: DifferenceMissing, Expected: expValue})
+		case !inExpected:
+			differences = append(differences, Difference{Path: child, Kind: DifferenceUnexpected, Actual: actValue})
+		default:
+			differences = append(differences, c.findDifferences(expValue, actValue, child, Key(path, key))...)
+		}
+	}
+
+	return differences
+}
+
+// arrayDifferences lists the differences between two ordered JSON arrays by
+// index.
+func (c *Comparator) arrayDifferences(expected, actual []interface{}, pointer string, path []string) []Difference {
+	var differences []Difference
+
+	for i := 0; i < max(len(expected), len(actual)); i++ {
+		child := pointer + "/" + strconv.Itoa(i)
+
+		switch {
+		case i >= len(actual):
+			differences = append(differences, Difference{Path: child, Kind: DifferenceMissing, Expected: expected[i]})
+		case i >= len(expected):
+			differences = append(differences, Difference{Path: child, Kind: DifferenceUnexpected, Actual: actual[i]})
+		default:
+			differences = append(differences, c.findDifferences(expected[i], actual[i], child, Index(path, i))...)
+		}
+	}
+
+	return differences
+}
+
+// unorderedDifferences lists the differences between two JSON arrays whose
+// order does not matter. Equal elements are matched wherever they are. The
+// others are paired by order key value when one is registered, then in
+// order, and compared at the index of the expected element. Unpaired
+// elements are missing at their expected index or unexpected at their
+// actual index.
+func (c *Comparator) unorderedDifferences(expected, actual []interface{}, pointer string, path []string) []Difference {
+	// Index actual elements by their sorted form, to match equal ones
+	available := make(map[string][]int)
+	for j, value := range actual {
+		key := fmt.Sprintf("%#v", c.sortArrays(value, Index(path, j)))
+		available[key] = append(available[key], j)
+	}
+
+	var unmatched []int
+
+	for i, value := range expected {
+		key := fmt.Sprintf("%#v", c.sortArrays(value, Index(path, i)))
+		if js := available[key]; len(js) > 0 {
+			available[key] = js[1:]
+
+			continue
+		}
+
+		unmatched = append(unmatched, i)
+	}
+
+	var rest []int
+	for _, js := range available {
+		rest = append(rest, js...)
+	}
+
+	sort.Ints(rest)
+
+	field, _ := c.orderKeyFor(path)
+	pairs, unmatched, rest := pairElements(expected, actual, unmatched, rest, field)
+
+	// Differences are listed by index, as for ordered arrays
+	type indexed struct {
+		index       int
+		differences []Difference
+	}
+
+	var found []indexed
+
+	for _, pair := range pairs {
+		child := pointer + "/" + strconv.Itoa(pair[0])
+		found = append(found, indexed{pair[0], c.findDifferences(expected[pair[0]], actual[pair[1]], child, Index(path, pair[0]))})
+	}
+
+	for _, i := range unmatched {
+		found = append(found, indexed{i, []Difference{{Path: pointer + "/" + strconv.Itoa(i), Kind: DifferenceMissing, Expected: expected[i]}}})
+	}
+
+	for _, j := range rest {
+		found = append(found, indexed{j, []Difference{{Path: pointer + "/" + strconv.Itoa(j), Kind: DifferenceUnexpected, Actual: actual[j]}}})
+	}
+
+	sort.SliceStable(found, func(a, b int) bool {
+		return found[a].index < found[b].index
+	})
+
+	var differences []Difference
+	for _, f := range found {
+		differences = append(differences, f.differences...)
+	}
+
+	return differences
+}
+
+// pairElements pairs the unmatched expected and actual elements, at the
+// indices expIdx and actIdx, by the value of field if not empty, then in
+// order. It returns the pairs and the indices left unpaired.
+func pairElements(expected, actual []interface{}, expIdx, actIdx []int, field string) ([][2]int, []int, []int) {
+	var pairs [][2]int
+
+	if field != "" {
+		var left []int
+
+		for _, i := range expIdx {
+			want, ok := keyValue(expected[i], field)
+
+			k := -1
+			for n, j := range actIdx {
+				if got, found := keyValue(actual[j], field); ok && found && reflect.DeepEqual(want, got) {
+					k = n
+
+					break
+				}
+			}
+
+			if k < 0 {
+				left = append(left, i)
+
+				continue
+			}
+
+			pairs = append(pairs, [2]int{i, actIdx[k]})
+			actIdx = append(actIdx[:k:k], actIdx[k+1:]...)
+		}
+
+		expIdx = left
+	}
+
+	n := min(len(expIdx), len(actIdx))
+	for k := range n {
+		pairs = append(pairs, [2]int{expIdx[k], actIdx[k]})
+	}
+
+	return pairs, expIdx[n:], actIdx[n:]
+}
+
+// escapePointer escapes a key for use as a JSON pointer reference token.
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,49 @@
+package comparator
+
+// dropExtraFields returns actual without the object fields that are absent
+// from expected, recording them as ignored. Objects are matched by key and
+// arrays by index.
+func dropExtraFields(expected, actual interface{}, path []string, state *compareState) interface{} {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+
+		kept := make(map[string]interface{}, len(exp))
+
+		for key, value := range act {
+			fieldPath := Key(path, key)
+
+			expValue, exists := exp[key]
+			if !exists {
+				state.ignore(FormatPath(fieldPath), "IgnoreExtraFields")
+
+				continue
+			}
+
+			kept[key] = dropExtraFields(expValue, value, fieldPath, state)
+		}
+
+		return kept
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return actual
+		}
+
+		kept := make([]interface{}, len(act))
+		for i, value := range act {
+			if i < len(exp) {
+				value = dropExtraFields(exp[i], value, Index(path, i), state)
+			}
+
+			kept[i] = value
+		}
+
+		return kept
+	}
+
+	return actual
+}
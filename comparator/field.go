@@ -0,0 +1,38 @@
+package comparator
+
+// FieldComparer compares the values found at Path with Equal instead of by
+// deep equality. Values are decoded JSON: string, json.Number, bool, nil,
+// map[string]interface{} or []interface{}.
+type FieldComparer struct {
+	Path  string // Field expression, e.g. "data.signature" or "items[*].hash"
+	Equal func(expected, actual interface{}) bool
+}
+
+// fieldComparer is a FieldComparer with its path parsed.
+type fieldComparer struct {
+	FieldComparer
+
+	path FieldPath
+}
+
+// parseFieldComparers parses the paths of comparers.
+func parseFieldComparers(comparers []FieldComparer) []fieldComparer {
+	parsed := make([]fieldComparer, len(comparers))
+	for i, comparer := range comparers {
+		parsed[i] = fieldComparer{FieldComparer: comparer, path: ParseFieldPath(comparer.Path)}
+	}
+
+	return parsed
+}
+
+// fieldComparerMatch reports whether a field comparer registered for path
+// considers expected and actual equal.
+func (c *Comparator) fieldComparerMatch(expected, actual interface{}, path []string) bool {
+	for _, comparer := range c.fieldComparers {
+		if comparer.path.Matches(path) {
+			return comparer.Equal(expected, actual)
+		}
+	}
+
+	return false
+}
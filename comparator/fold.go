@@ -0,0 +1,16 @@
+package comparator
+
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// foldCase maps s to a caseless form, using the case rules of the
+// configured locale when set, so that "I" and "ı" match in Turkish.
+func (c *Comparator) foldCase(s string) string {
+	if c.options.Locale == "" {
+		return cases.Fold().String(s)
+	}
+
+	return cases.Lower(language.Make(c.options.Locale)).String(s)
+}
@@ -0,0 +1,132 @@
+package comparator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// preformattedElements keep their whitespace during HTML normalization.
+var preformattedElements = map[string]bool{
+	"pre":      true,
+	"textarea": true,
+	"script":   true,
+	"style":    true,
+}
+
+// compareHTML compares two HTML documents by their normalized form, ignoring
+// attribute order, self-closing syntax, insignificant whitespace and the
+// configured attributes.
+func (c *Comparator) compareHTML(expected, actual []byte) *CompareResult {
+	expectedNorm, err := c.NormalizeHTML(expected)
+	if err != nil {
+		return &CompareResult{Details: fmt.Sprintf("Failed to parse expected HTML: %v", err)}
+	}
+
+	actualNorm, err := c.NormalizeHTML(actual)
+	if err != nil {
+		return &CompareResult{Details: fmt.Sprintf("Failed to parse actual HTML: %v", err)}
+	}
+
+	return &CompareResult{
+		Equal:   bytes.Equal(expectedNorm, actualNorm),
+		Details: "HTML normalized comparison",
+	}
+}
+
+// NormalizeHTML renders an HTML document in the canonical form used for
+// comparison: one node per line, indented by depth, with sorted attributes
+// and whitespace collapsed outside preformatted elements.
+func (c *Comparator) NormalizeHTML(data []byte) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	for child := doc.FirstChild; child != nil; child = child.NextSibling {
+		c.writeHTMLNode(&buf, child, 0, false)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeHTMLNode writes the canonical form of node and its children.
+func (c *Comparator) writeHTMLNode(buf *bytes.Buffer, node *html.Node, depth int, preformatted bool) {
+	indent := strings.Repeat("  ", depth)
+
+	switch node.Type {
+	case html.TextNode:
+		text := node.Data
+		if !preformatted {
+			text = strings.Join(strings.Fields(text), " ")
+		}
+
+		if text != "" {
+			fmt.Fprintf(buf, "%s%q\n", indent, text)
+		}
+
+		return
+	case html.CommentNode:
+		fmt.Fprintf(buf, "%s<!--%s-->\n", indent, strings.TrimSpace(node.Data))
+
+		return
+	case html.DoctypeNode:
+		fmt.Fprintf(buf, "%s<!DOCTYPE %s>\n", indent, strings.ToLower(node.Data))
+
+		return
+	case html.ElementNode:
+		fmt.Fprintf(buf, "%s<%s%s>\n", indent, node.Data, c.htmlAttributes(node.Attr))
+	}
+
+	preformatted = preformatted || preformattedElements[node.Data]
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		c.writeHTMLNode(buf, child, depth+1, preformatted)
+	}
+}
+
+// htmlAttributes renders attributes sorted by name, without stripped ones.
+func (c *Comparator) htmlAttributes(attrs []html.Attribute) string {
+	var rendered []string
+
+	for _, attr := range attrs {
+		if c.stripsAttribute(attr.Key) {
+			continue
+		}
+
+		name := attr.Key
+		if attr.Namespace != "" {
+			name = attr.Namespace + ":" + name
+		}
+
+		value := attr.Val
+		if name == "class" {
+			// Class order is insignificant too
+			classes := strings.Fields(value)
+			sort.Strings(classes)
+			value = strings.Join(classes, " ")
+		}
+
+		rendered = append(rendered, fmt.Sprintf(" %s=%q", name, value))
+	}
+
+	sort.Strings(rendered)
+
+	return strings.Join(rendered, "")
+}
+
+// stripsAttribute reports whether the attribute name is configured to be stripped.
+func (c *Comparator) stripsAttribute(name string) bool {
+	for _, strip := range c.options.StripAttributes {
+		if strings.EqualFold(strip, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package comparator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// IsNDJSON reports whether data holds newline-delimited JSON: at least two
+// lines, each a JSON object or array on its own.
+func IsNDJSON(data []byte) bool {
+	lines := ndjsonLines(data)
+	if len(lines) < 2 {
+		return false
+	}
+
+	for _, line := range lines {
+		if (line[0] != '{' && line[0] != '[') || !json.Valid(line) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ndjsonLines returns the non-blank lines of data.
+func ndjsonLines(data []byte) [][]byte {
+	var lines [][]byte
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// compareNDJSON compares newline-delimited JSON line by line. The lines are
+// compared as the elements of an array, so difference paths start with the
+// line index, e.g. "/2/id". Record order matters unless UnorderedRecords is
+// set, whatever IgnoreOrder, which only applies within records.
+func (c *Comparator) compareNDJSON(expected, actual []byte) *CompareResult {
+	expectedLines, result := c.decodeLines("expected", expected)
+	if result != nil {
+		return result
+	}
+
+	actualLines, result := c.decodeLines("actual", actual)
+	if result != nil {
+		return result
+	}
+
+	records := *c
+	records.records = true
+
+	return records.compareDecoded(expectedLines, actualLines, "NDJSON semantic comparison")
+}
+
+// decodeLines decodes every line of newline-delimited JSON, returning a
+// failed result for an invalid line.
+func (c *Comparator) decodeLines(side string, data []byte) ([]interface{}, *CompareResult) {
+	if c.options.StrictJSON {
+		if err := ValidateStrictNDJSON(data); err != nil {
+			return nil, &CompareResult{
+				Details: fmt.Sprintf("Strict validation of %s NDJSON failed: %v", side, err),
+				Err:     fmt.Errorf("invalid %s NDJSON: %w", side, err),
+			}
+		}
+	}
+
+	lines := ndjsonLines(data)
+	values := make([]interface{}, len(lines))
+
+	for i, line := range lines {
+		value, err := DecodeJSON(line)
+		if err != nil {
+			return nil, &CompareResult{Details: fmt.Sprintf("Failed to parse %s NDJSON record %d: %v", side, i+1, err)}
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// ValidateStrictNDJSON applies ValidateStrictJSON to every record of
+// newline-delimited JSON.
+func ValidateStrictNDJSON(data []byte) error {
+	for i, line := range ndjsonLines(data) {
+		if err := ValidateStrictJSON(line); err != nil {
+			return fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
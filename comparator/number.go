@@ -0,0 +1,105 @@
+package comparator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExactExponent bounds the exponents of numbers compared exactly, which
+// keeps adversarial input such as 1e999999999 from exhausting memory.
+const maxExactExponent = 1000
+
+// errTrailingData is returned when a JSON document is followed by more data.
+var errTrailingData = errors.New("invalid character after top-level value")
+
+// DecodeJSON decodes a JSON document keeping numbers as json.Number, so that
+// integers above 2^53 and high-precision decimals are not rounded to float64.
+func DecodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err //nolint:wrapcheck // Decoding errors are descriptive on their own
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errTrailingData
+	}
+
+	return v, nil
+}
+
+// canonicalNumber returns the exact decimal form of n without exponent and
+// trailing zeros, so that 1, 1.0 and 1e0 compare equal.
+func canonicalNumber(n json.Number) json.Number {
+	r, ok := parseNumber(n)
+	if !ok {
+		return n
+	}
+
+	if r.IsInt() {
+		return json.Number(r.Num().String())
+	}
+
+	formatted := r.FloatString(fractionDigits(string(n)))
+	formatted = strings.TrimRight(formatted, "0")
+
+	return json.Number(strings.TrimSuffix(formatted, "."))
+}
+
+// parseNumber parses n exactly, unless its exponent is out of bounds.
+func parseNumber(n json.Number) (*big.Rat, bool) {
+	if _, exponent := splitExponent(string(n)); exponent > maxExactExponent || exponent < -maxExactExponent {
+		return nil, false
+	}
+
+	return new(big.Rat).SetString(string(n))
+}
+
+// splitExponent splits a JSON number into its mantissa and exponent.
+func splitExponent(s string) (string, int) {
+	i := strings.IndexAny(s, "eE")
+	if i < 0 {
+		return s, 0
+	}
+
+	exponent, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		// Out of int range, beyond any bound
+		return s[:i], math.MaxInt
+	}
+
+	return s[:i], exponent
+}
+
+// fractionDigits returns the number of digits after the decimal point needed
+// to write the JSON number s exactly.
+func fractionDigits(s string) int {
+	mantissa, exponent := splitExponent(s)
+
+	digits := 0
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits = len(mantissa) - i - 1
+	}
+
+	return max(digits-exponent, 0)
+}
+
+// compareNumbers compares two JSON numbers exactly.
+func compareNumbers(a, b json.Number) (int, bool) {
+	ar, aok := parseNumber(a)
+	br, bok := parseNumber(b)
+
+	if !aok || !bok {
+		return 0, false
+	}
+
+	return ar.Cmp(br), true
+}
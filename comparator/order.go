@@ -0,0 +1,135 @@
+package comparator
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// OrderKey sorts the arrays of objects at Path by the value of Field before
+// comparison, so that element order does not matter.
+type OrderKey struct {
+	Path  string // Field expression of the array, e.g. "items" or "data.users"
+	Field string // Object key to sort elements by, e.g. "id"
+}
+
+// orderKey is an OrderKey with its path parsed.
+type orderKey struct {
+	OrderKey
+
+	path FieldPath
+}
+
+// parseOrderKeys parses the paths of keys.
+func parseOrderKeys(keys []OrderKey) []orderKey {
+	parsed := make([]orderKey, len(keys))
+	for i, key := range keys {
+		parsed[i] = orderKey{OrderKey: key, path: ParseFieldPath(key.Path)}
+	}
+
+	return parsed
+}
+
+// orderKeyFor returns the order key field registered for the array at path.
+func (c *Comparator) orderKeyFor(path []string) (string, bool) {
+	for _, key := range c.orderKeys {
+		if key.path.Matches(path) {
+			return key.Field, true
+		}
+	}
+
+	return "", false
+}
+
+// unordered reports whether the order of the array at path does not
+// matter, because of an order key or IgnoreOrder.
+func (c *Comparator) unordered(path []string) bool {
+	_, ok := c.orderKeyFor(path)
+
+	return ok || c.ignoresOrder(path)
+}
+
+// ignoresOrder reports whether IgnoreOrder applies to the array at path. The
+// order of NDJSON records only depends on UnorderedRecords.
+func (c *Comparator) ignoresOrder(path []string) bool {
+	if c.records && len(path) == 0 {
+		return c.options.UnorderedRecords
+	}
+
+	return c.options.IgnoreOrder
+}
+
+// sortArrays returns a copy of the normalized value v found at path with
+// the arrays whose order does not matter sorted, by key when one is
+// registered, so that equal values compare deeply equal.
+func (c *Comparator) sortArrays(v interface{}, path []string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		sorted := make(map[string]interface{}, len(val))
+		for key, value := range val {
+			sorted[key] = c.sortArrays(value, Key(path, key))
+		}
+
+		return sorted
+	case []interface{}:
+		sorted := make([]interface{}, len(val))
+		for i, value := range val {
+			sorted[i] = c.sortArrays(value, Index(path, i))
+		}
+
+		if field, ok := c.orderKeyFor(path); ok {
+			c.sortByKey(sorted, field)
+		} else if c.ignoresOrder(path) {
+			sort.Slice(sorted, func(i, j int) bool {
+				return c.compareValues(sorted[i], sorted[j]) < 0
+			})
+		}
+
+		return sorted
+	default:
+		return v
+	}
+}
+
+// sortByKey sorts arr by the value of field in each object. Elements without
+// the field sort last, in their fallback order.
+func (c *Comparator) sortByKey(arr []interface{}, field string) {
+	sort.SliceStable(arr, func(i, j int) bool {
+		a, aok := keyValue(arr[i], field)
+		b, bok := keyValue(arr[j], field)
+
+		switch {
+		case aok && bok:
+			return c.compareKeys(a, b) < 0
+		case aok != bok:
+			return aok
+		default:
+			return c.compareValues(arr[i], arr[j]) < 0
+		}
+	})
+}
+
+// keyValue returns the value of field when v is an object holding it.
+func keyValue(v interface{}, field string) (interface{}, bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	value, ok := obj[field]
+
+	return value, ok
+}
+
+// compareKeys compares key values, numerically when both are numbers.
+func (c *Comparator) compareKeys(a, b interface{}) int {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+
+	if aok && bok {
+		if cmp, ok := compareNumbers(an, bn); ok {
+			return cmp
+		}
+	}
+
+	return c.compareValues(a, b)
+}
@@ -0,0 +1,129 @@
+package comparator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FieldPath is a parsed field expression such as "data.user.created_at",
+// "items[*].id" or "$.items[0].name". A bare name without dots or brackets
+// matches the field at any depth.
+type FieldPath struct {
+	segments []string
+	anywhere bool
+}
+
+// ParseFieldPath parses a dot/JSONPath field expression.
+func ParseFieldPath(expr string) FieldPath {
+	expr = strings.TrimPrefix(strings.TrimPrefix(expr, "$"), ".")
+
+	if !strings.ContainsAny(expr, ".[") {
+		return FieldPath{segments: []string{expr}, anywhere: true}
+	}
+
+	var segments []string
+
+	for _, part := range strings.Split(expr, ".") {
+		// Split "items[*][0]" into "items", "[*]", "[0]"
+		for part != "" {
+			open := strings.IndexByte(part, '[')
+
+			switch {
+			case open < 0:
+				segments = append(segments, part)
+				part = ""
+			case open > 0:
+				segments = append(segments, part[:open])
+				part = part[open:]
+			default:
+				end := strings.IndexByte(part, ']')
+				if end < 0 {
+					end = len(part) - 1
+				}
+
+				segments = append(segments, part[:end+1])
+				part = part[end+1:]
+			}
+		}
+	}
+
+	return FieldPath{segments: segments}
+}
+
+// Matches reports whether the path of a value, as built with Key and Index,
+// is selected by the expression.
+func (p FieldPath) Matches(path []string) bool {
+	if p.anywhere {
+		return len(path) > 0 && path[len(path)-1] == p.segments[0]
+	}
+
+	if len(path) != len(p.segments) {
+		return false
+	}
+
+	for i, segment := range p.segments {
+		if !matchSegment(segment, path[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchSegment matches a single expression segment against a path element.
+func matchSegment(segment, element string) bool {
+	switch segment {
+	case "*":
+		return !strings.HasPrefix(element, "[")
+	case "[*]":
+		return strings.HasPrefix(element, "[")
+	}
+
+	return segment == element
+}
+
+// Key appends an object key to a value path.
+func Key(path []string, key string) []string {
+	return append(path[:len(path):len(path)], key)
+}
+
+// Index appends an array index to a value path.
+func Index(path []string, i int) []string {
+	return append(path[:len(path):len(path)], "["+strconv.Itoa(i)+"]")
+}
+
+// FormatPath renders a value path as an expression, e.g. "items[0].id".
+func FormatPath(path []string) string {
+	var buf strings.Builder
+
+	for i, element := range path {
+		if i > 0 && !strings.HasPrefix(element, "[") {
+			buf.WriteByte('.')
+		}
+
+		buf.WriteString(element)
+	}
+
+	return buf.String()
+}
+
+// ParseFieldPaths parses a list of field expressions.
+func ParseFieldPaths(exprs []string) []FieldPath {
+	paths := make([]FieldPath, len(exprs))
+	for i, expr := range exprs {
+		paths[i] = ParseFieldPath(expr)
+	}
+
+	return paths
+}
+
+// MatchAny reports whether any of paths matches path.
+func MatchAny(paths []FieldPath, path []string) bool {
+	for _, p := range paths {
+		if p.Matches(path) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,119 @@
+package comparator
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern matches placeholder tokens such as <<UUID>>.
+var placeholderPattern = regexp.MustCompile(`<<([A-Z0-9_]+)>>`)
+
+// Placeholders maps placeholder names to the shape of the values they match.
+// A golden value "<<UUID>>" matches any actual value matching Placeholders["UUID"].
+// ANY is handled specially and matches any value, including objects and arrays.
+var Placeholders = map[string]*regexp.Regexp{
+	"ANY":     regexp.MustCompile(`^(?s).*$`),
+	"UUID":    regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+	"ULID":    regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`),
+	"RFC3339": regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})$`),
+	"DATE":    regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`),
+	"NUMBER":  regexp.MustCompile(`^-?\d+(\.\d+)?([eE][+-]?\d+)?$`),
+	"INT":     regexp.MustCompile(`^-?\d+$`),
+	"HEX":     regexp.MustCompile(`^[0-9a-fA-F]+$`),
+}
+
+// placeholderName returns the name of s if it is exactly one known placeholder token.
+func placeholderName(s string) (string, bool) {
+	m := placeholderPattern.FindStringSubmatch(s)
+	if m == nil || m[0] != s {
+		return "", false
+	}
+
+	_, ok := Placeholders[m[1]]
+
+	return m[1], ok
+}
+
+// placeholderMatch reports whether expected is a placeholder token matching
+// the shape of actual.
+func placeholderMatch(expected, actual interface{}) bool {
+	s, ok := expected.(string)
+	if !ok {
+		return false
+	}
+
+	name, ok := placeholderName(s)
+
+	return ok && placeholderMatches(name, actual)
+}
+
+// placeholderMatches reports whether a JSON value has the placeholder's shape.
+func placeholderMatches(name string, value interface{}) bool {
+	if name == "ANY" {
+		return true
+	}
+
+	var s string
+
+	switch v := value.(type) {
+	case string:
+		s = v
+	case float64, json.Number:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+
+		s = string(data)
+	default:
+		return false
+	}
+
+	return Placeholders[name].MatchString(s)
+}
+
+// textPlaceholderRegexp compiles expected text containing placeholder tokens
+// into a regexp matching actual text. It returns nil if expected has none.
+func textPlaceholderRegexp(expected string) *regexp.Regexp {
+	matches := placeholderPattern.FindAllStringSubmatchIndex(expected, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var (
+		buf  strings.Builder
+		last int
+	)
+
+	buf.WriteString(`^`)
+
+	for _, m := range matches {
+		pattern, ok := Placeholders[expected[m[2]:m[3]]]
+		if !ok {
+			continue
+		}
+
+		buf.WriteString(regexp.QuoteMeta(expected[last:m[0]]))
+
+		if expected[m[2]:m[3]] == "ANY" {
+			buf.WriteString(`[^\n]*?`)
+		} else {
+			// Reuse the anchored value pattern inside the line
+			inner := strings.TrimSuffix(strings.TrimPrefix(pattern.String(), "^"), "$")
+			buf.WriteString("(?:" + inner + ")")
+		}
+
+		last = m[1]
+	}
+
+	buf.WriteString(regexp.QuoteMeta(expected[last:]))
+	buf.WriteString(`$`)
+
+	re, err := regexp.Compile(buf.String())
+	if err != nil {
+		return nil
+	}
+
+	return re
+}
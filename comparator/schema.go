@@ -0,0 +1,327 @@
+package comparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateSchema validates the JSON document actual against the JSON Schema
+// stored in schema, instead of comparing exact values. Violations are
+// reported as DifferenceSchema differences.
+//
+// The supported keywords are type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum, maximum, allOf, anyOf and oneOf; other keywords,
+// including $ref, are ignored.
+func (c *Comparator) ValidateSchema(schema, actual []byte) *CompareResult {
+	var schemaObj, actualObj interface{}
+
+	if err := json.Unmarshal(schema, &schemaObj); err != nil {
+		return &CompareResult{Details: fmt.Sprintf("Failed to parse JSON schema: %v", err)}
+	}
+
+	if err := json.Unmarshal(actual, &actualObj); err != nil {
+		return &CompareResult{Details: fmt.Sprintf("Failed to parse actual JSON: %v", err)}
+	}
+
+	differences := validateSchema(schemaObj, actualObj, "")
+
+	return &CompareResult{
+		Equal:       len(differences) == 0,
+		Details:     fmt.Sprintf("JSON schema validation: %d violation(s)", len(differences)),
+		Differences: differences,
+	}
+}
+
+// InferSchema returns a JSON Schema describing the shape of the JSON document
+// data: its types, object properties, which are all required, and the items
+// of arrays.
+func InferSchema(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	schema := inferSchema(value)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	return out, nil
+}
+
+// inferSchema returns the schema of a decoded JSON value. Numbers are typed
+// as "number", since a whole value does not mean the field is an integer.
+func inferSchema(value interface{}) map[string]interface{} {
+	schema := map[string]interface{}{"type": jsonType(value)}
+	if schema["type"] == "integer" {
+		schema["type"] = "number"
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties := make(map[string]interface{}, len(v))
+		required := make([]string, 0, len(v))
+
+		for key, val := range v {
+			properties[key] = inferSchema(val)
+			required = append(required, key)
+		}
+
+		sort.Strings(required)
+		schema["properties"] = properties
+		schema["required"] = required
+	case []interface{}:
+		if items := inferItems(v); items != nil {
+			schema["items"] = items
+		}
+	}
+
+	return schema
+}
+
+// inferItems returns the schema shared by the elements of an array, which is
+// the schema of the first element when all elements have the same type.
+func inferItems(arr []interface{}) map[string]interface{} {
+	if len(arr) == 0 {
+		return nil
+	}
+
+	for _, v := range arr[1:] {
+		if inferSchema(v)["type"] != inferSchema(arr[0])["type"] {
+			return map[string]interface{}{}
+		}
+	}
+
+	return inferSchema(arr[0])
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// validateSchema validates value found at pointer against schema.
+func validateSchema(schema, value interface{}, pointer string) []Difference {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		// Boolean schemas accept everything or nothing
+		if accept, isBool := schema.(bool); isBool && !accept {
+			return []Difference{schemaDifference(pointer, value, "value is not allowed")}
+		}
+
+		return nil
+	}
+
+	var differences []Difference
+
+	if reason := checkType(s, value); reason != "" {
+		// Further keywords would only repeat the type error
+		return []Difference{schemaDifference(pointer, value, reason)}
+	}
+
+	if reason := checkEnum(s, value); reason != "" {
+		differences = append(differences, schemaDifference(pointer, value, reason))
+	}
+
+	for _, reason := range checkBounds(s, value) {
+		differences = append(differences, schemaDifference(pointer, value, reason))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		differences = append(differences, validateObject(s, v, pointer)...)
+	case []interface{}:
+		if items, ok := s["items"]; ok {
+			for i, item := range v {
+				differences = append(differences, validateSchema(items, item, pointer+"/"+strconv.Itoa(i))...)
+			}
+		}
+	}
+
+	return append(differences, validateCombinators(s, value, pointer)...)
+}
+
+// schemaDifference creates a DifferenceSchema difference.
+func schemaDifference(pointer string, value interface{}, reason string) Difference {
+	return Difference{Path: pointer, Kind: DifferenceSchema, Actual: value, Reason: reason}
+}
+
+// checkType validates the type keyword.
+func checkType(schema map[string]interface{}, value interface{}) string {
+	var types []string
+
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return ""
+	}
+
+	actual := jsonType(value)
+	for _, t := range types {
+		// Integers are numbers too
+		if t == actual || (t == "number" && actual == "integer") {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("type %s, want %s", actual, strings.Join(types, " or "))
+}
+
+// checkEnum validates the enum and const keywords.
+func checkEnum(schema map[string]interface{}, value interface{}) string {
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(constant, value) {
+		return fmt.Sprintf("value must be %s", formatDifferenceValue(constant))
+	}
+
+	enum, ok := schema["enum"].([]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, allowed := range enum {
+		if reflect.DeepEqual(allowed, value) {
+			return ""
+		}
+	}
+
+	return "value is not one of the enum values"
+}
+
+// checkBounds validates the length, size and range keywords.
+func checkBounds(schema map[string]interface{}, value interface{}) []string {
+	var reasons []string
+
+	check := func(keyword string, n float64, below bool) {
+		limit, ok := schema[keyword].(float64)
+		if ok && ((below && n < limit) || (!below && n > limit)) {
+			reasons = append(reasons, fmt.Sprintf("%s %v violated by %v", keyword, limit, n))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := float64(len([]rune(v)))
+		check("minLength", length, true)
+		check("maxLength", length, false)
+
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				reasons = append(reasons, fmt.Sprintf("value does not match pattern %q", pattern))
+			}
+		}
+	case float64:
+		check("minimum", v, true)
+		check("maximum", v, false)
+	case []interface{}:
+		check("minItems", float64(len(v)), true)
+		check("maxItems", float64(len(v)), false)
+	}
+
+	return reasons
+}
+
+// validateObject validates the properties, required and additionalProperties keywords.
+func validateObject(schema, obj map[string]interface{}, pointer string) []Difference {
+	var differences []Difference
+
+	required, _ := schema["required"].([]interface{})
+	for _, name := range required {
+		key, ok := name.(string)
+		if _, exists := obj[key]; ok && !exists {
+			differences = append(differences, Difference{
+				Path: pointer + "/" + escapePointer(key), Kind: DifferenceSchema, Reason: "required property is missing",
+			})
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional, hasAdditional := schema["additionalProperties"]
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := pointer + "/" + escapePointer(key)
+
+		if property, ok := properties[key]; ok {
+			differences = append(differences, validateSchema(property, obj[key], child)...)
+		} else if hasAdditional {
+			differences = append(differences, validateSchema(additional, obj[key], child)...)
+		}
+	}
+
+	return differences
+}
+
+// validateCombinators validates the allOf, anyOf and oneOf keywords.
+func validateCombinators(schema map[string]interface{}, value interface{}, pointer string) []Difference {
+	var differences []Difference
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			differences = append(differences, validateSchema(sub, value, pointer)...)
+		}
+	}
+
+	matching := func(subs []interface{}) int {
+		count := 0
+
+		for _, sub := range subs {
+			if len(validateSchema(sub, value, pointer)) == 0 {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && matching(anyOf) == 0 {
+		differences = append(differences, schemaDifference(pointer, value, "value matches no anyOf schema"))
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if n := matching(oneOf); n != 1 {
+			differences = append(differences, schemaDifference(pointer, value, fmt.Sprintf("value matches %d oneOf schemas, want 1", n)))
+		}
+	}
+
+	return differences
+}
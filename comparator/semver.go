@@ -0,0 +1,162 @@
+package comparator
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version. Build metadata is dropped, as it does
+// not affect precedence.
+type semver struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+// MatchSemver is a FieldComparer function for version fields. The golden
+// value is a semantic version, which must be equal to the actual one, or
+// constraints such as ">=1.2.0 <2". Constraints separated by spaces must all
+// hold, and alternatives are separated by "||". Supported operators are =,
+// !=, >, >=, <, <=, ~ (same minor version) and ^ (same major version).
+// Partial versions like "2" or "1.4" stand for their missing parts as zero.
+// Register it for a path with:
+//
+//	golden.WithFieldComparer("build.version", comparator.MatchSemver)
+func MatchSemver(expected, actual interface{}) bool {
+	constraints, ok := expected.(string)
+	if !ok {
+		return false
+	}
+
+	s, ok := actual.(string)
+	if !ok {
+		return false
+	}
+
+	version, ok := parseSemver(s)
+	if !ok {
+		return false
+	}
+
+	for _, alternative := range strings.Split(constraints, "||") {
+		if matchConstraints(strings.Fields(alternative), version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchConstraints reports whether version satisfies every constraint.
+func matchConstraints(constraints []string, version semver) bool {
+	if len(constraints) == 0 {
+		return false
+	}
+
+	for _, constraint := range constraints {
+		operator := constraint[:len(constraint)-len(strings.TrimLeft(constraint, "=!<>~^"))]
+
+		bound, ok := parseSemver(constraint[len(operator):])
+		if !ok || !matchOperator(operator, compareSemver(version, bound), version, bound) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchOperator applies a constraint operator given order, the comparison
+// of version with the bound.
+func matchOperator(operator string, order int, version, bound semver) bool {
+	switch operator {
+	case "", "=", "==":
+		return order == 0
+	case "!=":
+		return order != 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case "~":
+		return order >= 0 && version.major == bound.major && version.minor == bound.minor
+	case "^":
+		return order >= 0 && version.major == bound.major
+	default:
+		return false
+	}
+}
+
+// parseSemver parses a possibly partial semantic version with an optional
+// "v" prefix.
+func parseSemver(s string) (semver, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	s, _, _ = strings.Cut(s, "+")
+	s, prerelease, hasPrerelease := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return semver{}, false
+	}
+
+	var numbers [3]int
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+
+		numbers[i] = n
+	}
+
+	version := semver{major: numbers[0], minor: numbers[1], patch: numbers[2]}
+	if hasPrerelease {
+		version.prerelease = strings.Split(prerelease, ".")
+	}
+
+	return version, true
+}
+
+// compareSemver compares two versions by semantic version precedence.
+func compareSemver(a, b semver) int {
+	for _, pair := range [][2]int{{a.major, b.major}, {a.minor, b.minor}, {a.patch, b.patch}} {
+		if pair[0] != pair[1] {
+			return cmp.Compare(pair[0], pair[1])
+		}
+	}
+
+	// A version without prerelease has higher precedence
+	if len(a.prerelease) == 0 || len(b.prerelease) == 0 {
+		return cmp.Compare(len(b.prerelease), len(a.prerelease))
+	}
+
+	for i := 0; i < min(len(a.prerelease), len(b.prerelease)); i++ {
+		if order := comparePrerelease(a.prerelease[i], b.prerelease[i]); order != 0 {
+			return order
+		}
+	}
+
+	return cmp.Compare(len(a.prerelease), len(b.prerelease))
+}
+
+// comparePrerelease compares prerelease identifiers: numeric ones
+// numerically and lower than alphanumeric ones, which compare as text.
+func comparePrerelease(a, b string) int {
+	an, aerr := strconv.Atoi(a)
+	bn, berr := strconv.Atoi(b)
+
+	switch {
+	case aerr == nil && berr == nil:
+		return cmp.Compare(an, bn)
+	case aerr == nil:
+		return -1
+	case berr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
@@ -0,0 +1,225 @@
+package comparator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// StrictError describes JSON rejected by strict validation.
+type StrictError struct {
+	Line   int // 1-based line of the offending input
+	Column int // 1-based byte column of the offending input
+	Msg    string
+}
+
+// Error implements the error interface.
+func (e *StrictError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// strictFrame tracks an open object or array during strict validation.
+type strictFrame struct {
+	keys    map[string]bool // nil for arrays
+	wantKey bool
+}
+
+// ValidateStrictJSON checks that data is a single JSON document without
+// duplicate object keys, invalid UTF-8 or unpaired surrogate escapes, all
+// of which encoding/json accepts silently.
+func ValidateStrictJSON(data []byte) error {
+	if err := validateStrings(data); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var stack []*strictFrame
+
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return strictDecodeError(data, err)
+		}
+
+		var top *strictFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		if key, ok := token.(string); ok && top != nil && top.wantKey {
+			if top.keys[key] {
+				end := int(dec.InputOffset())
+
+				return newStrictError(data, stringStart(data, end), "duplicate key "+strconv.Quote(key))
+			}
+
+			top.keys[key] = true
+			top.wantKey = false
+
+			continue
+		}
+
+		switch token {
+		case json.Delim('{'):
+			stack = append(stack, &strictFrame{keys: make(map[string]bool), wantKey: true})
+
+			continue
+		case json.Delim('['):
+			stack = append(stack, &strictFrame{})
+
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		}
+
+		// A value is complete
+		if len(stack) == 0 {
+			break
+		}
+
+		if top := stack[len(stack)-1]; top.keys != nil {
+			top.wantKey = true
+		}
+	}
+
+	end := int(dec.InputOffset())
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return newStrictError(data, nextNonSpace(data, end), "trailing data after top-level value")
+	}
+
+	return nil
+}
+
+// strictDecodeError converts a decoding error into a StrictError.
+func strictDecodeError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return newStrictError(data, int(syntaxErr.Offset)-1, syntaxErr.Error())
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return newStrictError(data, len(data), "unexpected end of JSON input")
+	}
+
+	return newStrictError(data, 0, err.Error())
+}
+
+// validateStrings checks the string literals of data for invalid UTF-8 and
+// unpaired surrogate escapes. Outside string literals JSON has no quotes, so
+// a plain scan finds every literal.
+func validateStrings(data []byte) error {
+	inString := false
+
+	for i := 0; i < len(data); {
+		c := data[i]
+
+		switch {
+		case !inString:
+			inString = c == '"'
+			i++
+		case c == '"':
+			inString = false
+			i++
+		case c == '\\' && i+1 < len(data) && data[i+1] == 'u':
+			n, err := checkUnicodeEscape(data, i)
+			if err != nil {
+				return err
+			}
+
+			i += n
+		case c == '\\':
+			i += 2
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size == 1 {
+				return newStrictError(data, i, "invalid UTF-8 in string")
+			}
+
+			i += size
+		default:
+			i++
+		}
+	}
+
+	return nil
+}
+
+// checkUnicodeEscape validates the \uXXXX escape at i, which must pair up
+// surrogates, and returns the length of the escape sequence.
+func checkUnicodeEscape(data []byte, i int) (int, error) {
+	r, ok := parseUnicodeEscape(data, i)
+	if !ok {
+		// Malformed escapes are reported by the decoder
+		return 2, nil
+	}
+
+	if !utf16.IsSurrogate(r) {
+		return 6, nil
+	}
+
+	if next, ok := parseUnicodeEscape(data, i+6); ok && r < 0xDC00 && next >= 0xDC00 && next <= 0xDFFF {
+		return 12, nil
+	}
+
+	return 0, newStrictError(data, i, fmt.Sprintf("unpaired surrogate escape \\u%04x", r))
+}
+
+// parseUnicodeEscape parses the \uXXXX escape at i.
+func parseUnicodeEscape(data []byte, i int) (rune, bool) {
+	if i+6 > len(data) || data[i] != '\\' || data[i+1] != 'u' {
+		return 0, false
+	}
+
+	r, err := strconv.ParseUint(string(data[i+2:i+6]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return rune(r), true
+}
+
+// stringStart returns the offset of the opening quote of the string literal
+// ending right before end.
+func stringStart(data []byte, end int) int {
+	for i := end - 2; i >= 0; i-- {
+		if data[i] != '"' {
+			continue
+		}
+
+		backslashes := 0
+		for j := i - 1; j >= 0 && data[j] == '\\'; j-- {
+			backslashes++
+		}
+
+		if backslashes%2 == 0 {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// nextNonSpace returns the offset of the first non-whitespace byte from i.
+func nextNonSpace(data []byte, i int) int {
+	for i < len(data) && bytes.IndexByte([]byte(" \t\r\n"), data[i]) >= 0 {
+		i++
+	}
+
+	return i
+}
+
+// newStrictError creates a StrictError locating offset in data.
+func newStrictError(data []byte, offset int, msg string) *StrictError {
+	offset = max(min(offset, len(data)), 0)
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+
+	return &StrictError{Line: line, Column: column, Msg: msg}
+}
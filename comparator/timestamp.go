@@ -0,0 +1,115 @@
+package comparator
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// unixMillisThreshold separates Unix timestamps in seconds from milliseconds.
+const unixMillisThreshold = 1e11
+
+// TimestampRule configures how timestamps at Path are compared.
+// Timestamps are RFC3339 strings or Unix times in seconds or milliseconds.
+type TimestampRule struct {
+	Path      string        // Field expression, e.g. "data.created_at" or "events[*].ts"
+	Tolerance time.Duration // Maximum allowed difference between expected and actual
+	Token     string        // If set, valid timestamps are normalized to this token instead
+}
+
+// timestampRule is a TimestampRule with its path parsed.
+type timestampRule struct {
+	TimestampRule
+
+	path FieldPath
+}
+
+// parseTimestampRules parses the paths of rules.
+func parseTimestampRules(rules []TimestampRule) []timestampRule {
+	parsed := make([]timestampRule, len(rules))
+	for i, rule := range rules {
+		parsed[i] = timestampRule{TimestampRule: rule, path: ParseFieldPath(rule.Path)}
+	}
+
+	return parsed
+}
+
+// timestampRuleFor returns the rule applying to the value at path.
+func (c *Comparator) timestampRuleFor(path []string) (timestampRule, bool) {
+	for _, rule := range c.timestampRules {
+		if rule.path.Matches(path) {
+			return rule, true
+		}
+	}
+
+	return timestampRule{}, false
+}
+
+// normalizeTimestamp replaces a valid timestamp at path by the rule's token.
+func (c *Comparator) normalizeTimestamp(value interface{}, path []string, state *compareState) (interface{}, bool) {
+	rule, ok := c.timestampRuleFor(path)
+	if !ok || rule.Token == "" {
+		return nil, false
+	}
+
+	if _, valid := parseTimestamp(value); !valid {
+		return nil, false
+	}
+
+	state.ignore(FormatPath(path), "Timestamp "+rule.Path+" normalized to "+rule.Token)
+
+	return rule.Token, true
+}
+
+// timestampsMatch reports whether expected and actual at path are timestamps
+// within the configured tolerance of each other.
+func (c *Comparator) timestampsMatch(expected, actual interface{}, path []string) bool {
+	rule, ok := c.timestampRuleFor(path)
+	if !ok || rule.Token != "" {
+		return false
+	}
+
+	expectedTime, ok := parseTimestamp(expected)
+	if !ok {
+		return false
+	}
+
+	actualTime, ok := parseTimestamp(actual)
+	if !ok {
+		return false
+	}
+
+	delta := expectedTime.Sub(actualTime)
+	if delta < 0 {
+		delta = -delta
+	}
+
+	return delta <= rule.Tolerance
+}
+
+// parseTimestamp parses an RFC3339 string or a Unix time number.
+func parseTimestamp(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+
+		return t, err == nil
+	case float64:
+		if math.Abs(v) >= unixMillisThreshold {
+			return time.UnixMilli(int64(v)), true
+		}
+
+		sec, frac := math.Modf(v)
+
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))), true
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		return parseTimestamp(f)
+	}
+
+	return time.Time{}, false
+}
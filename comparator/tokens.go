@@ -0,0 +1,132 @@
+package comparator
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sourceToken is a token of source code with the line it starts on.
+type sourceToken struct {
+	text string
+	line int
+}
+
+// compareTokens compares source code token by token, ignoring formatting.
+// Go source is optionally run through go/format first, so that differences
+// between gofmt versions do not matter either.
+func (c *Comparator) compareTokens(expected, actual []byte) *CompareResult {
+	if c.options.FormatGo {
+		expected, actual = formatGo(expected), formatGo(actual)
+	}
+
+	expectedTokens, actualTokens := tokenize(string(expected)), tokenize(string(actual))
+
+	for i := 0; i < max(len(expectedTokens), len(actualTokens)); i++ {
+		switch {
+		case i >= len(actualTokens):
+			return &CompareResult{Details: fmt.Sprintf(
+				"Token comparison: missing %q from expected line %d", expectedTokens[i].text, expectedTokens[i].line)}
+		case i >= len(expectedTokens):
+			return &CompareResult{Details: fmt.Sprintf(
+				"Token comparison: unexpected %q at actual line %d", actualTokens[i].text, actualTokens[i].line)}
+		case expectedTokens[i].text != actualTokens[i].text:
+			return &CompareResult{Details: fmt.Sprintf(
+				"Token comparison: expected %q at line %d, got %q at line %d",
+				expectedTokens[i].text, expectedTokens[i].line, actualTokens[i].text, actualTokens[i].line)}
+		}
+	}
+
+	return &CompareResult{Equal: true, Details: "Token comparison"}
+}
+
+// formatGo formats Go source, returning it unchanged if it does not parse.
+func formatGo(src []byte) []byte {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return src
+	}
+
+	return formatted
+}
+
+// tokenize splits source code into identifiers, numbers, string and rune
+// literals, comments and single punctuation characters. Whitespace between
+// tokens is dropped and whitespace inside comments is collapsed.
+func tokenize(src string) []sourceToken {
+	var tokens []sourceToken
+
+	line := 1
+
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+
+		var n int
+
+		switch {
+		case r == '\n':
+			line++
+			i++
+
+			continue
+		case unicode.IsSpace(r):
+			i += size
+
+			continue
+		case unicode.IsDigit(r):
+			// Numbers continue across dots, e.g. 1.5, but identifiers do not
+			n = strings.IndexFunc(src[i:], func(r rune) bool { return r != '.' && !isIdentifierRune(r) })
+		case isIdentifierRune(r):
+			n = strings.IndexFunc(src[i:], func(r rune) bool { return !isIdentifierRune(r) })
+		case r == '"' || r == '\'' || r == '`':
+			n = literalEnd(src[i:], byte(r))
+		case strings.HasPrefix(src[i:], "//"):
+			n = strings.IndexByte(src[i:], '\n')
+		case strings.HasPrefix(src[i:], "/*"):
+			if end := strings.Index(src[i+2:], "*/"); end >= 0 {
+				n = end + 4
+			}
+		default:
+			n = size
+		}
+
+		if n <= 0 {
+			n = len(src) - i
+		}
+
+		text := src[i : i+n]
+		if strings.HasPrefix(text, "//") || strings.HasPrefix(text, "/*") {
+			text = strings.Join(strings.Fields(text), " ")
+		}
+
+		tokens = append(tokens, sourceToken{text: text, line: line})
+		line += strings.Count(src[i:i+n], "\n")
+		i += n
+	}
+
+	return tokens
+}
+
+// isIdentifierRune reports whether r may appear in an identifier.
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// literalEnd returns the length of the string or rune literal at the start
+// of s delimited by quote, or 0 if it is unterminated.
+func literalEnd(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote != '`':
+			i++
+		case s[i] == quote:
+			return i + 1
+		case s[i] == '\n' && quote != '`':
+			return i
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,79 @@
+package comparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// DecodeTOML decodes a TOML document into JSON-compatible values, like
+// DecodeYAML. Offset date-times become RFC3339 strings, and local dates and
+// times keep their TOML representation.
+func DecodeTOML(data []byte) (interface{}, error) {
+	var doc map[string]interface{}
+
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode TOML: %w", err)
+	}
+
+	return tomlToJSON(doc), nil
+}
+
+// tomlToJSON converts a decoded TOML value to the types encoding/json
+// decodes into with UseNumber.
+func tomlToJSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, value := range val {
+			val[key] = tomlToJSON(value)
+		}
+
+		return val
+	case []interface{}:
+		for i, value := range val {
+			val[i] = tomlToJSON(value)
+		}
+
+		return val
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(val, 'g', -1, 64))
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+		return fmt.Sprint(val)
+	default:
+		return val
+	}
+}
+
+// compareTOML compares TOML documents semantically: key order, table
+// layout and comments do not matter. Content that is not TOML on either
+// side, such as a plain string asserted with the TOML format, is compared
+// as text, and the parse error is kept in the details.
+func (c *Comparator) compareTOML(expected, actual []byte) *CompareResult {
+	expectedObj, err := DecodeTOML(expected)
+	if err != nil {
+		return c.compareNotTOML(expected, actual, "expected", err)
+	}
+
+	actualObj, err := DecodeTOML(actual)
+	if err != nil {
+		return c.compareNotTOML(expected, actual, "actual", err)
+	}
+
+	return c.compareDecoded(expectedObj, actualObj, "TOML comparison")
+}
+
+// compareNotTOML compares expected and actual as text because side failed
+// to parse as TOML with err.
+func (c *Comparator) compareNotTOML(expected, actual []byte, side string, err error) *CompareResult {
+	result := c.compareText(expected, actual)
+	result.Details = fmt.Sprintf("%s (failed to parse %s TOML: %v)", result.Details, side, err)
+
+	return result
+}
@@ -0,0 +1,90 @@
+package comparator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ignoredValueToken replaces values ignored by their shape.
+const ignoredValueToken = "<<IGNORED>>"
+
+// valuePattern is an IgnoreValues expression compiled to match whole JSON
+// string values and occurrences within text.
+type valuePattern struct {
+	expr  string
+	value *regexp.Regexp
+	text  *regexp.Regexp
+}
+
+// compileValuePatterns compiles IgnoreValues expressions, returning the
+// error of the first one that does not compile.
+func compileValuePatterns(exprs []string) ([]valuePattern, error) {
+	patterns := make([]valuePattern, len(exprs))
+
+	for i, expr := range exprs {
+		pattern, err := compileValuePattern(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		patterns[i] = pattern
+	}
+
+	return patterns, nil
+}
+
+// ValidateIgnoreValue reports whether expr is a valid IgnoreValues
+// expression.
+func ValidateIgnoreValue(expr string) error {
+	_, err := compileValuePattern(expr)
+
+	return err
+}
+
+// compileValuePattern compiles an IgnoreValues expression.
+func compileValuePattern(expr string) (valuePattern, error) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(expr, "^"), "$")
+
+	text, err := regexp.Compile(inner)
+	if err != nil {
+		return valuePattern{}, fmt.Errorf("invalid ignored value pattern %q: %w", expr, err)
+	}
+
+	return valuePattern{
+		expr:  expr,
+		value: regexp.MustCompile(`^(?:` + inner + `)$`),
+		text:  text,
+	}, nil
+}
+
+// ignoreValue returns the token replacing v when it is a string whose shape
+// is ignored, recording it as ignored.
+func (c *Comparator) ignoreValue(v interface{}, path []string, state *compareState) (string, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return "", false
+	}
+
+	for _, pattern := range c.valuePatterns {
+		if pattern.value.MatchString(s) {
+			state.ignore(FormatPath(path), "IgnoreValues "+pattern.expr)
+
+			return ignoredValueToken, true
+		}
+	}
+
+	return "", false
+}
+
+// ignoreTextValues replaces occurrences of ignored value shapes in text.
+func (c *Comparator) ignoreTextValues(s string, state *compareState) string {
+	for _, pattern := range c.valuePatterns {
+		if replaced := pattern.text.ReplaceAllLiteralString(s, ignoredValueToken); replaced != s {
+			state.ignore("(text)", "IgnoreValues "+pattern.expr)
+			s = replaced
+		}
+	}
+
+	return s
+}
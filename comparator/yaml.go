@@ -0,0 +1,206 @@
+package comparator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeYAML decodes every document of a YAML stream into JSON-compatible
+// values: objects with string keys, json.Number numbers and RFC3339 strings
+// for timestamps, so that YAML is compared like JSON.
+func DecodeYAML(data []byte) ([]interface{}, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	var documents []interface{}
+
+	for {
+		var doc interface{}
+
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML document %d: %w", len(documents)+1, err)
+		}
+
+		documents = append(documents, yamlToJSON(doc))
+	}
+}
+
+// yamlToJSON converts a decoded YAML value to the types encoding/json
+// decodes into with UseNumber.
+func yamlToJSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, value := range val {
+			val[key] = yamlToJSON(value)
+		}
+
+		return val
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(val))
+		for key, value := range val {
+			obj[fmt.Sprint(key)] = yamlToJSON(value)
+		}
+
+		return obj
+	case []interface{}:
+		for i, value := range val {
+			val[i] = yamlToJSON(value)
+		}
+
+		return val
+	case int:
+		return json.Number(strconv.Itoa(val))
+	case uint64:
+		return json.Number(strconv.FormatUint(val, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(val, 'g', -1, 64))
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	default:
+		return val
+	}
+}
+
+// compareYAML compares YAML streams document by document. Documents are
+// paired by the values at YAMLDocumentKeys when set, e.g. "kind" and
+// "metadata.name" for Kubernetes manifests, and by index otherwise.
+// Difference paths start with the index of the document pair.
+func (c *Comparator) compareYAML(expected, actual []byte) *CompareResult {
+	expectedDocs, err := DecodeYAML(expected)
+	if err != nil {
+		return &CompareResult{Details: fmt.Sprintf("Failed to parse expected YAML: %v", err)}
+	}
+
+	actualDocs, err := DecodeYAML(actual)
+	if err != nil {
+		return &CompareResult{Details: fmt.Sprintf("Failed to parse actual YAML: %v", err)}
+	}
+
+	result := &CompareResult{Equal: true}
+
+	var ignored []Ignored
+
+	for i, pair := range c.pairDocuments(expectedDocs, actualDocs) {
+		pointer := "/" + strconv.Itoa(i)
+
+		switch {
+		case !pair.hasActual:
+			result.Differences = append(result.Differences, Difference{Path: pointer, Kind: DifferenceMissing, Expected: pair.expected})
+		case !pair.hasExpected:
+			result.Differences = append(result.Differences, Difference{Path: pointer, Kind: DifferenceUnexpected, Actual: pair.actual})
+		default:
+			docResult := c.compareDecoded(pair.expected, pair.actual, "YAML document")
+
+			for _, d := range docResult.Differences {
+				d.Path = pointer + d.Path
+				result.Differences = append(result.Differences, d)
+			}
+
+			for _, item := range docResult.Ignored {
+				item.Path = "[" + strconv.Itoa(i) + "]." + item.Path
+				ignored = append(ignored, item)
+			}
+
+			result.Equal = result.Equal && docResult.Equal
+		}
+	}
+
+	result.Equal = result.Equal && len(result.Differences) == 0
+	result.Ignored = ignored
+	result.Details = fmt.Sprintf("YAML stream comparison: %d document(s), %d difference(s)", len(expectedDocs), len(result.Differences))
+
+	return result
+}
+
+// documentPair pairs an expected document with an actual one. Documents may
+// be null, so presence is tracked separately.
+type documentPair struct {
+	expected, actual       interface{}
+	hasExpected, hasActual bool
+}
+
+// pairDocuments pairs expected and actual documents. Unpaired expected
+// documents come first in their order, followed by unpaired actual ones.
+func (c *Comparator) pairDocuments(expected, actual []interface{}) []documentPair {
+	var pairs []documentPair
+
+	if len(c.options.YAMLDocumentKeys) == 0 {
+		for i := 0; i < max(len(expected), len(actual)); i++ {
+			pair := documentPair{hasExpected: i < len(expected), hasActual: i < len(actual)}
+
+			if pair.hasExpected {
+				pair.expected = expected[i]
+			}
+
+			if pair.hasActual {
+				pair.actual = actual[i]
+			}
+
+			pairs = append(pairs, pair)
+		}
+
+		return pairs
+	}
+
+	used := make([]bool, len(actual))
+
+	for _, exp := range expected {
+		pair := documentPair{expected: exp, hasExpected: true}
+		identity := c.documentIdentity(exp)
+
+		for j, act := range actual {
+			if !used[j] && c.documentIdentity(act) == identity {
+				used[j] = true
+				pair.actual, pair.hasActual = act, true
+
+				break
+			}
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	for j, act := range actual {
+		if !used[j] {
+			pairs = append(pairs, documentPair{actual: act, hasActual: true})
+		}
+	}
+
+	return pairs
+}
+
+// documentIdentity returns the values of the document keys of doc.
+func (c *Comparator) documentIdentity(doc interface{}) string {
+	values := make([]string, len(c.options.YAMLDocumentKeys))
+
+	for i, key := range c.options.YAMLDocumentKeys {
+		value := doc
+
+		for _, segment := range strings.Split(key, ".") {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+
+				break
+			}
+
+			value = obj[segment]
+		}
+
+		values[i] = fmt.Sprint(value)
+	}
+
+	return strings.Join(values, "\x00")
+}
@@ -0,0 +1,57 @@
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sivchari/golden/manager"
+)
+
+// ErrComparison is returned by Compare when the comparison could not be
+// carried out, e.g. because the golden file is missing outside update mode.
+var ErrComparison = errors.New("golden comparison failed")
+
+// Result is the outcome of Compare.
+type Result struct {
+	Filename string // Golden file compared or written
+	Match    bool   // Whether actual matched the golden file, always true in update mode
+	Updated  bool   // Whether update mode created or changed the golden file
+	Diff     string // Failure message with the diff, without colors, if actual did not match
+}
+
+// Compare compares actual with the golden file name like Golden.Assert,
+// or writes it in update mode, without a testing.T, so that the comparison
+// and update machinery can be reused by CLIs, approval scripts and custom
+// harnesses. Golden files are named after name alone with
+// manager.FlatNaming, under testdata relative to the working directory,
+// unless WithNamingStrategy or WithBaseDir say otherwise.
+//
+// A mismatch is not an error: it is reported by Result.Match and
+// Result.Diff. Errors wrapping ErrComparison are returned when the golden
+// file cannot be read, written or compared.
+func Compare(name string, actual any, opts ...Option) (*Result, error) {
+	ctb := newCaptureTB(nil, nil)
+	result := &Result{}
+
+	var g *Golden
+
+	ctb.run(func(tb testing.TB) {
+		g = New(tb, append([]Option{WithNamingStrategy(&manager.FlatNaming{})}, opts...)...)
+		g.result = result
+		g.Assert(name, actual)
+	})
+
+	if g != nil {
+		updated, _ := g.manager.WriteStats()
+		result.Updated = updated > 0
+	}
+
+	result.Match = !ctb.Failed()
+	if !result.Match && result.Diff == "" {
+		return result, fmt.Errorf("%w: %s", ErrComparison, strings.Join(ctb.messages(), "; "))
+	}
+
+	return result, nil
+}
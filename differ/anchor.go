@@ -0,0 +1,226 @@
+package differ
+
+import (
+	"strings"
+)
+
+// maxAnchorCells bounds the size of the longest common subsequence table of
+// anchored diffs, quadratic in the line counts. Larger inputs fall back to
+// the Myers diff.
+const maxAnchorCells = 4_000_000
+
+// anchoredDiff aligns JSON and YAML array elements by the configured anchor
+// key. Lines holding the anchor key ("id": 42) act as synchronization points:
+// anchors present on both sides are matched, and the lines between two
+// matched anchors are diffed on their own, so inserting an element only
+// affects the lines of that element.
+func (d *Differ) anchoredDiff(expected, actual []string) *Diff {
+	if (len(expected)+1)*(len(actual)+1) > maxAnchorCells {
+		return d.myersDiff(expected, actual)
+	}
+
+	anchorsA := d.anchorLines(expected)
+	anchorsB := d.anchorLines(actual)
+
+	keysA := make([]string, len(anchorsA))
+	for i, idx := range anchorsA {
+		keysA[i] = anchorValue(expected[idx])
+	}
+
+	keysB := make([]string, len(anchorsB))
+	for i, idx := range anchorsB {
+		keysB[i] = anchorValue(actual[idx])
+	}
+
+	diff := &Diff{}
+	prevA, prevB := 0, 0
+
+	for _, pair := range lcsPairs(keysA, keysB) {
+		a, b := anchorsA[pair[0]], anchorsB[pair[1]]
+
+		diff.Chunks = append(diff.Chunks, lcsChunks(expected[prevA:a], actual[prevB:b], prevA, prevB)...)
+		diff.Chunks = append(diff.Chunks, lcsChunks(expected[a:a+1], actual[b:b+1], a, b)...)
+		prevA, prevB = a+1, b+1
+	}
+
+	diff.Chunks = append(diff.Chunks, lcsChunks(expected[prevA:], actual[prevB:], prevA, prevB)...)
+
+	diff.Equal = chunksEqual(diff.Chunks)
+
+	return diff
+}
+
+// anchorLines returns the indexes of lines holding the anchor key of an
+// object that is a direct array element. Keys of nested objects are not
+// anchors, as they do not identify an element. YAML block sequences are
+// searched when no JSON anchor is found.
+func (d *Differ) anchorLines(lines []string) []int {
+	if indexes := d.jsonAnchorLines(lines); len(indexes) > 0 {
+		return indexes
+	}
+
+	return d.yamlAnchorLines(lines)
+}
+
+// jsonAnchorLines returns the indexes of JSON anchor lines.
+func (d *Differ) jsonAnchorLines(lines []string) []int {
+	prefix := `"` + d.options.AnchorKey + `":`
+
+	var (
+		indexes []int
+		open    []byte // Brackets enclosing the current line
+	)
+
+	for i, line := range lines {
+		n := len(open)
+		if n >= 2 && open[n-1] == '{' && open[n-2] == '[' && strings.HasPrefix(strings.TrimSpace(line), prefix) {
+			indexes = append(indexes, i)
+		}
+
+		open = trackBrackets(open, line)
+	}
+
+	return indexes
+}
+
+// yamlAnchorLines returns the indexes of YAML anchor lines, either starting
+// a sequence item ("- id: 42") or at the indentation of an item's keys.
+func (d *Differ) yamlAnchorLines(lines []string) []int {
+	prefix := d.options.AnchorKey + ":"
+
+	var (
+		indexes []int
+		items   []int // Key indentation of the enclosing sequence items
+	)
+
+	for i, line := range lines {
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		for len(items) > 0 && items[len(items)-1] > indent {
+			items = items[:len(items)-1]
+		}
+
+		if item, ok := strings.CutPrefix(content, "- "); ok {
+			items = append(items, indent+2)
+
+			if strings.HasPrefix(item, prefix) {
+				indexes = append(indexes, i)
+			}
+
+			continue
+		}
+
+		if len(items) > 0 && items[len(items)-1] == indent && strings.HasPrefix(content, prefix) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+// trackBrackets returns the brackets open after line, given those open
+// before it, skipping the brackets in strings.
+func trackBrackets(open []byte, line string) []byte {
+	inString, escaped := false, false
+
+	for i := range len(line) {
+		switch c := line[i]; {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+			// Brackets in strings are text
+		case c == '{' || c == '[':
+			open = append(open, c)
+		case (c == '}' || c == ']') && len(open) > 0:
+			open = open[:len(open)-1]
+		}
+	}
+
+	return open
+}
+
+// anchorValue returns the comparable part of an anchor line, ignoring
+// indentation, the trailing comma and the sequence item marker that depend
+// on key order.
+func anchorValue(line string) string {
+	return strings.TrimPrefix(strings.TrimSuffix(strings.TrimSpace(line), ","), "- ")
+}
+
+// lcsPairs returns the index pairs of a longest common subsequence of a and b.
+func lcsPairs(a, b []string) [][2]int {
+	// table[i][j] holds the LCS length of a[i:] and b[j:]
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	var pairs [][2]int
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return pairs
+}
+
+// lcsChunks diffs two line ranges with a longest common subsequence,
+// producing single-line chunks positioned at the given offsets.
+func lcsChunks(a, b []string, offsetA, offsetB int) []DiffChunk {
+	var (
+		chunks []DiffChunk
+		i, j   int
+	)
+
+	for _, pair := range append(lcsPairs(a, b), [2]int{len(a), len(b)}) {
+		// Pair up leftover lines as replacements before pure deletes/inserts
+		for ; i < pair[0] && j < pair[1]; i, j = i+1, j+1 {
+			chunks = append(chunks, DiffChunk{
+				Type: ChunkReplace, Lines: []string{a[i], b[j]},
+				StartA: offsetA + i, StartB: offsetB + j, CountA: 1, CountB: 1,
+			})
+		}
+
+		for ; i < pair[0]; i++ {
+			chunks = append(chunks, DiffChunk{Type: ChunkDelete, Lines: []string{a[i]}, StartA: offsetA + i, CountA: 1})
+		}
+
+		for ; j < pair[1]; j++ {
+			chunks = append(chunks, DiffChunk{Type: ChunkInsert, Lines: []string{b[j]}, StartB: offsetB + j, CountB: 1})
+		}
+
+		if i < len(a) && j < len(b) {
+			chunks = append(chunks, DiffChunk{
+				Type: ChunkEqual, Lines: []string{a[i]},
+				StartA: offsetA + i, StartB: offsetB + j, CountA: 1, CountB: 1,
+			})
+			i++
+			j++
+		}
+	}
+
+	return chunks
+}
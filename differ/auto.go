@@ -0,0 +1,257 @@
+package differ
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// autoMaxMyersLines is the largest combined line count diffed with Myers in
+// AlgorithmAuto. The changed region of larger inputs is summarized by hash.
+const autoMaxMyersLines = 2000
+
+// autoAnchorKeys are the keys preferred to identify elements of arrays of
+// objects, before any other key holding unique scalar values.
+var autoAnchorKeys = []string{"id", "name", "key"}
+
+// autoDiff picks a diff strategy based on the size and shape of the input:
+// a hash summary for binary content, a linear prefix/suffix diff for huge
+// inputs, key-anchored alignment for JSON and YAML arrays of objects and
+// Myers for ordinary text.
+func (d *Differ) autoDiff(expected, actual []byte, expectedLines, actualLines []string) *Diff {
+	switch {
+	case isBinary(expected) || isBinary(actual):
+		return binarySummary(expected, actual)
+	case len(expectedLines)+len(actualLines) > autoMaxMyersLines:
+		return d.hashDiff(expectedLines, actualLines)
+	}
+
+	if key := structuralAnchorKey(expected); key != "" {
+		anchored := *d
+		anchored.options.AnchorKey = key
+
+		return anchored.anchoredDiff(expectedLines, actualLines)
+	}
+
+	return d.myersDiff(expectedLines, actualLines)
+}
+
+// autoLines runs autoDiff on split lines, for selecting it by name.
+func (d *Differ) autoLines(expected, actual []string) *Diff {
+	return d.autoDiff([]byte(strings.Join(expected, "\n")), []byte(strings.Join(actual, "\n")), expected, actual)
+}
+
+// structuralAnchorKey parses content as JSON or YAML and returns the key
+// identifying the elements of its first array of objects, or "" if the
+// content has no such array.
+func structuralAnchorKey(content []byte) string {
+	var value any
+
+	switch detectSyntax(content) {
+	case SyntaxJSON:
+		if json.Unmarshal(content, &value) != nil {
+			return ""
+		}
+	case SyntaxYAML:
+		if yaml.Unmarshal(content, &value) != nil {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	return findAnchorKey(value)
+}
+
+// findAnchorKey searches value breadth-first for an array of objects and
+// returns the key identifying its elements.
+func findAnchorKey(value any) string {
+	for queue := []any{value}; len(queue) > 0; queue = queue[1:] {
+		switch v := queue[0].(type) {
+		case map[string]any:
+			for _, k := range sortedKeys(v) {
+				queue = append(queue, v[k])
+			}
+		case []any:
+			if key := identifyingKey(v); key != "" {
+				return key
+			}
+
+			queue = append(queue, v...)
+		}
+	}
+
+	return ""
+}
+
+// identifyingKey returns a key present in every element of an array of
+// objects with unique scalar values, preferring autoAnchorKeys.
+func identifyingKey(elements []any) string {
+	objects := make([]map[string]any, 0, len(elements))
+
+	for _, element := range elements {
+		object, ok := element.(map[string]any)
+		if !ok {
+			return ""
+		}
+
+		objects = append(objects, object)
+	}
+
+	if len(objects) == 0 {
+		return ""
+	}
+
+	candidates := sortedKeys(objects[0])
+	slices.SortStableFunc(candidates, func(a, b string) int {
+		return anchorRank(a) - anchorRank(b)
+	})
+
+	for _, key := range candidates {
+		if uniqueScalars(objects, key) {
+			return key
+		}
+	}
+
+	return ""
+}
+
+// anchorRank orders the preferred autoAnchorKeys before any other key.
+func anchorRank(key string) int {
+	if i := slices.Index(autoAnchorKeys, key); i >= 0 {
+		return i
+	}
+
+	return len(autoAnchorKeys)
+}
+
+// uniqueScalars reports whether every object holds a distinct scalar value
+// under key.
+func uniqueScalars(objects []map[string]any, key string) bool {
+	seen := make(map[string]bool, len(objects))
+
+	for _, object := range objects {
+		switch value := object[key].(type) {
+		case nil, map[string]any, []any:
+			return false
+		default:
+			s := fmt.Sprint(value)
+			if seen[s] {
+				return false
+			}
+
+			seen[s] = true
+		}
+	}
+
+	return true
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+// hashDiff diffs huge inputs in linear time: the common prefix and suffix
+// are matched line by line, and the remaining region is diffed with Myers
+// if small enough, or summarized by line count and hash otherwise.
+func (d *Differ) hashDiff(expected, actual []string) *Diff {
+	prefix := 0
+	for prefix < len(expected) && prefix < len(actual) && d.linesEqual(expected[prefix], actual[prefix]) {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(expected)-prefix && suffix < len(actual)-prefix &&
+		d.linesEqual(expected[len(expected)-1-suffix], actual[len(actual)-1-suffix]) {
+		suffix++
+	}
+
+	midA := expected[prefix : len(expected)-suffix]
+	midB := actual[prefix : len(actual)-suffix]
+
+	diff := &Diff{Chunks: equalChunks(expected[:prefix], 0, 0)}
+
+	if len(midA)+len(midB) <= autoMaxMyersLines {
+		for _, chunk := range d.myersDiff(midA, midB).Chunks {
+			chunk.StartA += prefix
+			chunk.StartB += prefix
+			diff.Chunks = append(diff.Chunks, chunk)
+		}
+	} else {
+		diff.Chunks = append(diff.Chunks, DiffChunk{
+			Type:   ChunkReplace,
+			Lines:  []string{linesDescription(midA, prefix), linesDescription(midB, prefix)},
+			StartA: prefix,
+			StartB: prefix,
+			CountA: 1,
+			CountB: 1,
+		})
+	}
+
+	diff.Chunks = append(diff.Chunks, equalChunks(expected[len(expected)-suffix:], len(expected)-suffix, len(actual)-suffix)...)
+	diff.Equal = chunksEqual(diff.Chunks)
+
+	return diff
+}
+
+// equalChunks returns single-line equal chunks for lines at the given offsets.
+func equalChunks(lines []string, startA, startB int) []DiffChunk {
+	chunks := make([]DiffChunk, 0, len(lines))
+
+	for i, line := range lines {
+		chunks = append(chunks, DiffChunk{
+			Type: ChunkEqual, Lines: []string{line},
+			StartA: startA + i, StartB: startB + i, CountA: 1, CountB: 1,
+		})
+	}
+
+	return chunks
+}
+
+// linesDescription summarizes a region of lines too large to diff in a
+// single line.
+func linesDescription(lines []string, start int) string {
+	if len(lines) == 0 {
+		return fmt.Sprintf("<no lines at line %d>", start+1)
+	}
+
+	return fmt.Sprintf("<%d lines from line %d, sha256 %x>", len(lines), start+1, sha256.Sum256([]byte(strings.Join(lines, "\n"))))
+}
+
+// isBinary reports whether data looks like binary rather than text content.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+// binarySummary describes two binary inputs by size and hash instead of lines.
+func binarySummary(expected, actual []byte) *Diff {
+	if bytes.Equal(expected, actual) {
+		return &Diff{Equal: true}
+	}
+
+	return &Diff{Chunks: []DiffChunk{{
+		Type:   ChunkReplace,
+		Lines:  []string{binaryDescription(expected), binaryDescription(actual)},
+		CountA: 1,
+		CountB: 1,
+	}}}
+}
+
+// binaryDescription summarizes binary content in a single line.
+func binaryDescription(data []byte) string {
+	return fmt.Sprintf("<binary content: %d bytes, sha256 %x>", len(data), sha256.Sum256(data))
+}
@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -15,8 +16,15 @@ type Differ struct {
 
 // Options configures diff behavior.
 type Options struct {
-	ContextLines int
-	Algorithm    DiffAlgorithm
+	ContextLines     int // Equal lines shown around each change (0 shows every line)
+	Algorithm        DiffAlgorithm
+	SortLines        bool   // Diff against sorted views of both inputs
+	Syntax           Syntax // Syntax highlighting of diff content
+	DetectMoves      bool   // Render moved blocks instead of deletes and inserts
+	IgnoreWhitespace bool   // Treat lines differing only in whitespace as equal
+	AnchorKey        string // Align JSON array elements by this key instead of by index
+	Width            int    // Terminal width for soft-wrapping long lines (0 disables)
+	AlgorithmName    string // Registered algorithm to use instead of Algorithm and AnchorKey
 }
 
 // DiffAlgorithm specifies the diff algorithm to use.
@@ -27,9 +35,13 @@ const (
 	AlgorithmMyers DiffAlgorithm = iota
 	// AlgorithmSimple uses a simple line-by-line comparison.
 	AlgorithmSimple
+	// AlgorithmAuto picks a strategy based on the size and shape of the input.
+	AlgorithmAuto
 )
 
 // DiffChunk represents a chunk of differences.
+// For ChunkReplace, Lines holds the CountA expected lines followed by the
+// CountB actual lines.
 type DiffChunk struct {
 	Type   ChunkType
 	Lines  []string
@@ -51,12 +63,17 @@ const (
 	ChunkInsert
 	// ChunkReplace represents replaced content.
 	ChunkReplace
+	// ChunkMovedFrom represents content moved away from StartA.
+	ChunkMovedFrom
+	// ChunkMovedTo represents content moved to StartB.
+	ChunkMovedTo
 )
 
 // Diff represents the complete diff between two texts.
 type Diff struct {
 	Chunks []DiffChunk
 	Equal  bool
+	Syntax Syntax // Syntax used when formatting, resolved from Options.Syntax
 }
 
 // New creates a new Differ with default options.
@@ -69,8 +86,13 @@ func New() *Differ {
 	}
 }
 
-// NewWithOptions creates a new Differ with custom options.
+// NewWithOptions creates a new Differ with custom options. An AlgorithmName
+// that is not registered is ignored in favor of Algorithm.
 func NewWithOptions(opts Options) *Differ {
+	if _, ok := Lookup(opts.AlgorithmName); !ok {
+		opts.AlgorithmName = ""
+	}
+
 	return &Differ{options: opts}
 }
 
@@ -79,14 +101,42 @@ func (d *Differ) Diff(expected, actual []byte) *Diff {
 	expectedLines := d.splitLines(expected)
 	actualLines := d.splitLines(actual)
 
-	switch d.options.Algorithm {
-	case AlgorithmMyers:
-		return d.myersDiff(expectedLines, actualLines)
-	case AlgorithmSimple:
-		return d.simpleDiff(expectedLines, actualLines)
+	if d.options.SortLines {
+		expectedLines = sortLines(expectedLines)
+		actualLines = sortLines(actualLines)
+	}
+
+	var diff *Diff
+
+	if d.options.AlgorithmName != "" {
+		diff = d.namedDiff(d.options.AlgorithmName, expectedLines, actualLines)
+	}
+
+	switch {
+	case diff != nil:
+		// Computed by the named algorithm
+	case d.options.AnchorKey != "":
+		diff = d.anchoredDiff(expectedLines, actualLines)
+	case d.options.Algorithm == AlgorithmMyers:
+		diff = d.myersDiff(expectedLines, actualLines)
+	case d.options.Algorithm == AlgorithmAuto:
+		diff = d.autoDiff(expected, actual, expectedLines, actualLines)
 	default:
-		return d.simpleDiff(expectedLines, actualLines)
+		diff = d.simpleDiff(expectedLines, actualLines)
+	}
+
+	if d.options.DetectMoves && !diff.Equal {
+		detectMoves(diff)
 	}
+
+	diff.Chunks = coalesce(diff.Chunks)
+
+	diff.Syntax = d.options.Syntax
+	if diff.Syntax == SyntaxAuto {
+		diff.Syntax = detectSyntax(expected)
+	}
+
+	return diff
 }
 
 // Format formats a diff for display.
@@ -97,16 +147,20 @@ func (d *Differ) Format(diff *Diff) string {
 
 	var buf strings.Builder
 
-	for _, chunk := range diff.Chunks {
+	for i, chunk := range diff.Chunks {
 		switch chunk.Type {
 		case ChunkEqual:
-			d.formatEqualChunk(&buf, chunk)
+			d.formatEqualChunk(&buf, chunk, diff.Syntax, i > 0, i < len(diff.Chunks)-1)
 		case ChunkDelete:
-			d.formatDeleteChunk(&buf, chunk)
+			d.formatDeleteChunk(&buf, chunk, diff.Syntax)
 		case ChunkInsert:
-			d.formatInsertChunk(&buf, chunk)
+			d.formatInsertChunk(&buf, chunk, diff.Syntax)
 		case ChunkReplace:
-			d.formatReplaceChunk(&buf, chunk)
+			d.formatReplaceChunk(&buf, chunk, diff.Syntax)
+		case ChunkMovedFrom:
+			d.formatMovedFromChunk(&buf, chunk)
+		case ChunkMovedTo:
+			d.formatMovedToChunk(&buf, chunk, diff.Syntax)
 		}
 	}
 
@@ -134,6 +188,22 @@ func (d *Differ) splitLines(data []byte) []string {
 	return lines
 }
 
+// sortLines returns a sorted copy of lines, keeping the trailing
+// no-newline marker produced by splitLines at the end.
+func sortLines(lines []string) []string {
+	sorted := make([]string, len(lines))
+	copy(sorted, lines)
+
+	body := sorted
+	if n := len(body); n > 0 && body[n-1] == "" {
+		body = body[:n-1]
+	}
+
+	sort.Strings(body)
+
+	return sorted
+}
+
 // simpleDiff implements a simple line-by-line diff algorithm.
 func (d *Differ) simpleDiff(expected, actual []string) *Diff {
 	diff := &Diff{Equal: true}
@@ -166,7 +236,7 @@ func (d *Differ) simpleDiff(expected, actual []string) *Diff {
 			}
 			diff.Chunks = append(diff.Chunks, chunk)
 			diff.Equal = false
-		case expected[i] == actual[i]:
+		case d.linesEqual(expected[i], actual[i]):
 			// Equal lines
 			chunk := DiffChunk{
 				Type:   ChunkEqual,
@@ -197,54 +267,182 @@ func (d *Differ) simpleDiff(expected, actual []string) *Diff {
 	return diff
 }
 
-// myersDiff implements Myers diff algorithm (simplified version).
-func (d *Differ) myersDiff(expected, actual []string) *Diff {
-	// For now, fall back to simple diff
-	// TODO: Implement full Myers algorithm
-	return d.simpleDiff(expected, actual)
+// linesEqual reports whether two lines are equal under the configured options.
+func (d *Differ) linesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	if d.options.IgnoreWhitespace {
+		return normalizeSpace(a) == normalizeSpace(b)
+	}
+
+	return false
 }
 
-// formatEqualChunk formats equal lines.
-func (d *Differ) formatEqualChunk(buf *strings.Builder, chunk DiffChunk) {
-	for i, line := range chunk.Lines {
-		lineNum := chunk.StartA + i + 1
-		fmt.Fprintf(buf, " %4d  %s\n", lineNum, line)
+// normalizeSpace trims s and collapses whitespace runs into single spaces.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// chunksEqual reports whether all chunks hold equal content.
+func chunksEqual(chunks []DiffChunk) bool {
+	for _, chunk := range chunks {
+		if chunk.Type != ChunkEqual {
+			return false
+		}
+	}
+
+	return true
+}
+
+// coalesce merges consecutive equal chunks, and consecutive delete, insert
+// and replace chunks, into hunks with accurate ranges on both sides.
+func coalesce(chunks []DiffChunk) []DiffChunk {
+	var (
+		merged     []DiffChunk
+		posA, posB int
+		deleted    []string
+		inserted   []string
+		startA     int
+		startB     int
+	)
+
+	flush := func() {
+		if len(deleted) == 0 && len(inserted) == 0 {
+			return
+		}
+
+		chunk := DiffChunk{StartA: startA, StartB: startB, CountA: len(deleted), CountB: len(inserted)}
+
+		switch {
+		case len(inserted) == 0:
+			chunk.Type, chunk.Lines = ChunkDelete, deleted
+		case len(deleted) == 0:
+			chunk.Type, chunk.Lines = ChunkInsert, inserted
+		default:
+			chunk.Type, chunk.Lines = ChunkReplace, append(deleted, inserted...)
+		}
+
+		merged = append(merged, chunk)
+		deleted, inserted = nil, nil
+	}
+
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case ChunkDelete, ChunkInsert, ChunkReplace:
+			if len(deleted) == 0 && len(inserted) == 0 {
+				startA, startB = posA, posB
+			}
+
+			deleted = append(deleted, chunk.Lines[:chunk.CountA]...)
+			inserted = append(inserted, chunk.Lines[chunk.CountA:]...)
+			posA += chunk.CountA
+			posB += chunk.CountB
+
+			continue
+		case ChunkEqual:
+			flush()
+
+			if n := len(merged); n > 0 && merged[n-1].Type == ChunkEqual {
+				merged[n-1].Lines = append(merged[n-1].Lines, chunk.Lines...)
+				merged[n-1].CountA += chunk.CountA
+				merged[n-1].CountB += chunk.CountB
+			} else {
+				chunk.StartA, chunk.StartB = posA, posB
+				chunk.Lines = append([]string(nil), chunk.Lines...)
+				merged = append(merged, chunk)
+			}
+
+			posA += chunk.CountA
+			posB += chunk.CountB
+		case ChunkMovedFrom:
+			flush()
+
+			merged = append(merged, chunk)
+			posA += chunk.CountA
+		case ChunkMovedTo:
+			flush()
+
+			merged = append(merged, chunk)
+			posB += chunk.CountB
+		}
+	}
+
+	flush()
+
+	return merged
+}
+
+// formatEqualChunk formats equal lines, keeping ContextLines of them next
+// to the changes before and after the chunk and eliding the others.
+func (d *Differ) formatEqualChunk(buf *strings.Builder, chunk DiffChunk, syntax Syntax, before, after bool) {
+	head, tail := len(chunk.Lines), 0
+
+	if n := d.options.ContextLines; n > 0 {
+		head, tail = 0, 0
+
+		if before {
+			head = n
+		}
+
+		if after {
+			tail = n
+		}
+
+		// Eliding a single line would not shorten the output
+		if head+tail+1 >= len(chunk.Lines) {
+			head, tail = len(chunk.Lines), 0
+		}
+	}
+
+	for i, line := range chunk.Lines[:head] {
+		d.writeLine(buf, "", ' ', chunk.StartA+i+1, line, syntax)
+	}
+
+	if skipped := len(chunk.Lines) - head - tail; skipped > 0 {
+		fmt.Fprintf(buf, "\033[2m ...  %d unchanged lines\033[0m\n", skipped)
+	}
+
+	for i, line := range chunk.Lines[len(chunk.Lines)-tail:] {
+		d.writeLine(buf, "", ' ', chunk.StartA+len(chunk.Lines)-tail+i+1, line, syntax)
 	}
 }
 
 // formatDeleteChunk formats deleted lines.
-func (d *Differ) formatDeleteChunk(buf *strings.Builder, chunk DiffChunk) {
+func (d *Differ) formatDeleteChunk(buf *strings.Builder, chunk DiffChunk, syntax Syntax) {
 	for i, line := range chunk.Lines {
 		lineNum := chunk.StartA + i + 1
-		d.writeDeleteLine(buf, line, lineNum)
+		d.writeDeleteLine(buf, line, lineNum, syntax)
 	}
 }
 
 // writeDeleteLine writes a single delete line with appropriate formatting.
-func (d *Differ) writeDeleteLine(buf *strings.Builder, line string, lineNum int) {
-	fmt.Fprintf(buf, "\033[31m-%4d  %s\033[0m\n", lineNum, line)
+func (d *Differ) writeDeleteLine(buf *strings.Builder, line string, lineNum int, syntax Syntax) {
+	d.writeLine(buf, "\033[31m", '-', lineNum, line, syntax)
 }
 
 // formatInsertChunk formats inserted lines.
-func (d *Differ) formatInsertChunk(buf *strings.Builder, chunk DiffChunk) {
+func (d *Differ) formatInsertChunk(buf *strings.Builder, chunk DiffChunk, syntax Syntax) {
 	for i, line := range chunk.Lines {
 		lineNum := chunk.StartB + i + 1
-		d.writeInsertLine(buf, line, lineNum)
+		d.writeInsertLine(buf, line, lineNum, syntax)
 	}
 }
 
 // writeInsertLine writes a single insert line with appropriate formatting.
-func (d *Differ) writeInsertLine(buf *strings.Builder, line string, lineNum int) {
-	fmt.Fprintf(buf, "\033[32m+%4d  %s\033[0m\n", lineNum, line)
+func (d *Differ) writeInsertLine(buf *strings.Builder, line string, lineNum int, syntax Syntax) {
+	d.writeLine(buf, "\033[32m", '+', lineNum, line, syntax)
 }
 
 // formatReplaceChunk formats replaced lines.
-func (d *Differ) formatReplaceChunk(buf *strings.Builder, chunk DiffChunk) {
+func (d *Differ) formatReplaceChunk(buf *strings.Builder, chunk DiffChunk, syntax Syntax) {
 	// Show as delete followed by insert
-	expectedLine := chunk.Lines[0]
-	actualLine := chunk.Lines[1]
-	lineNum := chunk.StartA + 1
+	for i, line := range chunk.Lines[:chunk.CountA] {
+		d.writeDeleteLine(buf, line, chunk.StartA+i+1, syntax)
+	}
 
-	d.writeDeleteLine(buf, expectedLine, lineNum)
-	d.writeInsertLine(buf, actualLine, lineNum)
+	for i, line := range chunk.Lines[chunk.CountA:] {
+		d.writeInsertLine(buf, line, chunk.StartB+i+1, syntax)
+	}
 }
@@ -0,0 +1,488 @@
+package differ
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatHighlightsInvisibleCharacters(t *testing.T) {
+	t.Parallel()
+
+	d := New()
+	output := d.Format(d.Diff([]byte("hello world\n"), []byte("hello\u00a0world\u200b\n")))
+
+	for _, want := range []string{"<NBSP>", "<ZWSP>"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Format() output does not contain %s:\n%s", want, output)
+		}
+	}
+}
+
+func TestVisualizeKeepsGraphemeClusters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain text", "plain text"},
+		{"e\u0301", "e\u0301"},                                       // Combining accent
+		{"\U0001f468\u200d\U0001f469", "\U0001f468\u200d\U0001f469"}, // ZWJ emoji sequence
+		{"a\u200db", "a" + invisibleStart + "<ZWJ>" + invisibleEnd + "b"},
+		{"x\u202ey", "x" + invisibleStart + "<RLO>" + invisibleEnd + "y"},
+		{"\u2063", invisibleStart + "<U+2063>" + invisibleEnd},
+	}
+
+	for _, tt := range tests {
+		if got := visualize(tt.input); got != tt.expected {
+			t.Errorf("visualize(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSyntaxFromFilename(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		filename string
+		expected Syntax
+	}{
+		{"testdata/a_TestA_out.golden.go", SyntaxAuto},
+		{"testdata/a_TestA_out.json", SyntaxJSON},
+		{"testdata/a_TestA_out.yaml.golden", SyntaxYAML},
+		{"testdata/a_TestA_out.go", SyntaxGo},
+		{"testdata/a_TestA_out.txt", SyntaxAuto},
+	}
+
+	for _, tt := range tests {
+		if got := SyntaxFromFilename(tt.filename); got != tt.expected {
+			t.Errorf("SyntaxFromFilename(%s) = %d, want %d", tt.filename, got, tt.expected)
+		}
+	}
+}
+
+func TestHighlightJSONKeys(t *testing.T) {
+	t.Parallel()
+
+	d := NewWithOptions(Options{Syntax: SyntaxAuto})
+	diff := d.Diff([]byte(`{"name": "a"}`), []byte(`{"name": "b"}`))
+
+	if diff.Syntax != SyntaxJSON {
+		t.Fatalf("Diff().Syntax = %d, want SyntaxJSON", diff.Syntax)
+	}
+
+	// Keys are bold and string values italic on changed lines
+	output := d.Format(diff)
+	if !strings.Contains(output, "\033[1m\"name\"\033[22m") || !strings.Contains(output, "\033[3m\"b\"\033[23m") {
+		t.Errorf("Format() output is not highlighted:\n%q", output)
+	}
+}
+
+func TestDetectMoves(t *testing.T) {
+	t.Parallel()
+
+	d := NewWithOptions(Options{DetectMoves: true})
+	diff := d.Diff([]byte("alpha\nbeta\ngamma\ndelta\n"), []byte("gamma\ndelta\nalpha\nbeta\n"))
+
+	var from, to []DiffChunk
+
+	for _, chunk := range diff.Chunks {
+		switch chunk.Type {
+		case ChunkMovedFrom:
+			from = append(from, chunk)
+		case ChunkMovedTo:
+			to = append(to, chunk)
+		case ChunkEqual:
+		case ChunkDelete, ChunkInsert, ChunkReplace:
+			t.Errorf("unexpected chunk type %d for lines %v", chunk.Type, chunk.Lines)
+		}
+	}
+
+	if len(from) != 1 || len(to) != 1 {
+		t.Fatalf("expected 1 moved block, got %d from and %d to", len(from), len(to))
+	}
+
+	if from[0].CountA != 2 || from[0].StartA != 0 || from[0].StartB != 2 {
+		t.Errorf("moved block = %+v, want lines 1-2 moved to 3-4", from[0])
+	}
+
+	if output := d.Format(diff); !strings.Contains(output, "[moved lines 1-2 to lines 3-4]") {
+		t.Errorf("Format() output does not annotate the move:\n%s", output)
+	}
+}
+
+func TestDetectMovesIgnoresSingleLines(t *testing.T) {
+	t.Parallel()
+
+	d := NewWithOptions(Options{DetectMoves: true})
+	diff := d.Diff([]byte("alpha\nbeta\ngamma\n"), []byte("beta\ngamma\nalpha\n"))
+
+	for _, chunk := range diff.Chunks {
+		if chunk.Type == ChunkMovedFrom || chunk.Type == ChunkMovedTo {
+			t.Errorf("single line reported as moved: %+v", chunk)
+		}
+	}
+}
+
+func TestIgnoreWhitespaceRendersEqual(t *testing.T) {
+	t.Parallel()
+
+	d := NewWithOptions(Options{IgnoreWhitespace: true})
+	diff := d.Diff([]byte("a  b\nvalue: 1\n"), []byte("a b \nvalue: 2\n"))
+
+	if diff.Chunks[0].Type != ChunkEqual {
+		t.Errorf("whitespace-only change produced chunk type %d, want ChunkEqual", diff.Chunks[0].Type)
+	}
+
+	if diff.Chunks[1].Type != ChunkReplace {
+		t.Errorf("real change produced chunk type %d, want ChunkReplace", diff.Chunks[1].Type)
+	}
+}
+
+func TestAnchoredDiffAlignsByKey(t *testing.T) {
+	t.Parallel()
+
+	expected := `[
+  {
+    "id": 1,
+    "name": "a"
+  },
+  {
+    "id": 2,
+    "name": "b"
+  }
+]
+`
+	actual := `[
+  {
+    "id": 0,
+    "name": "new"
+  },
+  {
+    "id": 1,
+    "name": "a"
+  },
+  {
+    "id": 2,
+    "name": "b"
+  }
+]
+`
+
+	d := NewWithOptions(Options{AnchorKey: "id"})
+	diff := d.Diff([]byte(expected), []byte(actual))
+
+	changed := 0
+
+	for _, chunk := range diff.Chunks {
+		if chunk.Type != ChunkEqual {
+			changed += max(chunk.CountA, chunk.CountB)
+		}
+	}
+
+	// Only the four lines of the inserted element should differ
+	if diff.Equal || changed != 4 {
+		t.Errorf("expected 4 changed lines, got %d:\n%s", changed, d.Format(diff))
+	}
+}
+
+func TestAnchorLinesOnlyArrayElements(t *testing.T) {
+	t.Parallel()
+
+	lines := strings.Split(`{
+  "id": "root",
+  "items": [
+    {
+      "id": 1,
+      "owner": {
+        "id": 9,
+        "note": "[{"
+      }
+    },
+    {
+      "id": 2
+    }
+  ]
+}`, "\n")
+
+	d := NewWithOptions(Options{AnchorKey: "id"})
+
+	if got, want := d.anchorLines(lines), []int{4, 11}; !reflect.DeepEqual(got, want) {
+		t.Errorf("anchorLines() = %v, want %v", got, want)
+	}
+}
+
+func TestAnchoredDiffFallsBackOnLargeInput(t *testing.T) {
+	t.Parallel()
+
+	var expected, actual strings.Builder
+
+	expected.WriteString("[\n")
+	actual.WriteString("[\n")
+
+	for i := range 1100 {
+		fmt.Fprintf(&expected, "  {\n    \"id\": %d\n  },\n", i)
+		fmt.Fprintf(&actual, "  {\n    \"id\": %d\n  },\n", i+1)
+	}
+
+	d := NewWithOptions(Options{AnchorKey: "id"})
+	if diff := d.Diff([]byte(expected.String()), []byte(actual.String())); diff.Equal {
+		t.Error("different inputs diffed as equal")
+	}
+}
+
+func TestDiffCoalescesHunks(t *testing.T) {
+	t.Parallel()
+
+	d := New()
+	diff := d.Diff([]byte("a\nb\nc\nd\n"), []byte("a\nx\ny\nd\ne\n"))
+
+	expected := []DiffChunk{
+		{Type: ChunkEqual, Lines: []string{"a"}, StartA: 0, StartB: 0, CountA: 1, CountB: 1},
+		{Type: ChunkReplace, Lines: []string{"b", "c", "x", "y"}, StartA: 1, StartB: 1, CountA: 2, CountB: 2},
+		{Type: ChunkEqual, Lines: []string{"d"}, StartA: 3, StartB: 3, CountA: 1, CountB: 1},
+		{Type: ChunkInsert, Lines: []string{"e"}, StartA: 4, StartB: 4, CountA: 0, CountB: 1},
+	}
+
+	if len(diff.Chunks) != len(expected) {
+		t.Fatalf("got %d chunks, want %d: %+v", len(diff.Chunks), len(expected), diff.Chunks)
+	}
+
+	for i, chunk := range diff.Chunks {
+		want := expected[i]
+		if chunk.Type != want.Type || strings.Join(chunk.Lines, ",") != strings.Join(want.Lines, ",") ||
+			chunk.StartA != want.StartA || chunk.StartB != want.StartB ||
+			chunk.CountA != want.CountA || chunk.CountB != want.CountB {
+			t.Errorf("chunk %d = %+v, want %+v", i, chunk, want)
+		}
+	}
+}
+
+func TestFormatWrapsLongLines(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("x", 50)
+	d := NewWithOptions(Options{Width: 30})
+	output := d.Format(d.Diff([]byte("short\n"), []byte(long+"\n")))
+
+	// 50 characters at 23 columns of content wrap into three insert lines
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 output lines, got %d:\n%s", len(lines), output)
+	}
+
+	for _, line := range lines[1:] {
+		if !strings.HasPrefix(line, "\033[32m+") || !strings.HasSuffix(line, "\033[0m") {
+			t.Errorf("wrapped line does not keep its color: %q", line)
+		}
+	}
+
+	if !strings.Contains(lines[2], "↪") {
+		t.Errorf("continuation line has no marker: %q", lines[2])
+	}
+}
+
+func TestRegisteredAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	Register("test-everything-changed", AlgorithmFunc(func(expected, actual []string) *Diff {
+		called = true
+
+		return &Diff{Chunks: []DiffChunk{
+			{Type: ChunkReplace, Lines: append(append([]string{}, expected...), actual...), CountA: len(expected), CountB: len(actual)},
+		}}
+	}))
+
+	d := NewWithOptions(Options{AlgorithmName: "test-everything-changed"})
+	diff := d.Diff([]byte("a\n"), []byte("a\n"))
+
+	if !called || len(diff.Chunks) != 1 || diff.Chunks[0].Type != ChunkReplace {
+		t.Errorf("registered algorithm was not used: %+v", diff.Chunks)
+	}
+}
+
+func TestRegisteredAlgorithmOverridesAnchorKey(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	Register("test-anchored", AlgorithmFunc(func(expected, actual []string) *Diff {
+		called = true
+
+		return New().simpleDiff(expected, actual)
+	}))
+
+	NewWithOptions(Options{AlgorithmName: "test-anchored", AnchorKey: "id"}).Diff([]byte("a\n"), []byte("b\n"))
+
+	if !called {
+		t.Error("anchor key took precedence over the registered algorithm")
+	}
+}
+
+func TestRegisteredAlgorithmReturningNil(t *testing.T) {
+	t.Parallel()
+
+	Register("test-nil", AlgorithmFunc(func([]string, []string) *Diff { return nil }))
+
+	d := NewWithOptions(Options{AlgorithmName: "test-nil"})
+	if diff := d.Diff([]byte("a\n"), []byte("b\n")); diff.Equal {
+		t.Error("different inputs diffed as equal")
+	}
+}
+
+func TestUnknownAlgorithmFallsBack(t *testing.T) {
+	t.Parallel()
+
+	d := NewWithOptions(Options{AlgorithmName: "test-unknown", Algorithm: AlgorithmMyers})
+	if diff := d.Diff([]byte("a\n"), []byte("b\n")); diff.Equal || len(diff.Chunks) == 0 {
+		t.Errorf("unknown algorithm did not fall back to the built-in one: %+v", diff)
+	}
+}
+
+func TestBuiltinAlgorithmUsesOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"simple", "myers", "auto"} {
+		d := NewWithOptions(Options{AlgorithmName: name, IgnoreWhitespace: true})
+		if diff := d.Diff([]byte("a  b\n"), []byte("a b\n")); !diff.Equal {
+			t.Errorf("%s: whitespace-only change is not equal: %+v", name, diff.Chunks)
+		}
+	}
+}
+
+func TestMyersDiffIsMinimal(t *testing.T) {
+	t.Parallel()
+
+	d := NewWithOptions(Options{Algorithm: AlgorithmMyers})
+	diff := d.Diff([]byte("a\nb\nc\nd\n"), []byte("x\na\nb\nd\n"))
+
+	var inserted, deleted []string
+
+	for _, chunk := range diff.Chunks {
+		switch chunk.Type {
+		case ChunkInsert:
+			inserted = append(inserted, chunk.Lines...)
+		case ChunkDelete:
+			deleted = append(deleted, chunk.Lines...)
+		case ChunkReplace:
+			deleted = append(deleted, chunk.Lines[:chunk.CountA]...)
+			inserted = append(inserted, chunk.Lines[chunk.CountA:]...)
+		case ChunkEqual, ChunkMovedFrom, ChunkMovedTo:
+		}
+	}
+
+	if strings.Join(inserted, ",") != "x" || strings.Join(deleted, ",") != "c" {
+		t.Errorf("Myers diff inserted %v and deleted %v, want [x] and [c]", inserted, deleted)
+	}
+}
+
+func TestAutoDiffSummarizesBinary(t *testing.T) {
+	t.Parallel()
+
+	d := NewWithOptions(Options{Algorithm: AlgorithmAuto})
+	diff := d.Diff([]byte{0x00, 0x01}, []byte{0x00, 0x02})
+
+	if diff.Equal || len(diff.Chunks) != 1 || !strings.HasPrefix(diff.Chunks[0].Lines[0], "<binary content: 2 bytes") {
+		t.Errorf("binary input was not summarized: %+v", diff.Chunks)
+	}
+}
+
+func TestAutoDiffAnchorsYAMLSequences(t *testing.T) {
+	t.Parallel()
+
+	expected := "items:\n  - name: a\n    value: 1\n  - name: b\n    value: 2\n"
+	actual := "items:\n  - name: new\n    value: 0\n  - name: a\n    value: 1\n  - name: b\n    value: 2\n"
+
+	diff := NewWithOptions(Options{Algorithm: AlgorithmAuto}).Diff([]byte(expected), []byte(actual))
+
+	for _, chunk := range diff.Chunks {
+		if chunk.Type == ChunkReplace || chunk.Type == ChunkDelete {
+			t.Errorf("inserted item changed existing lines: %+v", chunk)
+		}
+	}
+}
+
+func TestAutoDiffIgnoresNestedKeys(t *testing.T) {
+	t.Parallel()
+
+	if key := structuralAnchorKey([]byte(`{"meta": {"id": 1}, "tags": ["a", "b"]}`)); key != "" {
+		t.Errorf("structuralAnchorKey() = %q for content without arrays of objects", key)
+	}
+
+	if key := structuralAnchorKey([]byte(`{"users": [{"uid": 1, "role": "a"}, {"uid": 2, "role": "a"}]}`)); key != "uid" {
+		t.Errorf("structuralAnchorKey() = %q, want the unique key uid", key)
+	}
+}
+
+func TestAutoDiffSummarizesHugeInput(t *testing.T) {
+	t.Parallel()
+
+	var expected, actual strings.Builder
+
+	for i := range 3000 {
+		fmt.Fprintf(&expected, "line %d\n", i)
+
+		if i >= 1000 && i < 2500 {
+			fmt.Fprintf(&actual, "changed %d\n", i)
+		} else {
+			fmt.Fprintf(&actual, "line %d\n", i)
+		}
+	}
+
+	diff := NewWithOptions(Options{Algorithm: AlgorithmAuto}).Diff([]byte(expected.String()), []byte(actual.String()))
+
+	var summaries int
+
+	for _, chunk := range diff.Chunks {
+		if chunk.Type == ChunkEqual {
+			continue
+		}
+
+		if chunk.StartA != 1000 || !strings.HasPrefix(chunk.Lines[0], "<1500 lines from line 1001, sha256 ") {
+			t.Errorf("unexpected chunk: %+v", chunk)
+		}
+
+		summaries++
+	}
+
+	if diff.Equal || summaries != 1 {
+		t.Errorf("huge input was not summarized once: %d summaries", summaries)
+	}
+}
+
+func TestFormatTrimsContext(t *testing.T) {
+	t.Parallel()
+
+	var expected, actual strings.Builder
+
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&expected, "line %d\n", i)
+
+		if i == 5 || i == 15 {
+			fmt.Fprintf(&actual, "changed %d\n", i)
+		} else {
+			fmt.Fprintf(&actual, "line %d\n", i)
+		}
+	}
+
+	d := NewWithOptions(Options{ContextLines: 2})
+	output := d.Format(d.Diff([]byte(expected.String()), []byte(actual.String())))
+
+	for _, want := range []string{" ...  2 unchanged lines", "line 3\n", "line 7\n", " ...  5 unchanged lines", "line 13\n", "line 17\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Format() output lacks %q:\n%s", want, output)
+		}
+	}
+
+	for _, unwanted := range []string{"line 2\n", "line 8\n", "line 12\n", "line 18\n"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("Format() output shows %q beyond the context:\n%s", unwanted, output)
+		}
+	}
+
+	if all := NewWithOptions(Options{}).Format(d.Diff([]byte(expected.String()), []byte(actual.String()))); !strings.Contains(all, "line 10\n") {
+		t.Errorf("Format() without ContextLines elided lines:\n%s", all)
+	}
+}
@@ -0,0 +1,238 @@
+package differ
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// opKind is the kind of a single-line edit operation.
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// lineOp is a single-line edit operation used while detecting moves.
+type lineOp struct {
+	kind  opKind
+	line  string
+	a, b  int // Line index in expected and actual, valid depending on kind
+	block int // Index of the moved block the op belongs to, or -1
+}
+
+// minMoveLines is the smallest number of lines a moved block must span.
+// Shorter matches are usually coincidental repeats, such as a common line
+// appearing in both a deleted and an inserted region.
+const minMoveLines = 2
+
+// movedBlock pairs a run of deleted lines with an identical run of inserted lines.
+type movedBlock struct {
+	startA, startB, count int
+	emitted               [2]bool // Whether the from/to chunks were emitted
+}
+
+// detectMoves rewrites diff so that runs of at least minMoveLines deleted
+// lines which reappear as inserted lines elsewhere become
+// ChunkMovedFrom/ChunkMovedTo pairs.
+func detectMoves(diff *Diff) {
+	ops := expandOps(diff.Chunks)
+	blocks := dropShortMoves(ops, pairMoves(ops))
+
+	if len(blocks) == 0 {
+		return
+	}
+
+	diff.Chunks = rebuildChunks(ops, blocks)
+}
+
+// expandOps flattens chunks into single-line operations.
+func expandOps(chunks []DiffChunk) []lineOp {
+	var ops []lineOp
+
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case ChunkEqual:
+			for i, line := range chunk.Lines {
+				ops = append(ops, lineOp{kind: opEqual, line: line, a: chunk.StartA + i, b: chunk.StartB + i, block: -1})
+			}
+		case ChunkDelete:
+			for i, line := range chunk.Lines {
+				ops = append(ops, lineOp{kind: opDelete, line: line, a: chunk.StartA + i, block: -1})
+			}
+		case ChunkInsert:
+			for i, line := range chunk.Lines {
+				ops = append(ops, lineOp{kind: opInsert, line: line, b: chunk.StartB + i, block: -1})
+			}
+		case ChunkReplace:
+			for i, line := range chunk.Lines[:chunk.CountA] {
+				ops = append(ops, lineOp{kind: opDelete, line: line, a: chunk.StartA + i, block: -1})
+			}
+
+			for i, line := range chunk.Lines[chunk.CountA:] {
+				ops = append(ops, lineOp{kind: opInsert, line: line, b: chunk.StartB + i, block: -1})
+			}
+		case ChunkMovedFrom, ChunkMovedTo:
+			// Already processed
+		}
+	}
+
+	return ops
+}
+
+// pairMoves matches inserted lines with identical deleted lines and groups
+// consecutive matches into blocks, recording the block index on each op.
+func pairMoves(ops []lineOp) []*movedBlock {
+	deleted := make(map[string][]int) // line -> indexes into ops
+
+	for i, op := range ops {
+		if op.kind == opDelete && isMovable(op.line) {
+			deleted[op.line] = append(deleted[op.line], i)
+		}
+	}
+
+	var (
+		blocks []*movedBlock
+		last   *movedBlock
+	)
+
+	for i := range ops {
+		op := &ops[i]
+		if op.kind != opInsert || len(deleted[op.line]) == 0 {
+			continue
+		}
+
+		from := deleted[op.line][0]
+		deleted[op.line] = deleted[op.line][1:]
+
+		// Extend the previous block when both sides continue it
+		if last != nil && op.b == last.startB+last.count && ops[from].a == last.startA+last.count {
+			last.count++
+		} else {
+			last = &movedBlock{startA: ops[from].a, startB: op.b, count: 1}
+			blocks = append(blocks, last)
+		}
+
+		op.block = len(blocks) - 1
+		ops[from].block = len(blocks) - 1
+	}
+
+	return blocks
+}
+
+// dropShortMoves discards blocks spanning fewer than minMoveLines lines,
+// renumbering the blocks recorded on ops.
+func dropShortMoves(ops []lineOp, blocks []*movedBlock) []*movedBlock {
+	kept := make([]*movedBlock, 0, len(blocks))
+	index := make([]int, len(blocks))
+
+	for i, block := range blocks {
+		index[i] = -1
+
+		if block.count >= minMoveLines {
+			index[i] = len(kept)
+			kept = append(kept, block)
+		}
+	}
+
+	for i := range ops {
+		if ops[i].block >= 0 {
+			ops[i].block = index[ops[i].block]
+		}
+	}
+
+	return kept
+}
+
+// isMovable reports whether line carries enough content to be tracked as moved.
+func isMovable(line string) bool {
+	return strings.IndexFunc(line, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0
+}
+
+// rebuildChunks turns ops back into chunks, emitting each moved block once
+// on each side.
+func rebuildChunks(ops []lineOp, blocks []*movedBlock) []DiffChunk {
+	var chunks []DiffChunk
+
+	for _, op := range ops {
+		if op.block >= 0 {
+			block := blocks[op.block]
+			side := 0
+			chunkType := ChunkMovedFrom
+
+			if op.kind == opInsert {
+				side = 1
+				chunkType = ChunkMovedTo
+			}
+
+			if block.emitted[side] {
+				continue
+			}
+
+			block.emitted[side] = true
+
+			chunks = append(chunks, DiffChunk{
+				Type:   chunkType,
+				Lines:  blockLines(ops, op.block, op.kind),
+				StartA: block.startA,
+				StartB: block.startB,
+				CountA: block.count,
+				CountB: block.count,
+			})
+
+			continue
+		}
+
+		switch op.kind {
+		case opEqual:
+			chunks = append(chunks, DiffChunk{Type: ChunkEqual, Lines: []string{op.line}, StartA: op.a, StartB: op.b, CountA: 1, CountB: 1})
+		case opDelete:
+			chunks = append(chunks, DiffChunk{Type: ChunkDelete, Lines: []string{op.line}, StartA: op.a, CountA: 1})
+		case opInsert:
+			chunks = append(chunks, DiffChunk{Type: ChunkInsert, Lines: []string{op.line}, StartB: op.b, CountB: 1})
+		}
+	}
+
+	return chunks
+}
+
+// blockLines returns the lines of a moved block as seen on one side.
+func blockLines(ops []lineOp, block int, kind opKind) []string {
+	var lines []string
+
+	for _, op := range ops {
+		if op.block == block && op.kind == kind {
+			lines = append(lines, op.line)
+		}
+	}
+
+	return lines
+}
+
+// formatMovedFromChunk writes a single annotation where a block was moved away from.
+func (d *Differ) formatMovedFromChunk(buf *strings.Builder, chunk DiffChunk) {
+	fmt.Fprintf(buf, "\033[35m~%4d  [moved %s to %s]\033[0m\n",
+		chunk.StartA+1, lineRange(chunk.StartA, chunk.CountA), lineRange(chunk.StartB, chunk.CountB))
+}
+
+// formatMovedToChunk writes the lines of a moved block at its new location.
+func (d *Differ) formatMovedToChunk(buf *strings.Builder, chunk DiffChunk, syntax Syntax) {
+	fmt.Fprintf(buf, "\033[35m~      [moved from %s]\033[0m\n", lineRange(chunk.StartA, chunk.CountA))
+
+	for i, line := range chunk.Lines {
+		d.writeLine(buf, "\033[35m", '~', chunk.StartB+i+1, line, syntax)
+	}
+}
+
+// lineRange formats a 0-based start and count as a 1-based line range.
+func lineRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("line %d", start+1)
+	}
+
+	return fmt.Sprintf("lines %d-%d", start+1, start+count)
+}
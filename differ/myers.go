@@ -0,0 +1,117 @@
+package differ
+
+// maxMyersDistance bounds the edit distance explored by the Myers algorithm,
+// whose trace needs memory quadratic in the distance. Inputs differing by
+// more fall back to the simple line-by-line diff.
+const maxMyersDistance = 2000
+
+// myersDiff implements the Myers O(ND) diff algorithm, producing a minimal
+// edit script as single-line chunks.
+func (d *Differ) myersDiff(expected, actual []string) *Diff {
+	a := d.comparableLines(expected)
+	b := d.comparableLines(actual)
+	n, m := len(a), len(b)
+
+	limit := min(n+m, maxMyersDistance)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace[dist] holds v for diagonals -dist..dist before step dist
+	var trace [][]int
+
+	for dist := 0; dist <= limit; dist++ {
+		trace = append(trace, append([]int(nil), v[offset-dist:offset+dist+1]...))
+
+		for k := -dist; k <= dist; k += 2 {
+			var x int
+			if k == -dist || (k != dist && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Move down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // Move right: deletion
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return &Diff{
+					Chunks: backtrackMyers(trace, expected, actual),
+					Equal:  dist == 0,
+				}
+			}
+		}
+	}
+
+	return d.simpleDiff(expected, actual)
+}
+
+// backtrackMyers walks the trace backwards to build the edit script.
+func backtrackMyers(trace [][]int, expected, actual []string) []DiffChunk {
+	var reversed []DiffChunk
+
+	x, y := len(expected), len(actual)
+
+	for dist := len(trace) - 1; dist >= 0; dist-- {
+		v := trace[dist]
+		at := func(k int) int { return v[k+dist] }
+		k := x - y
+
+		prevK := k - 1
+		if dist > 0 && (k == -dist || (k != dist && at(k-1) < at(k+1))) {
+			prevK = k + 1
+		}
+
+		prevX, prevY := 0, 0
+		if dist > 0 {
+			prevX = at(prevK)
+			prevY = prevX - prevK
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, DiffChunk{
+				Type: ChunkEqual, Lines: []string{expected[x]},
+				StartA: x, StartB: y, CountA: 1, CountB: 1,
+			})
+		}
+
+		if dist == 0 {
+			break
+		}
+
+		if x == prevX {
+			reversed = append(reversed, DiffChunk{Type: ChunkInsert, Lines: []string{actual[prevY]}, StartA: x, StartB: prevY, CountB: 1})
+		} else {
+			reversed = append(reversed, DiffChunk{Type: ChunkDelete, Lines: []string{expected[prevX]}, StartA: prevX, StartB: y, CountA: 1})
+		}
+
+		x, y = prevX, prevY
+	}
+
+	chunks := make([]DiffChunk, len(reversed))
+	for i, chunk := range reversed {
+		chunks[len(reversed)-1-i] = chunk
+	}
+
+	return chunks
+}
+
+// comparableLines returns the form of lines used for equality checks.
+func (d *Differ) comparableLines(lines []string) []string {
+	if !d.options.IgnoreWhitespace {
+		return lines
+	}
+
+	normalized := make([]string, len(lines))
+	for i, line := range lines {
+		normalized[i] = normalizeSpace(line)
+	}
+
+	return normalized
+}
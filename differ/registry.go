@@ -0,0 +1,101 @@
+package differ
+
+import (
+	"sort"
+	"sync"
+)
+
+// Algorithm computes the line diff between expected and actual.
+// Implementations only need to produce chunks; move detection, hunk
+// merging and syntax resolution are applied by the Differ afterwards.
+//
+// The chunks must cover expected and actual in order. Every chunk sets
+// CountA and CountB to the number of expected and actual lines it spans,
+// and its Lines hold the CountA expected lines followed by the CountB
+// actual lines; equal chunks hold their lines once, with CountA equal to
+// CountB. Returning nil makes the Differ use its built-in algorithm.
+type Algorithm interface {
+	Diff(expected, actual []string) *Diff
+}
+
+// AlgorithmFunc adapts an ordinary function to the Algorithm interface.
+type AlgorithmFunc func(expected, actual []string) *Diff
+
+// Diff calls f(expected, actual).
+func (f AlgorithmFunc) Diff(expected, actual []string) *Diff {
+	return f(expected, actual)
+}
+
+// builtinAlgorithm is a diff algorithm of the Differ, registered so that it
+// can be selected by name. The Differ runs it with its own options.
+type builtinAlgorithm func(d *Differ, expected, actual []string) *Diff
+
+// Diff runs the algorithm with the default options.
+func (f builtinAlgorithm) Diff(expected, actual []string) *Diff {
+	return f(New(), expected, actual)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Algorithm{
+		"simple": builtinAlgorithm((*Differ).simpleDiff),
+		"myers":  builtinAlgorithm((*Differ).myersDiff),
+		"auto":   builtinAlgorithm((*Differ).autoLines),
+	}
+)
+
+// Register makes a diff algorithm available under name, replacing any
+// algorithm previously registered with the same name.
+func Register(name string, algo Algorithm) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[name] = algo
+}
+
+// Lookup returns the algorithm registered under name.
+func Lookup(name string) (Algorithm, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	algo, ok := registry[name]
+
+	return algo, ok
+}
+
+// namedDiff diffs expected and actual with the algorithm registered under
+// name, returning nil if there is none.
+func (d *Differ) namedDiff(name string, expected, actual []string) *Diff {
+	algo, ok := Lookup(name)
+	if !ok {
+		return nil
+	}
+
+	if builtin, ok := algo.(builtinAlgorithm); ok {
+		return builtin(d, expected, actual)
+	}
+
+	diff := algo.Diff(expected, actual)
+	if diff == nil {
+		return nil
+	}
+
+	diff.Equal = chunksEqual(diff.Chunks)
+
+	return diff
+}
+
+// Registered returns the sorted names of all registered algorithms.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,289 @@
+package differ
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Syntax selects the syntax highlighting applied to diff content.
+type Syntax int
+
+const (
+	// SyntaxNone disables syntax highlighting.
+	SyntaxNone Syntax = iota
+	// SyntaxAuto detects the syntax from the diffed content.
+	SyntaxAuto
+	// SyntaxJSON highlights JSON keys, values and punctuation.
+	SyntaxJSON
+	// SyntaxYAML highlights YAML keys, values and comments.
+	SyntaxYAML
+	// SyntaxGo highlights Go keywords, literals and comments.
+	SyntaxGo
+)
+
+// tokenKind classifies a highlighted token.
+type tokenKind int
+
+const (
+	tokenPlain tokenKind = iota
+	tokenKey
+	tokenString
+	tokenNumber
+	tokenKeyword
+	tokenPunct
+	tokenComment
+)
+
+// token is a classified piece of a line.
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// yamlKeyPattern matches a YAML mapping key at the start of a line.
+var yamlKeyPattern = regexp.MustCompile(`^(\s*(?:- )?)([^\s#:][^#:]*?)(:)(\s|$)`)
+
+// goKeywords lists Go keywords and predeclared literals.
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+	"true": true, "false": true, "nil": true, "iota": true,
+}
+
+// dataKeywords lists the literals of JSON and YAML.
+var dataKeywords = map[string]bool{
+	"true": true, "false": true, "null": true, "~": true,
+}
+
+// SyntaxFromFilename returns the syntax implied by a golden file's extension.
+// Unknown extensions, and the generic .golden.go convention whose extension
+// only exists for IDE support, return SyntaxAuto.
+func SyntaxFromFilename(filename string) Syntax {
+	name := strings.TrimSuffix(filename, ".golden.go")
+	if name != filename {
+		return SyntaxAuto
+	}
+
+	name = strings.TrimSuffix(name, ".golden")
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return SyntaxJSON
+	case ".yaml", ".yml":
+		return SyntaxYAML
+	case ".go":
+		return SyntaxGo
+	default:
+		return SyntaxAuto
+	}
+}
+
+// detectSyntax guesses the syntax of content.
+func detectSyntax(content []byte) Syntax {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 {
+		return SyntaxNone
+	}
+
+	switch {
+	case (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed):
+		return SyntaxJSON
+	case bytes.HasPrefix(trimmed, []byte("package ")):
+		return SyntaxGo
+	case bytes.HasPrefix(trimmed, []byte("---")):
+		return SyntaxYAML
+	}
+
+	firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	if yamlKeyPattern.Match(firstLine) {
+		return SyntaxYAML
+	}
+
+	return SyntaxNone
+}
+
+// highlight renders line with syntax highlighting. Changed lines only use
+// text attributes so that the surrounding +/- color is preserved.
+func highlight(line string, syntax Syntax, changed bool) string {
+	if syntax == SyntaxNone || syntax == SyntaxAuto {
+		if changed {
+			return visualize(line)
+		}
+
+		return line
+	}
+
+	var buf strings.Builder
+
+	for _, tok := range tokenize(line, syntax) {
+		text := tok.text
+		if changed {
+			text = visualize(text)
+		}
+
+		start, end := tokenStyle(tok.kind, changed)
+		buf.WriteString(start)
+		buf.WriteString(text)
+		buf.WriteString(end)
+	}
+
+	return buf.String()
+}
+
+// tokenStyle returns the escape sequences surrounding a token.
+func tokenStyle(kind tokenKind, changed bool) (string, string) {
+	if changed {
+		switch kind {
+		case tokenKey, tokenKeyword:
+			return "\033[1m", "\033[22m"
+		case tokenPunct, tokenComment:
+			return "\033[2m", "\033[22m"
+		case tokenString, tokenNumber:
+			return "\033[3m", "\033[23m"
+		case tokenPlain:
+			return "", ""
+		}
+
+		return "", ""
+	}
+
+	switch kind {
+	case tokenKey:
+		return "\033[34m", "\033[39m"
+	case tokenString:
+		return "\033[33m", "\033[39m"
+	case tokenNumber:
+		return "\033[35m", "\033[39m"
+	case tokenKeyword:
+		return "\033[36m", "\033[39m"
+	case tokenPunct, tokenComment:
+		return "\033[2m", "\033[22m"
+	case tokenPlain:
+		return "", ""
+	}
+
+	return "", ""
+}
+
+// tokenize splits line into classified tokens for syntax.
+func tokenize(line string, syntax Syntax) []token {
+	var (
+		tokens []token
+		rest   = line
+	)
+
+	if syntax == SyntaxYAML {
+		if m := yamlKeyPattern.FindStringSubmatchIndex(line); m != nil {
+			tokens = append(tokens,
+				token{tokenPunct, line[m[2]:m[3]]},
+				token{tokenKey, line[m[4]:m[5]]},
+				token{tokenPunct, line[m[6]:m[7]]},
+			)
+			rest = line[m[7]:]
+		}
+	}
+
+	for rest != "" {
+		tok := nextToken(rest, syntax)
+
+		// A JSON string directly followed by a colon is an object key
+		if syntax == SyntaxJSON && tok.kind == tokenString &&
+			strings.HasPrefix(strings.TrimLeft(rest[len(tok.text):], " \t"), ":") {
+			tok.kind = tokenKey
+		}
+
+		tokens = append(tokens, tok)
+		rest = rest[len(tok.text):]
+	}
+
+	return tokens
+}
+
+// nextToken scans the token at the start of s.
+func nextToken(s string, syntax Syntax) token {
+	r, size := utf8.DecodeRuneInString(s)
+
+	switch {
+	case isComment(s, syntax):
+		return token{tokenComment, s}
+	case r == '"' || (syntax != SyntaxJSON && r == '\'') || (syntax == SyntaxGo && r == '`'):
+		return token{tokenString, s[:scanQuoted(s, r)]}
+	case unicode.IsDigit(r) || (r == '-' && len(s) > 1 && unicode.IsDigit(rune(s[1]))):
+		return token{tokenNumber, s[:scanWhile(s, size, isNumberRune)]}
+	case unicode.IsLetter(r) || r == '_' || r == '~':
+		word := s[:scanWhile(s, size, isWordRune)]
+		if (syntax == SyntaxGo && goKeywords[word]) || (syntax != SyntaxGo && dataKeywords[word]) {
+			return token{tokenKeyword, word}
+		}
+
+		return token{tokenPlain, word}
+	case strings.ContainsRune("{}[]():,;=.-*&|!<>+", r):
+		return token{tokenPunct, s[:size]}
+	}
+
+	return token{tokenPlain, s[:size]}
+}
+
+// isComment reports whether s starts a line comment in syntax.
+func isComment(s string, syntax Syntax) bool {
+	switch syntax {
+	case SyntaxGo:
+		return strings.HasPrefix(s, "//")
+	case SyntaxYAML:
+		return strings.HasPrefix(s, "#")
+	case SyntaxNone, SyntaxAuto, SyntaxJSON:
+		return false
+	}
+
+	return false
+}
+
+// scanQuoted returns the length of the quoted literal at the start of s.
+func scanQuoted(s string, quote rune) int {
+	escaped := false
+
+	for i, r := range s[1:] {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '`':
+			escaped = true
+		case r == quote:
+			return i + 2
+		}
+	}
+
+	return len(s)
+}
+
+// scanWhile returns the end of the run of runes matching fn, starting at offset.
+func scanWhile(s string, offset int, fn func(rune) bool) int {
+	for offset < len(s) {
+		r, size := utf8.DecodeRuneInString(s[offset:])
+		if !fn(r) {
+			break
+		}
+
+		offset += size
+	}
+
+	return offset
+}
+
+// isNumberRune reports whether r can continue a numeric literal.
+func isNumberRune(r rune) bool {
+	return unicode.IsDigit(r) || strings.ContainsRune(".eE+-xXabcdefABCDEF_", r)
+}
+
+// isWordRune reports whether r can continue an identifier.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
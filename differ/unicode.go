@@ -0,0 +1,142 @@
+package differ
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner = '\u200d'
+
+	// Reverse video without resetting the surrounding +/- color.
+	invisibleStart = "\033[7m"
+	invisibleEnd   = "\033[27m"
+)
+
+// invisibleNames holds short labels for commonly confused invisible characters.
+var invisibleNames = map[rune]string{
+	'\r':     "CR",
+	'\u00a0': "NBSP",
+	'\u00ad': "SHY",
+	'\u200b': "ZWSP",
+	'\u200c': "ZWNJ",
+	'\u200d': "ZWJ",
+	'\u200e': "LRM",
+	'\u200f': "RLM",
+	'\u2028': "LSEP",
+	'\u2029': "PSEP",
+	'\u202a': "LRE",
+	'\u202b': "RLE",
+	'\u202c': "PDF",
+	'\u202d': "LRO",
+	'\u202e': "RLO",
+	'\u2060': "WJ",
+	'\u2066': "LRI",
+	'\u2067': "RLI",
+	'\u2068': "FSI",
+	'\u2069': "PDI",
+	'\ufeff': "BOM",
+}
+
+// visualize makes non-printable, zero-width and bidi characters visible by
+// replacing them with highlighted labels such as <ZWSP> or <U+2063>.
+// Characters that are part of a larger grapheme cluster (e.g. the ZWJ inside
+// an emoji sequence) are left untouched.
+func visualize(line string) string {
+	if isPlainASCII(line) {
+		return line
+	}
+
+	var buf strings.Builder
+
+	for _, cluster := range graphemes(line) {
+		r, size := utf8.DecodeRuneInString(cluster)
+		if size == len(cluster) && isInvisible(r) {
+			buf.WriteString(invisibleStart)
+			buf.WriteString(invisibleLabel(r))
+			buf.WriteString(invisibleEnd)
+
+			continue
+		}
+
+		buf.WriteString(cluster)
+	}
+
+	return buf.String()
+}
+
+// isPlainASCII reports whether s only contains printable ASCII and tabs.
+func isPlainASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c != '\t' && (c < 0x20 || c > 0x7e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isInvisible reports whether r renders as nothing or as ordinary whitespace.
+func isInvisible(r rune) bool {
+	return r != '\t' && !unicode.IsPrint(r)
+}
+
+// invisibleLabel returns the display label for an invisible rune.
+func invisibleLabel(r rune) string {
+	if name, ok := invisibleNames[r]; ok {
+		return "<" + name + ">"
+	}
+
+	return fmt.Sprintf("<U+%04X>", r)
+}
+
+// graphemes splits s into approximate extended grapheme clusters: a base
+// character followed by combining marks, variation selectors and
+// ZWJ-joined pictographs.
+func graphemes(s string) []string {
+	var clusters []string
+
+	for len(s) > 0 {
+		_, size := utf8.DecodeRuneInString(s)
+		end := size
+
+		for end < len(s) {
+			next, nextSize := utf8.DecodeRuneInString(s[end:])
+			if next == zeroWidthJoiner {
+				// A ZWJ only extends the cluster when it joins two pictographs
+				following, followingSize := utf8.DecodeRuneInString(s[end+nextSize:])
+				if followingSize == 0 || !isPictographic(following) {
+					break
+				}
+
+				end += nextSize + followingSize
+
+				continue
+			}
+
+			if !isExtender(next) {
+				break
+			}
+
+			end += nextSize
+		}
+
+		clusters = append(clusters, s[:end])
+		s = s[end:]
+	}
+
+	return clusters
+}
+
+// isExtender reports whether r attaches to the preceding character.
+func isExtender(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Variation_Selector) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) // Emoji skin tone modifiers
+}
+
+// isPictographic approximates the Extended_Pictographic property.
+func isPictographic(r rune) bool {
+	return unicode.Is(unicode.So, r) || (r >= 0x1f000 && r <= 0x1faff)
+}
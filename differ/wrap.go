@@ -0,0 +1,64 @@
+package differ
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// gutterWidth is the width of the marker and line number column.
+	gutterWidth = 7
+	// minWrapWidth is the smallest content width wrapping is applied to.
+	minWrapWidth = 20
+)
+
+// writeLine writes one diff line. Lines longer than the configured width are
+// soft-wrapped; every continuation line repeats the marker and color so that
+// colors never bleed across terminal lines.
+func (d *Differ) writeLine(buf *strings.Builder, color string, marker rune, lineNum int, line string, syntax Syntax) {
+	changed := color != ""
+
+	for i, segment := range wrapLine(line, d.options.Width-gutterWidth) {
+		number := fmt.Sprintf("%4d", lineNum)
+		if i > 0 {
+			number = "   ↪"
+		}
+
+		content := highlight(segment, syntax, changed)
+
+		if changed {
+			fmt.Fprintf(buf, "%s%c%s  %s\033[0m\n", color, marker, number, content)
+		} else {
+			fmt.Fprintf(buf, "%c%s  %s\n", marker, number, content)
+		}
+	}
+}
+
+// wrapLine splits line into segments of at most width grapheme clusters.
+// A width below minWrapWidth disables wrapping.
+func wrapLine(line string, width int) []string {
+	if width < minWrapWidth || utf8.RuneCountInString(line) <= width {
+		return []string{line}
+	}
+
+	var (
+		segments []string
+		current  strings.Builder
+		count    int
+	)
+
+	for _, cluster := range graphemes(line) {
+		if count == width {
+			segments = append(segments, current.String())
+			current.Reset()
+
+			count = 0
+		}
+
+		current.WriteString(cluster)
+		count++
+	}
+
+	return append(segments, current.String())
+}
@@ -0,0 +1,224 @@
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sivchari/golden/manager"
+)
+
+// FileScrubber applies Scrubbers to the files of directory snapshots whose
+// path matches Pattern.
+type FileScrubber struct {
+	Pattern   string
+	Scrubbers []Scrubber
+}
+
+// AssertDir compares the files under root, with their relative paths and
+// contents, with the golden file name. Every file is stored as a section
+// named after its slash-separated path, see AssertSection. Files matching
+// WithDirExcludes are skipped, and WithFileScrubbers rewrites the content of
+// matching files in addition to the scrubbers of g. On mismatch, added,
+// removed and changed files are listed, with a diff of each changed file.
+func (g *Golden) AssertDir(name, root string) {
+	g.t.Helper()
+
+	filename := g.goldenFilename(name, "txt")
+
+	actual, err := g.snapshotDir(root)
+	if err != nil {
+		g.t.Fatalf("Failed to snapshot directory %s: %v", root, err)
+	}
+
+	g.assertFiles(filename, actual)
+}
+
+// assertFiles compares files, stored as sections, with the golden file
+// filename, listing added, removed and changed files on mismatch.
+func (g *Golden) assertFiles(filename string, actual []manager.Section) {
+	if g.options.Update {
+		g.checkMutable(filename)
+		g.writeGolden(filename, manager.FormatSections(actual))
+
+		return
+	}
+
+	data, err := g.readGolden(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			g.t.Fatalf("Golden file %s does not exist. Run with update mode to create it.", filename)
+		}
+
+		g.t.Fatalf("Failed to read golden file %s: %v", filename, err)
+	}
+
+	if report := g.diffDir(manager.ParseSections(data), actual); report != "" {
+		g.t.Fatalf("%s", g.formatDiffError(filename, "", report, nil))
+	}
+}
+
+// snapshotDir reads the files under root in lexical order.
+func (g *Golden) snapshotDir(root string) ([]manager.Section, error) {
+	var files []manager.Section
+
+	err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", file, err)
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if rel != "." && g.dirExcluded(rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		data, err := readDirEntry(file, entry)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, manager.Section{Name: rel, Data: g.scrubFile(rel, data)})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	return files, nil
+}
+
+// readDirEntry returns the content of a file, or the target of a symlink.
+func readDirEntry(file string, entry fs.DirEntry) ([]byte, error) {
+	if entry.Type()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read symlink %s: %w", file, err)
+		}
+
+		return []byte("<symlink to " + filepath.ToSlash(target) + ">"), nil
+	}
+
+	data, err := os.ReadFile(file) //nolint:gosec // G304: Reading the snapshotted tree is the point
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	return data, nil
+}
+
+// dirExcluded reports whether a slash-separated relative path matches a
+// pattern of WithDirExcludes, either as a whole or by its base name.
+func (g *Golden) dirExcluded(rel string) bool {
+	return slices.ContainsFunc(g.options.DirExcludes, func(pattern string) bool {
+		return matchPath(pattern, rel)
+	})
+}
+
+// scrubFile applies the file scrubbers matching rel, then those of g.
+func (g *Golden) scrubFile(rel string, data []byte) []byte {
+	for _, fileScrubber := range g.options.FileScrubbers {
+		if !matchPath(fileScrubber.Pattern, rel) {
+			continue
+		}
+
+		for _, scrubber := range fileScrubber.Scrubbers {
+			data = scrubber(data)
+		}
+	}
+
+	return g.scrub(data)
+}
+
+// matchPath matches a glob against a slash-separated path or its base name.
+func matchPath(pattern, rel string) bool {
+	if ok, _ := path.Match(pattern, rel); ok {
+		return true
+	}
+
+	ok, _ := path.Match(pattern, path.Base(rel))
+
+	return ok
+}
+
+// diffDir reports the files added to, removed from or changed in actual
+// compared with expected, or returns an empty string if they match.
+func (g *Golden) diffDir(expected, actual []manager.Section) string {
+	expectedFiles := make(map[string][]byte, len(expected))
+	for _, file := range expected {
+		expectedFiles[file.Name] = file.Data
+	}
+
+	actualFiles := make(map[string][]byte, len(actual))
+	for _, file := range actual {
+		actualFiles[file.Name] = file.Data
+	}
+
+	var summary, diffs strings.Builder
+
+	for _, name := range sortedUnion(expectedFiles, actualFiles) {
+		want, inExpected := expectedFiles[name]
+		got, inActual := actualFiles[name]
+
+		switch {
+		case !inActual:
+			fmt.Fprintf(&summary, "\033[31m- %s (removed)\033[0m\n", name)
+		case !inExpected:
+			fmt.Fprintf(&summary, "\033[32m+ %s (added)\033[0m\n", name)
+		default:
+			want, got = g.transcode(want), g.transcode(got)
+			if g.compare(want, got).Equal {
+				continue
+			}
+
+			fmt.Fprintf(&summary, "\033[33m~ %s (changed)\033[0m\n", name)
+
+			want, got = g.diffInputs(want, got)
+			fmt.Fprintf(&diffs, "\n\033[1m%s\033[0m\n%s", name, g.differ.Format(g.differ.Diff(want, got)))
+		}
+	}
+
+	if summary.Len() == 0 {
+		return ""
+	}
+
+	return summary.String() + diffs.String()
+}
+
+// sortedUnion returns the keys of a and b in order.
+func sortedUnion[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+
+	for key := range a {
+		keys = append(keys, key)
+	}
+
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
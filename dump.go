@@ -0,0 +1,242 @@
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// dumpSerializer writes values as Go-like literals with their type names,
+// pointers dereferenced and cycles marked, like go-spew or litter. Unlike
+// JSON, it can include unexported fields.
+type dumpSerializer struct {
+	unexported bool
+}
+
+func (s dumpSerializer) Marshal(v interface{}) ([]byte, error) {
+	d := &dumper{unexported: s.unexported, visiting: make(map[uintptr]bool)}
+
+	// An addressable root makes unexported time.Time fields readable
+	root := reflect.ValueOf(v)
+	if root.IsValid() {
+		addressable := reflect.New(root.Type()).Elem()
+		addressable.Set(root)
+		root = addressable
+	}
+
+	d.dump(root, 0)
+
+	return d.buf.Bytes(), nil
+}
+
+func (dumpSerializer) Extension() string { return "godump" }
+
+// dumper renders one value. visiting holds the pointers on the path from
+// the root, so that shared pointers are dumped in full and only cycles are
+// cut short.
+type dumper struct {
+	buf        bytes.Buffer
+	unexported bool
+	visiting   map[uintptr]bool
+}
+
+func (d *dumper) dump(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		d.buf.WriteString("nil")
+
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		d.dumpPointer(v, depth)
+	case reflect.Interface:
+		if v.IsNil() {
+			d.buf.WriteString("nil")
+
+			return
+		}
+
+		d.dump(v.Elem(), depth)
+	case reflect.Struct:
+		d.dumpStruct(v, depth)
+	case reflect.Map:
+		d.dumpMap(v, depth)
+	case reflect.Slice, reflect.Array:
+		d.dumpList(v, depth)
+	default:
+		d.buf.WriteString(dumpScalar(v))
+	}
+}
+
+func (d *dumper) dumpPointer(v reflect.Value, depth int) {
+	if v.IsNil() {
+		fmt.Fprintf(&d.buf, "(%s)(nil)", v.Type())
+
+		return
+	}
+
+	if d.visiting[v.Pointer()] {
+		fmt.Fprintf(&d.buf, "<cycle %s>", v.Type())
+
+		return
+	}
+
+	d.visiting[v.Pointer()] = true
+	defer delete(d.visiting, v.Pointer())
+
+	d.buf.WriteByte('&')
+	d.dump(v.Elem(), depth)
+}
+
+func (d *dumper) dumpStruct(v reflect.Value, depth int) {
+	if v.Type() == timeType && (v.CanInterface() || v.CanAddr()) {
+		fmt.Fprintf(&d.buf, "time.Time(%s)", dumpTime(v))
+
+		return
+	}
+
+	fmt.Fprintf(&d.buf, "%s{", v.Type())
+
+	written := false
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() && !d.unexported {
+			continue
+		}
+
+		d.newline(depth + 1)
+		fmt.Fprintf(&d.buf, "%s: ", field.Name)
+		d.dump(v.Field(i), depth+1)
+		d.buf.WriteByte(',')
+
+		written = true
+	}
+
+	d.close(depth, written)
+}
+
+func (d *dumper) dumpMap(v reflect.Value, depth int) {
+	if v.IsNil() {
+		fmt.Fprintf(&d.buf, "%s(nil)", v.Type())
+
+		return
+	}
+
+	if d.visiting[v.Pointer()] {
+		fmt.Fprintf(&d.buf, "<cycle %s>", v.Type())
+
+		return
+	}
+
+	d.visiting[v.Pointer()] = true
+	defer delete(d.visiting, v.Pointer())
+
+	// Entries are rendered first, so that they can be sorted by key
+	type entry struct{ key, value string }
+
+	entries := make([]entry, 0, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key := &dumper{unexported: d.unexported, visiting: d.visiting}
+		key.dump(iter.Key(), depth+1)
+
+		value := &dumper{unexported: d.unexported, visiting: d.visiting}
+		value.dump(iter.Value(), depth+1)
+
+		entries = append(entries, entry{key.buf.String(), value.buf.String()})
+	}
+
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.key, b.key) })
+
+	fmt.Fprintf(&d.buf, "%s{", v.Type())
+
+	for _, e := range entries {
+		d.newline(depth + 1)
+		fmt.Fprintf(&d.buf, "%s: %s,", e.key, e.value)
+	}
+
+	d.close(depth, len(entries) > 0)
+}
+
+func (d *dumper) dumpList(v reflect.Value, depth int) {
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		fmt.Fprintf(&d.buf, "%s(nil)", v.Type())
+
+		return
+	}
+
+	fmt.Fprintf(&d.buf, "%s{", v.Type())
+
+	for i := range v.Len() {
+		d.newline(depth + 1)
+		d.dump(v.Index(i), depth+1)
+		d.buf.WriteByte(',')
+	}
+
+	d.close(depth, v.Len() > 0)
+}
+
+// newline starts a new line indented for depth.
+func (d *dumper) newline(depth int) {
+	d.buf.WriteByte('\n')
+	d.buf.WriteString(strings.Repeat("  ", depth))
+}
+
+// close ends a composite literal, on its own line if it has elements.
+func (d *dumper) close(depth int, hasElements bool) {
+	if hasElements {
+		d.newline(depth)
+	}
+
+	d.buf.WriteByte('}')
+}
+
+// dumpScalar renders a value of a basic kind. Accessors are used instead of
+// Interface, which panics for unexported fields.
+func dumpScalar(v reflect.Value) string {
+	var literal string
+
+	switch v.Kind() {
+	case reflect.String:
+		literal = strconv.Quote(v.String())
+	case reflect.Bool:
+		literal = strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		literal = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		literal = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		literal = strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		literal = strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())
+	default:
+		// Functions, channels and unsafe pointers differ between runs
+		return fmt.Sprintf("%s(...)", v.Type())
+	}
+
+	// Named types keep their name, e.g. time.Duration(1000)
+	if v.Type().PkgPath() != "" {
+		return fmt.Sprintf("%s(%s)", v.Type(), literal)
+	}
+
+	return literal
+}
+
+// dumpTime renders a time.Time through its methods, which are reachable for
+// addressable unexported fields too.
+func dumpTime(v reflect.Value) string {
+	if !v.CanInterface() {
+		v = reflect.NewAt(v.Type(), v.Addr().UnsafePointer()).Elem()
+	}
+
+	return v.Interface().(time.Time).Format(time.RFC3339Nano) //nolint:forcetypeassert // Checked by the caller
+}
@@ -0,0 +1,112 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/hex"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// minUTF16Length is the shortest content checked for UTF-16 without BOM.
+const minUTF16Length = 4
+
+// AssertEncoded is like Assert for data in enc, e.g. charmap.Windows1252 or
+// unicode.UTF16(unicode.LittleEndian, unicode.UseBOM). The golden file keeps
+// the bytes of data, while comparison and diffs use their UTF-8 decoding.
+func (g *Golden) AssertEncoded(name string, enc encoding.Encoding, data []byte) {
+	g.t.Helper()
+
+	g.with(WithEncoding(enc)).Assert(name, data)
+}
+
+// transcode decodes data to UTF-8 for comparison and diffing. Without a
+// declared encoding, BOMs, UTF-16 and Latin-1 are detected, except for raw
+// bytes. Data that cannot be decoded is returned unchanged.
+func (g *Golden) transcode(data []byte) []byte {
+	enc := g.options.Encoding
+	if enc == nil && !g.options.RawBytes {
+		enc = detectEncoding(data)
+	}
+
+	if enc == nil {
+		return data
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return data
+	}
+
+	return decoded
+}
+
+// detectEncoding returns the encoding of data, or nil for UTF-8 and binary
+// content.
+func detectEncoding(data []byte) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	case utf8.Valid(data) && bytes.IndexByte(data, 0) < 0:
+		return nil
+	}
+
+	if endian, ok := detectUTF16(data); ok {
+		return unicode.UTF16(endian, unicode.IgnoreBOM)
+	}
+
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil
+	}
+
+	return charmap.ISO8859_1
+}
+
+// detectUTF16 detects UTF-16 without BOM of mostly ASCII text, whose every
+// other byte is NUL.
+func detectUTF16(data []byte) (unicode.Endianness, bool) {
+	if len(data) < minUTF16Length || len(data)%2 != 0 {
+		return unicode.LittleEndian, false
+	}
+
+	var even, odd int
+
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+
+	half := len(data) / 2
+
+	switch {
+	case odd*10 >= half*9 && even == 0:
+		return unicode.LittleEndian, true
+	case even*10 >= half*9 && odd == 0:
+		return unicode.BigEndian, true
+	default:
+		return unicode.LittleEndian, false
+	}
+}
+
+// isBinary reports whether data is not text, even after transcoding.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+// hexDump renders binary content for line diffs.
+func hexDump(data []byte) []byte {
+	return []byte(hex.Dump(data))
+}
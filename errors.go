@@ -0,0 +1,56 @@
+package golden
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AssertError compares the chain of err with the golden file name. Every
+// error in the chain is written on its own line with its type and message,
+// indented below the error wrapping it, so that a change in a wrapped type
+// fails even when the message stays the same. Errors joined with
+// errors.Join or wrapping several errors with fmt.Errorf list each branch.
+// Source positions and memory addresses are scrubbed, and a nil err is
+// written as "<nil>".
+func (g *Golden) AssertError(name string, err error) {
+	g.t.Helper()
+
+	data := []byte(formatErrorChain(err))
+
+	for _, scrubber := range []Scrubber{ScrubFileLines(), ScrubMemoryAddresses()} {
+		data = scrubber(data)
+	}
+
+	g.assertBytes(name, data)
+}
+
+// formatErrorChain renders err and the errors it wraps as an indented tree.
+func formatErrorChain(err error) string {
+	if err == nil {
+		return "<nil>\n"
+	}
+
+	var buf strings.Builder
+
+	writeErrorChain(&buf, err, 0)
+
+	return buf.String()
+}
+
+// writeErrorChain writes err at depth and recurses into its causes.
+func writeErrorChain(buf *strings.Builder, err error, depth int) {
+	fmt.Fprintf(buf, "%s%T: %s\n", strings.Repeat("  ", depth), err, strings.ReplaceAll(err.Error(), "\n", `\n`))
+
+	switch e := err.(type) { //nolint:errorlint // The chain is walked one level at a time
+	case interface{ Unwrap() []error }:
+		for _, cause := range e.Unwrap() {
+			if cause != nil {
+				writeErrorChain(buf, cause, depth+1)
+			}
+		}
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			writeErrorChain(buf, cause, depth+1)
+		}
+	}
+}
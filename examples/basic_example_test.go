@@ -8,7 +8,7 @@ import (
 
 // Basic usage example - works with any type!
 func TestBasicUsage(t *testing.T) {
-	g := golden.New(t, golden.WithUpdate(true))
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false))
 
 	// String output
 	g.Assert("string_output", "Hello, Golden Test World!")
@@ -22,7 +22,7 @@ func TestBasicUsage(t *testing.T) {
 
 // JSON example - automatic formatting!
 func TestJSONOutput(t *testing.T) {
-	g := golden.New(t, golden.WithUpdate(true))
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false))
 
 	// Just pass the struct/map - it's automatically formatted as JSON
 	data := map[string]interface{}{
@@ -41,7 +41,7 @@ func TestJSONOutput(t *testing.T) {
 
 // Smart array order handling - works automatically!
 func TestSmartComparison(t *testing.T) {
-	g := golden.New(t, golden.WithUpdate(true))
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false))
 	// Smart default: array order is ignored for JSON automatically!
 
 	data := map[string]interface{}{
@@ -60,7 +60,7 @@ func TestSmartComparison(t *testing.T) {
 // Most users only need the update option!
 func TestEssentialOptions(t *testing.T) {
 	// Update mode (create/update golden files)
-	g := golden.New(t, golden.WithUpdate(true))
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false))
 	g.Assert("update_example", "This creates/updates the golden file")
 }
 
@@ -69,6 +69,7 @@ func TestAdvancedOptions(t *testing.T) {
 	// Ignore specific fields that change between runs
 	g := golden.New(t,
 		golden.WithUpdate(true),
+		golden.WithReadOnly(false),
 		golden.WithIgnoreFields("session_id", "request_id", "timestamp", "created_at"),
 		golden.WithIgnoreOrder(false), // Care about array order
 	)
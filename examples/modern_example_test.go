@@ -12,7 +12,7 @@ import (
 
 // Basic usage with different data types.
 func TestBasicGoldenUsage(t *testing.T) {
-	g := golden.New(t, golden.WithUpdate(true))
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false))
 
 	// Test strings
 	g.Assert("string_test", "Hello, Golden Test!")
@@ -29,7 +29,7 @@ func TestBasicGoldenUsage(t *testing.T) {
 
 // JSON data testing.
 func TestJSONGolden(t *testing.T) {
-	g := golden.New(t, golden.WithUpdate(true))
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false))
 
 	// Test simple JSON objects
 	user := map[string]interface{}{
@@ -57,7 +57,7 @@ func TestJSONGolden(t *testing.T) {
 
 // Testing with struct types.
 func TestStructGolden(t *testing.T) {
-	g := golden.New(t, golden.WithUpdate(true))
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false))
 
 	type User struct {
 		ID    int    `json:"id"`
@@ -79,6 +79,7 @@ func TestStructGolden(t *testing.T) {
 func TestIgnoreFieldsGolden(t *testing.T) {
 	g := golden.New(t,
 		golden.WithUpdate(true),
+		golden.WithReadOnly(false),
 		golden.WithIgnoreFields("timestamp", "session_id"),
 	)
 
@@ -96,7 +97,7 @@ func TestIgnoreFieldsGolden(t *testing.T) {
 
 // Testing array order independence for JSON.
 func TestArrayOrderGolden(t *testing.T) {
-	g := golden.New(t, golden.WithUpdate(true))
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false))
 
 	// Arrays in different order should match (default behavior for JSON)
 	data1 := map[string]interface{}{
@@ -114,7 +115,7 @@ func TestArrayOrderGolden(t *testing.T) {
 
 // Simple benchmark for core functionality.
 func BenchmarkGoldenAssert(b *testing.B) {
-	g := golden.New(&testing.T{}, golden.WithUpdate(true))
+	g := golden.New(&testing.T{}, golden.WithUpdate(true), golden.WithReadOnly(false))
 
 	testData := map[string]interface{}{
 		"id":      123,
@@ -0,0 +1,51 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// goldenFilename returns the golden file of name, with the extension ext
+// if WithFileExtensions is enabled. It fails the test if another golden
+// file in use by a running test already has the same filename.
+func (g *Golden) goldenFilename(name, ext string) string {
+	filename := g.manager.GetFilename(name)
+	if g.options.FileExtensions {
+		filename = g.manager.GetFilenameExt(name, ext)
+	}
+
+	release, err := g.manager.Claim(filename, name)
+	if err != nil {
+		g.t.Fatalf("%v", err)
+	}
+
+	g.t.Cleanup(release)
+
+	g.recordTouch(name, filename)
+
+	if g.result != nil {
+		g.result.Filename = filename
+	}
+
+	return filename
+}
+
+// contentExtension returns the file extension matching serialized content:
+// "bin" for binary data, "json" for JSON objects and arrays, the extension
+// of the serializer for other structured formats and "txt" otherwise.
+func (g *Golden) contentExtension(content []byte) string {
+	trimmed := bytes.TrimSpace(content)
+
+	switch {
+	case isBinary(content):
+		return "bin"
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed):
+		return "json"
+	}
+
+	if ext := g.serializer().Extension(); ext != "json" {
+		return ext
+	}
+
+	return "txt"
+}
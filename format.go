@@ -0,0 +1,141 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/sivchari/golden/comparator"
+)
+
+// errNotTable is returned when a value without keys is written as TOML.
+var errNotTable = errors.New("a TOML document must be a table")
+
+// Format selects how structured values are serialized into golden files.
+type Format int
+
+const (
+	// JSON serializes values as indented JSON. It is the default.
+	JSON Format = iota
+	// YAML serializes values as YAML and compares golden content as YAML.
+	YAML
+	// TOML serializes values as TOML and compares golden content as TOML.
+	// Only maps and structs can be serialized, and null values are omitted.
+	TOML
+	// XML serializes values with encoding/xml, so xml struct tags apply.
+	// Attributes keep the order of their struct fields. Golden content is
+	// compared as text.
+	XML
+	// Text writes Go's default representation of values, as formatted by
+	// the %+v verb.
+	Text
+	// GoDump writes values as Go-like literals with their type names,
+	// pointers dereferenced and cycles marked. Unlike JSON, it can include
+	// unexported fields, see WithUnexportedFields.
+	GoDump
+)
+
+// AssertFormat is like Assert, but serializes actual in format regardless of
+// the format of g.
+func (g *Golden) AssertFormat(name string, format Format, actual interface{}) {
+	g.t.Helper()
+
+	g.with(WithFormat(format)).Assert(name, actual)
+}
+
+// marshalYAML serializes value as YAML. The value goes through JSON first,
+// so that json struct tags and json.Marshaler implementations apply as they
+// do for the JSON format.
+func marshalYAML(value interface{}) ([]byte, error) {
+	data, err := marshalJSON(value, jsonStyle{compact: true})
+	if err != nil {
+		return nil, err
+	}
+
+	generic, err := comparator.DecodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %w", value, err)
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(yamlValue(generic)); err != nil {
+		return nil, fmt.Errorf("failed to encode %T as YAML: %w", value, err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode %T as YAML: %w", value, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshalTOML serializes value as TOML with sorted keys. Like marshalYAML,
+// the value goes through JSON first.
+func marshalTOML(value interface{}) ([]byte, error) {
+	data, err := marshalJSON(value, jsonStyle{compact: true})
+	if err != nil {
+		return nil, err
+	}
+
+	generic, err := comparator.DecodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %w", value, err)
+	}
+
+	if _, ok := generic.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%T: %w", value, errNotTable)
+	}
+
+	var buf bytes.Buffer
+
+	enc := toml.NewEncoder(&buf)
+	enc.SetIndentTables(true)
+	enc.SetMarshalJSONNumbers(true)
+
+	if err := enc.Encode(generic); err != nil {
+		return nil, fmt.Errorf("failed to encode %T as TOML: %w", value, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// yamlNumber is a JSON number encoded as a YAML number, digit for digit.
+type yamlNumber json.Number
+
+// MarshalYAML implements yaml.Marshaler.
+func (n yamlNumber) MarshalYAML() (interface{}, error) {
+	tag := "!!int"
+	if strings.ContainsAny(string(n), ".eE") {
+		tag = "!!float"
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(n)}, nil
+}
+
+// yamlValue replaces the json.Number values of a decoded JSON value, which
+// YAML would otherwise quote as strings.
+func yamlValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, value := range val {
+			val[key] = yamlValue(value)
+		}
+	case []interface{}:
+		for i, value := range val {
+			val[i] = yamlValue(value)
+		}
+	case json.Number:
+		return yamlNumber(val)
+	}
+
+	return v
+}
@@ -0,0 +1,64 @@
+package golden
+
+import (
+	"bytes"
+	"testing"
+)
+
+// serializationRounds is how many times CheckSerialization serializes a value
+// to detect nondeterministic output.
+const serializationRounds = 5
+
+// CheckSerialization verifies that value serializes to a stable golden
+// representation: repeated serialization is byte-identical, the output
+// compares equal to itself, and reformatting the output is semantically
+// equal and idempotent. It is intended for use in fuzz tests of the types
+// snapshotted by a golden suite, catching nondeterministic serialization
+// before it flakes the suite:
+//
+//	func FuzzOrder(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, id string, qty int) {
+//			golden.CheckSerialization(t, Order{ID: id, Qty: qty})
+//		})
+//	}
+func CheckSerialization(tb testing.TB, value interface{}, opts ...Option) bool {
+	tb.Helper()
+
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	g := &Golden{t: tb, options: options, comparator: newComparator(options)}
+
+	first := g.formatValue(value)
+	for i := 1; i < serializationRounds; i++ {
+		if again := g.formatValue(value); !bytes.Equal(first, again) {
+			tb.Errorf("Serialization of %T is not deterministic:\nfirst:\n%s\nround %d:\n%s", value, first, i+1, again)
+
+			return false
+		}
+	}
+
+	if result := g.compare(first, first); !result.Equal {
+		tb.Errorf("Serialized %T does not compare equal to itself (%s):\n%s", value, result.Details, first)
+
+		return false
+	}
+
+	reformatted := g.formatValue(first)
+	if result := g.compare(first, reformatted); !result.Equal {
+		tb.Errorf("Reformatted %T does not compare equal to the original (%s):\noriginal:\n%s\nreformatted:\n%s",
+			value, result.Details, first, reformatted)
+
+		return false
+	}
+
+	if again := g.formatValue(reformatted); !bytes.Equal(reformatted, again) {
+		tb.Errorf("Formatting of %T is not idempotent:\nonce:\n%s\ntwice:\n%s", value, reformatted, again)
+
+		return false
+	}
+
+	return true
+}
@@ -3,10 +3,10 @@ package golden
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -23,6 +23,9 @@ type Golden struct {
 	manager    *manager.Manager
 	comparator *comparator.Comparator
 	differ     *differ.Differ
+
+	// result records the outcome of the assertion, for Compare
+	result *Result
 }
 
 // New creates a new Golden instance.
@@ -34,30 +37,77 @@ func New(tb testing.TB, opts ...Option) *Golden {
 		opt(options)
 	}
 
-	// Get test file and function name
-	testFile, testFunc := getTestInfo()
+	if options.err != nil {
+		tb.Fatalf("Invalid golden options: %v", options.err)
+	}
+
+	// Get test file and function name, including subtests so that they do
+	// not share golden files
+	testDir, testFile, testFunc := getTestInfo()
+	if name := tb.Name(); name != "" {
+		testFunc = name
+	}
 
-	// Use custom baseDir if provided, otherwise default to "testdata"
+	// Use custom baseDir if provided, otherwise default to "testdata" (namespaced by variant)
 	baseDir := options.BaseDir
 	if baseDir == "" {
-		baseDir = "testdata"
+		baseDir = defaultBaseDir()
+
+		// Embedded files are rooted at the package directory already
+		if options.FS == nil {
+			baseDir = anchorBaseDir(testDir, baseDir)
+		}
 	}
 
 	mgr := manager.New(baseDir, testFile, testFunc)
+	mgr.SetFollowSymlinks(options.FollowSymlinks)
+	mgr.SetSymlinkRoots(options.SymlinkRoots...)
+	mgr.SetCompression(options.Compression)
+	mgr.SetExtensions(options.FileExtensions)
+	mgr.SetPlatform(options.Platform)
+	mgr.SetMaxFileSize(options.maxFileSize)
+
+	if options.FS != nil {
+		mgr.SetFS(options.FS)
+	}
 
-	// Create comparator with smart options
-	compOpts := comparator.Options{
-		IgnoreOrder:       options.IgnoreOrder,
-		IgnoreFields:      options.IgnoreFields,
-		CustomCompareFunc: options.CustomCompare,
+	if options.Memory != nil {
+		mgr.SetMemory(options.Memory)
+	}
+
+	if options.RemoteBaseline != "" {
+		mgr.SetRemote(options.RemoteBaseline, remoteCacheDir())
+	}
+
+	if options.NamingStrategy != nil {
+		mgr.SetNamingStrategy(options.NamingStrategy)
 	}
-	comp := comparator.NewWithOptions(compOpts)
+
+	// Report what update mode changed once the test is done
+	tb.Cleanup(func() {
+		if updated, unchanged := mgr.WriteStats(); updated+unchanged > 0 {
+			tb.Logf("Golden files: %d updated, %d unchanged", updated, unchanged)
+		}
+	})
+
+	comp := newComparator(options)
 
 	// Create differ with optimized options
 	diffOpts := differ.Options{
-		ContextLines: options.contextLines,
-		Algorithm:    differ.AlgorithmSimple,
+		ContextLines:     options.contextLines,
+		Algorithm:        differ.AlgorithmSimple,
+		SortLines:        options.SortedLines,
+		Syntax:           differ.SyntaxAuto,
+		DetectMoves:      options.DetectMoves,
+		IgnoreWhitespace: options.IgnoreWhitespace,
+		AnchorKey:        options.DiffAnchorKey,
+		Width:            options.DiffWidth,
+		AlgorithmName:    options.DiffAlgorithm,
+	}
+	if _, ok := differ.Lookup(diffOpts.AlgorithmName); diffOpts.AlgorithmName != "" && !ok {
+		tb.Fatalf("Unknown diff algorithm %q (registered: %s)", diffOpts.AlgorithmName, strings.Join(differ.Registered(), ", "))
 	}
+
 	diff := differ.NewWithOptions(diffOpts)
 
 	return &Golden{
@@ -69,20 +119,152 @@ func New(tb testing.TB, opts ...Option) *Golden {
 	}
 }
 
+// newComparator creates a comparator with smart options.
+func newComparator(options *Options) *comparator.Comparator {
+	return comparator.NewWithOptions(comparator.Options{
+		IgnoreOrder:       options.IgnoreOrder,
+		IgnoreWhitespace:  options.IgnoreWhitespace,
+		CaseInsensitive:   options.CaseInsensitive,
+		Locale:            options.Locale,
+		IgnoreFields:      options.IgnoreFields,
+		SortLines:         options.SortedLines,
+		CustomCompareFunc: options.CustomCompare,
+		Placeholders:      options.Placeholders,
+		Timestamps:        options.Timestamps,
+		FieldComparers:    options.FieldComparers,
+		OrderKeys:         options.OrderKeys,
+		StrictJSON:        options.StrictJSON,
+		NullAsMissing:     options.NullAsMissing,
+		IgnoreExtraFields: options.IgnoreExtraFields,
+		FieldDecoders:     options.FieldDecoders,
+		HTML:              options.HTML,
+		StripAttributes:   options.StripAttributes,
+		IgnoreValues:      options.IgnoreValues,
+		Tokens:            options.Tokens,
+		FormatGo:          options.FormatGo,
+		YAML:              options.YAML || options.Format == YAML,
+		YAMLDocumentKeys:  options.YAMLDocumentKeys,
+		TOML:              options.Format == TOML,
+		UnorderedRecords:  options.UnorderedRecords,
+	})
+}
+
+// with returns a copy of g with opts applied on top of its options.
+func (g *Golden) with(opts ...Option) *Golden {
+	options := *g.options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.err != nil {
+		g.t.Fatalf("Invalid golden options: %v", options.err)
+	}
+
+	clone := *g
+	clone.options = &options
+	clone.comparator = newComparator(&options)
+
+	return &clone
+}
+
+// AssertRaw compares data with the golden file name byte for byte, like
+// Assert with WithRawBytes.
+func (g *Golden) AssertRaw(name string, data []byte) {
+	g.t.Helper()
+
+	g.with(WithRawBytes(true)).Assert(name, data)
+}
+
 // Assert compares any value with the golden file (main API)
 // Automatically detects the type and formats appropriately with beautiful diff output.
 func (g *Golden) Assert(name string, actual interface{}) {
+	if g.usesCmp(actual) {
+		g.assertCmp(name, actual)
+
+		return
+	}
+
 	// Convert actual value to formatted bytes
 	actualBytes := g.formatValue(actual)
 	g.assertBytes(name, actualBytes)
 }
 
+// Updating reports whether g writes golden files instead of comparing them.
+func (g *Golden) Updating() bool {
+	return g.options.Update
+}
+
+// Load returns the content of the golden file name, e.g. to replay recorded
+// output.
+func (g *Golden) Load(name string) ([]byte, error) {
+	return g.readGolden(g.manager.GetFilename(name))
+}
+
+// AssertSchema validates value against the JSON Schema stored in the golden
+// file instead of comparing exact values, which suits output whose values
+// vary but whose shape must stay stable. Update mode writes a schema inferred
+// from value, which can then be edited by hand to relax or tighten it.
+func (g *Golden) AssertSchema(name string, value interface{}) {
+	actual := g.scrub(g.formatValue(value))
+	filename := g.goldenFilename(name, "json")
+
+	if g.options.Update {
+		schema, err := comparator.InferSchema(actual)
+		if err != nil {
+			g.t.Fatalf("Failed to infer JSON schema for %s: %v", filename, err)
+		}
+
+		g.checkMutable(filename)
+		g.writeGolden(filename, schema)
+
+		return
+	}
+
+	schema, err := g.readGolden(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			g.t.Fatalf("Golden file %s does not exist. Run with update mode to create it.", filename)
+		}
+
+		g.t.Fatalf("Failed to read golden file %s: %v", filename, err)
+	}
+
+	result := g.comparator.ValidateSchema(schema, actual)
+	if result.Equal {
+		return
+	}
+
+	var buf strings.Builder
+
+	fmt.Fprintf(&buf, "Golden schema %s not satisfied: %s", filename, result.Details)
+
+	for _, difference := range result.Differences {
+		fmt.Fprintf(&buf, "\n  %s", difference)
+	}
+
+	g.t.Fatalf("%s", buf.String())
+}
+
+// GoldenMarshaler is implemented by types that control their own golden file
+// representation, e.g. to redact secrets or format money. It takes
+// precedence over every serializer.
+type GoldenMarshaler interface { //nolint:revive // Named after json.Marshaler, stutter is intended
+	MarshalGolden() ([]byte, error)
+}
+
 // formatValue converts any value to a well-formatted byte representation.
 func (g *Golden) formatValue(value interface{}) []byte {
 	switch v := value.(type) {
+	case GoldenMarshaler:
+		data, err := v.MarshalGolden()
+		if err != nil {
+			g.t.Fatalf("Failed to marshal %T for golden file: %v", v, err)
+		}
+
+		return data
 	case []byte:
 		// If it's already bytes, check if it's JSON
-		if g.isJSON(v) {
+		if !g.options.RawBytes && g.isJSON(v) {
 			return g.formatJSON(v)
 		}
 
@@ -90,7 +272,7 @@ func (g *Golden) formatValue(value interface{}) []byte {
 	case string:
 		// If it's a string, check if it's JSON
 		data := []byte(v)
-		if g.isJSON(data) {
+		if !g.options.RawBytes && g.isJSON(data) {
 			return g.formatJSON(data)
 		}
 
@@ -98,15 +280,23 @@ func (g *Golden) formatValue(value interface{}) []byte {
 	case nil:
 		return []byte("null")
 	default:
+		if g.options.PreferStringer {
+			if text, ok := stringValue(reflect.ValueOf(v)); ok {
+				return []byte(text)
+			}
+		}
+
 		// Apply field filtering for JSON-serializable data
-		filtered := g.filterIgnoredFields(v)
+		filtered := g.filterIgnoredFields(g.canonicalize(v))
 
-		// Try to marshal as JSON (works for structs, maps, slices, etc.)
-		if jsonBytes, err := json.MarshalIndent(filtered, "", "  "); err == nil {
-			return jsonBytes
+		// Try to marshal in the configured format (works for structs, maps, slices, etc.)
+		if data, err := g.serializer().Marshal(filtered); err == nil {
+			return data
 		}
 		// Fall back to Go's default string representation
-		return []byte(fmt.Sprintf("%+v", filtered))
+		data, _ := textSerializer{}.Marshal(filtered)
+
+		return data
 	}
 }
 
@@ -128,12 +318,18 @@ func (g *Golden) isJSON(data []byte) bool {
 
 // formatJSON ensures JSON is consistently formatted.
 func (g *Golden) formatJSON(jsonData []byte) []byte {
-	var parsed interface{}
-	if err := json.Unmarshal(jsonData, &parsed); err != nil {
+	// Reformatting would hide what strict validation rejects
+	if g.options.StrictJSON && comparator.ValidateStrictJSON(jsonData) != nil {
+		return jsonData
+	}
+
+	// Keep numbers as written, so that large IDs are not mangled into floats
+	parsed, err := comparator.DecodeJSON(jsonData)
+	if err != nil {
 		return jsonData // Return as-is if not valid JSON
 	}
 
-	formatted, err := json.MarshalIndent(parsed, "", "  ")
+	formatted, err := g.jsonStyle().marshal(parsed)
 	if err != nil {
 		return jsonData // Return as-is if formatting fails
 	}
@@ -147,24 +343,31 @@ func (g *Golden) filterIgnoredFields(value interface{}) interface{} {
 		return value
 	}
 
+	return g.filterFields(value, comparator.ParseFieldPaths(g.options.IgnoreFields), nil)
+}
+
+// filterFields removes the fields matching paths from value found at path.
+func (g *Golden) filterFields(value interface{}, paths []comparator.FieldPath, path []string) interface{} {
 	switch v := value.(type) {
 	case map[string]interface{}:
 		filtered := make(map[string]interface{})
 
 		for key, val := range v {
+			fieldPath := comparator.Key(path, key)
+
 			// Skip ignored fields
-			if g.shouldIgnoreField(key) {
+			if comparator.MatchAny(paths, fieldPath) {
 				continue
 			}
 
-			filtered[key] = g.filterIgnoredFields(val)
+			filtered[key] = g.filterFields(val, paths, fieldPath)
 		}
 
 		return filtered
 	case []interface{}:
 		filtered := make([]interface{}, len(v))
 		for i, val := range v {
-			filtered[i] = g.filterIgnoredFields(val)
+			filtered[i] = g.filterFields(val, paths, comparator.Index(path, i))
 		}
 
 		return filtered
@@ -173,30 +376,20 @@ func (g *Golden) filterIgnoredFields(value interface{}) interface{} {
 	}
 }
 
-// shouldIgnoreField checks if a field should be ignored.
-func (g *Golden) shouldIgnoreField(field string) bool {
-	for _, ignored := range g.options.IgnoreFields {
-		if field == ignored {
-			return true
-		}
-	}
-
-	return false
-}
-
 // assertBytes is the internal implementation.
 func (g *Golden) assertBytes(name string, actual []byte) {
-	filename := g.manager.GetFilename(name)
+	filename := g.goldenFilename(name, g.contentExtension(actual))
+	actual = g.scrub(actual)
 
 	if g.options.Update {
-		if err := g.manager.WriteFile(filename, actual); err != nil {
-			g.t.Fatalf("Failed to write golden file %s: %v", filename, err)
-		}
+		g.checkStrict(filename, actual)
+		g.checkMutable(filename)
+		g.writeGolden(filename, g.collapseVars(actual))
 
 		return
 	}
 
-	expected, err := g.manager.ReadFile(filename)
+	expected, err := g.readGolden(filename)
 	if err != nil {
 		// If file doesn't exist and we're not in update mode, suggest update mode
 		if os.IsNotExist(err) {
@@ -206,27 +399,294 @@ func (g *Golden) assertBytes(name string, actual []byte) {
 		g.t.Fatalf("Failed to read golden file %s: %v", filename, err)
 	}
 
+	g.verify(filename, "", g.expandVars(expected), actual, actual)
+}
+
+// verify compares actual with the expected content of filename, or of its
+// section if not empty, and fails the test with a diff if they differ. If
+// not nil, update is the output update mode would write to filename, offered
+// as an accept link.
+func (g *Golden) verify(filename, section string, expected, actual, update []byte) {
+	// Compare and diff text as UTF-8, whatever encoding it is stored in
+	expected, actual = g.transcode(expected), g.transcode(actual)
+
 	// Use advanced comparison
-	result := g.comparator.Compare(expected, actual)
+	result := g.compare(expected, actual)
+	g.reportIgnored(filename, result.Ignored)
+
+	if result.Err != nil {
+		g.t.Fatalf("Failed to compare golden file %s: %v", filename, result.Err)
+	}
+
 	if !result.Equal {
+		recordFailure(filename, "", actual)
+
+		expected, actual = g.diffInputs(expected, actual)
+
 		// Generate beautiful diff output
 		diff := g.differ.Diff(expected, actual)
+		if syntax := differ.SyntaxFromFilename(filename); syntax != differ.SyntaxAuto && section == "" {
+			diff.Syntax = syntax
+		}
+
+		recordMagnitude(filename, len(result.Differences), diff)
+
+		// Without line differences, the comparator's details tell what differs
 		diffOutput := g.differ.Format(diff)
+		if diffOutput == "" && result.Details != "" {
+			diffOutput = "No line differences: " + result.Details + "\n"
+		}
 
 		// Create beautiful error message with diff
-		errorMsg := g.formatDiffError(filename, diffOutput)
+		errorMsg := g.formatDiffError(filename, section, diffOutput, result.Differences)
+		if link := g.acceptLink(filename, update); link != "" {
+			errorMsg += "Accept: " + link + "\n"
+		}
+
 		g.t.Fatalf("%s", errorMsg)
 	}
 }
 
+// compare compares expected and actual with the configured comparator.
+func (g *Golden) compare(expected, actual []byte) *comparator.CompareResult {
+	if g.options.RawBytes {
+		return &comparator.CompareResult{Equal: bytes.Equal(expected, actual), Details: "Exact byte comparison"}
+	}
+
+	if g.options.Comparator != nil {
+		return g.options.Comparator.Compare(expected, actual)
+	}
+
+	return g.comparator.Compare(expected, actual)
+}
+
+// diffInputs returns what to diff for a failed comparison. HTML is diffed in
+// its normalized form, so that formatting noise does not hide the change,
+// and binary content as hex dumps.
+func (g *Golden) diffInputs(expected, actual []byte) ([]byte, []byte) {
+	if isBinary(expected) || isBinary(actual) {
+		return hexDump(expected), hexDump(actual)
+	}
+
+	if !g.options.HTML {
+		return expected, actual
+	}
+
+	expectedNorm, err := g.comparator.NormalizeHTML(expected)
+	if err != nil {
+		return expected, actual
+	}
+
+	actualNorm, err := g.comparator.NormalizeHTML(actual)
+	if err != nil {
+		return expected, actual
+	}
+
+	return expectedNorm, actualNorm
+}
+
+// checkStrict fails the test if strict JSON validation rejects actual.
+func (g *Golden) checkStrict(filename string, actual []byte) {
+	if !g.options.StrictJSON || g.options.RawBytes || !g.isJSON(actual) {
+		return
+	}
+
+	validate := comparator.ValidateStrictJSON
+	if comparator.IsNDJSON(actual) {
+		validate = comparator.ValidateStrictNDJSON
+	}
+
+	if err := validate(actual); err != nil {
+		g.t.Fatalf("Refusing to write golden file %s with invalid JSON: %v", filename, err)
+	}
+}
+
+// checkWritable fails the test if golden files are read-only.
+func (g *Golden) checkWritable(filename string) {
+	if g.options.ReadOnly {
+		g.t.Fatalf("Refusing to write golden file %s: golden files are read-only, which is the default on CI. "+
+			"Make sure update mode is not left enabled in the test, or disable with WithReadOnly(false) or GOLDEN_READ_ONLY=false.", filename)
+	}
+}
+
+// checkMutable fails the test if filename is immutable and not forced.
+func (g *Golden) checkMutable(filename string) {
+	if g.options.ForceImmutable {
+		return
+	}
+
+	immutable, err := g.manager.IsImmutable(filename)
+	if err != nil {
+		g.t.Fatalf("Failed to check golden file %s: %v", filename, err)
+	}
+
+	if immutable {
+		g.t.Fatalf("Golden file %s is immutable. Set GOLDEN_FORCE_IMMUTABLE=true to update it.", filename)
+	}
+}
+
+// writeGolden writes actual to the golden file, moving it to a blob if it
+// exceeds the blob threshold or deduplicating it if configured. Otherwise,
+// text content is prefixed with a metadata header if configured.
+func (g *Golden) writeGolden(filename string, actual []byte) {
+	g.checkWritable(filename)
+
+	if size := int64(len(actual)); g.options.maxFileSize > 0 && size > g.options.maxFileSize {
+		g.t.Fatalf("Golden file %s would be %d bytes, exceeding the limit of %d bytes. Raise it with WithMaxFileSize if this is intended.",
+			filename, size, g.options.maxFileSize)
+	}
+
+	if size := int64(len(actual)); g.options.Update && g.options.SizeBudget > 0 && size > g.options.SizeBudget {
+		g.t.Fatalf("Golden file %s would be %d bytes, exceeding the size budget of %d bytes. Shrink the output or raise WithSizeBudget.",
+			filename, size, g.options.SizeBudget)
+	}
+
+	if g.options.BlobThreshold > 0 && len(actual) > g.options.BlobThreshold {
+		if err := g.manager.WriteBlob(filename, actual); err != nil {
+			g.t.Fatalf("Failed to write blob for golden file %s: %v", filename, err)
+		}
+
+		return
+	}
+
+	if !g.options.Deduplicate {
+		if g.options.MetadataHeader && !isBinary(actual) {
+			actual = g.withHeader(filename, actual)
+		}
+
+		if err := g.manager.WriteFile(filename, actual); err != nil {
+			g.t.Fatalf("Failed to write golden file %s: %v", filename, err)
+		}
+
+		return
+	}
+
+	shared, err := g.manager.WriteShared(filename, actual)
+	if err != nil {
+		g.t.Fatalf("Failed to write shared golden file for %s: %v", filename, err)
+	}
+
+	if len(shared) > 0 {
+		g.t.Logf("Golden file %s shares content with: %s", filename, strings.Join(shared, ", "))
+	}
+}
+
+// readGolden reads the golden file, resolving shared content if configured
+// and blob references.
+func (g *Golden) readGolden(filename string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if g.options.Deduplicate {
+		data, err = g.manager.ReadShared(filename)
+	} else {
+		data, err = g.manager.ReadFile(filename)
+	}
+
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Errors are already wrapped by the manager
+	}
+
+	if _, body, ok := manager.ParseHeader(data); ok {
+		return body, nil
+	}
+
+	return g.manager.ResolveBlob(data) //nolint:wrapcheck // Errors are already wrapped by the manager
+}
+
+// CollectBlobs removes the blobs in the golden directory that no golden file
+// refers to any more and returns their hashes. Run it after updating golden
+// files written with WithBlobThreshold.
+func (g *Golden) CollectBlobs() []string {
+	g.t.Helper()
+
+	if g.options.ReadOnly {
+		g.t.Fatalf("Refusing to collect blobs: golden files are read-only. Disable with WithReadOnly(false) or GOLDEN_READ_ONLY=false.")
+	}
+
+	removed, err := g.manager.CollectBlobs()
+	if err != nil {
+		g.t.Fatalf("Failed to collect unreferenced blobs: %v", err)
+	}
+
+	return removed
+}
+
+// List returns the golden files of the test and its subtests, e.g. to find
+// stale fixtures. See manager.Manager.List.
+func (g *Golden) List() []manager.GoldenFile {
+	g.t.Helper()
+
+	files, err := g.manager.List()
+	if err != nil {
+		g.t.Fatalf("Failed to list golden files: %v", err)
+	}
+
+	return files
+}
+
+// Glob returns the golden files whose path relative to the golden directory
+// matches pattern, e.g. to assert every fixture of a directory. See
+// manager.Manager.Glob.
+func (g *Golden) Glob(pattern string) []manager.GoldenFile {
+	g.t.Helper()
+
+	files, err := g.manager.Glob(pattern)
+	if err != nil {
+		g.t.Fatalf("Failed to list golden files matching %s: %v", pattern, err)
+	}
+
+	return files
+}
+
+// reportIgnored logs the content skipped by ignore rules in verbose mode,
+// so that overly broad rules masking real regressions can be spotted.
+func (g *Golden) reportIgnored(filename string, ignored []comparator.Ignored) {
+	if len(ignored) == 0 || !testing.Verbose() {
+		return
+	}
+
+	var buf strings.Builder
+
+	fmt.Fprintf(&buf, "Golden file %s: ignored %d item(s) during comparison", filename, len(ignored))
+
+	for _, item := range ignored {
+		fmt.Fprintf(&buf, "\n  %s (rule: %s)", item.Path, item.Rule)
+	}
+
+	g.t.Logf("%s", buf.String())
+}
+
+// maxReportedDifferences limits the differences listed in a failure message.
+const maxReportedDifferences = 10
+
 // formatDiffError creates a beautiful error message with diff.
-func (g *Golden) formatDiffError(filename, diffOutput string) string {
+func (g *Golden) formatDiffError(filename, section, diffOutput string, differences []comparator.Difference) string {
 	var buf strings.Builder
 
 	// Header with colors
 	buf.WriteString("\033[1;31mGolden test failed\033[0m\n")
-	buf.WriteString(fmt.Sprintf("File: \033[1;36m%s\033[0m\n", filename))
+	displayName := filename
+	if g.options.Hyperlinks {
+		displayName = fileHyperlink(filename, filename)
+	}
+
+	buf.WriteString(fmt.Sprintf("File: \033[1;36m%s\033[0m\n", displayName))
+
+	if section != "" {
+		buf.WriteString(fmt.Sprintf("Section: \033[1;36m%s\033[0m\n", section))
+	}
+
 	buf.WriteString("\n")
+	// List where the values differ, which is hard to spot in large diffs
+	if len(differences) > 0 {
+		buf.WriteString("\033[1;33mDifferent paths:\033[0m\n")
+		buf.WriteString(comparator.FormatTable(differences, maxReportedDifferences))
+		buf.WriteString("\n")
+	}
+
 	buf.WriteString("\033[1;33mDifferences found:\033[0m\n")
 	buf.WriteString(strings.Repeat("─", 80))
 	buf.WriteString("\n")
@@ -239,11 +699,18 @@ func (g *Golden) formatDiffError(filename, diffOutput string) string {
 	buf.WriteString("\n")
 	buf.WriteString("\033[1;32mTip: Run with update mode to accept changes\033[0m\n")
 
+	recordFailure(filename, buf.String(), nil)
+
+	if g.result != nil {
+		g.result.Diff = ansiPattern.ReplaceAllString(buf.String(), "")
+	}
+
 	return buf.String()
 }
 
-// getTestInfo extracts test file and function information from runtime.
-func getTestInfo() (string, string) {
+// getTestInfo extracts the directory, file and function of the test from
+// runtime.
+func getTestInfo() (string, string, string) {
 	pc := make([]uintptr, 10)
 	n := runtime.Callers(2, pc)
 	frames := runtime.CallersFrames(pc[:n])
@@ -255,7 +722,7 @@ func getTestInfo() (string, string) {
 			funcParts := strings.Split(frame.Function, ".")
 			funcName := funcParts[len(funcParts)-1]
 
-			return file, funcName
+			return filepath.Dir(frame.File), file, funcName
 		}
 
 		if !more {
@@ -263,5 +730,5 @@ func getTestInfo() (string, string) {
 		}
 	}
 
-	return "unknown_test.go", "UnknownTest"
+	return "", "unknown_test.go", "UnknownTest"
 }
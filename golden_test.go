@@ -1,16 +1,51 @@
 package golden
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+
+	"github.com/sivchari/golden/comparator"
+	"github.com/sivchari/golden/manager"
 )
 
 func TestGoldenFileCreationAndComparison(t *testing.T) {
 	t.Parallel()
 
 	// Create golden file
-	g := New(t, WithUpdate(true))
+	g := New(t, WithUpdate(true), WithReadOnly(false))
 	testData := "test content"
 	g.Assert("test_file", testData)
 
@@ -22,7 +57,7 @@ func TestGoldenFileCreationAndComparison(t *testing.T) {
 func TestGoldenJSONFormatting(t *testing.T) {
 	t.Parallel()
 
-	g := New(t, WithUpdate(true))
+	g := New(t, WithUpdate(true), WithReadOnly(false))
 
 	// Test struct as JSON
 	type TestData struct {
@@ -42,7 +77,7 @@ func TestGoldenIgnoreFields(t *testing.T) {
 	t.Parallel()
 
 	// Create golden file with ignored fields
-	g := New(t, WithUpdate(true), WithIgnoreFields("timestamp"))
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithIgnoreFields("timestamp"))
 	original := map[string]interface{}{
 		"user":      "john",
 		"timestamp": "2024-01-01T10:00:00Z",
@@ -62,7 +97,7 @@ func TestGoldenEnvironmentVariable(t *testing.T) {
 	// Test GOLDEN_UPDATE environment variable
 	t.Setenv("GOLDEN_UPDATE", "true")
 
-	g := New(t)
+	g := New(t, WithReadOnly(false))
 	g.Assert("env_test", "test data")
 
 	// Verify file was created
@@ -79,7 +114,7 @@ func TestGoldenWithBaseDir(t *testing.T) {
 	customDir := t.TempDir()
 
 	// Create golden file in custom directory
-	g := New(t, WithUpdate(true), WithBaseDir(customDir))
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(customDir))
 	testData := "custom dir test content"
 	g.Assert("basedir_test", testData)
 
@@ -93,3 +128,2329 @@ func TestGoldenWithBaseDir(t *testing.T) {
 	g = New(t, WithUpdate(false), WithBaseDir(customDir))
 	g.Assert("basedir_test", testData)
 }
+
+func TestGoldenSortedLines(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithSortedLines())
+	g.Assert("listing", "b.txt\na.txt\nc.txt\n")
+
+	// Same lines in a different order should match
+	g = New(t, WithUpdate(false), WithBaseDir(dir), WithSortedLines())
+	g.Assert("listing", "c.txt\nb.txt\na.txt\n")
+}
+
+func TestGoldenDeduplication(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithDeduplication(true))
+	g.Assert("case_a", "same output")
+	g.Assert("case_b", "same output")
+
+	// Only one shared file should exist for identical content
+	shared, err := os.ReadDir(filepath.Join(dir, "shared"))
+	if err != nil {
+		t.Fatalf("Failed to read shared directory: %v", err)
+	}
+
+	if len(shared) != 1 {
+		t.Fatalf("Expected 1 shared golden file, got %d", len(shared))
+	}
+
+	g = New(t, WithUpdate(false), WithBaseDir(dir), WithDeduplication(true))
+	g.Assert("case_a", "same output")
+	g.Assert("case_b", "same output")
+}
+
+func TestGoldenVariant(t *testing.T) {
+	// Not parallel: the variant is package-level state
+	if err := SetVariant("integration"); err != nil {
+		t.Fatalf("SetVariant() error = %v", err)
+	}
+
+	defer func() { _ = SetVariant("") }()
+
+	g := New(t)
+
+	expected := filepath.Join("testdata", "integration", "golden_test_TestGoldenVariant_out.golden.go")
+	if got := g.manager.GetFilename("out"); got != expected {
+		t.Errorf("GetFilename() = %s, want %s", got, expected)
+	}
+
+	if err := SetVariant("../escape"); err == nil {
+		t.Error("SetVariant() accepted a path outside testdata")
+	}
+}
+
+func TestGoldenAnchorBaseDir(t *testing.T) {
+	t.Parallel()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd() error = %v", err)
+	}
+
+	// go test runs in the package directory, where testdata stays relative
+	if got := New(t).manager.GetFilename("out"); filepath.IsAbs(got) {
+		t.Errorf("GetFilename() = %s, want a relative path", got)
+	}
+
+	other := filepath.Join(t.TempDir(), "pkg")
+
+	tests := []struct {
+		testDir  string
+		expected string
+	}{
+		{cwd, "testdata"},
+		{other, filepath.Join(other, "testdata")},
+		{"github.com/sivchari/golden", "testdata"}, // -trimpath
+		{"", "testdata"},
+	}
+
+	for _, tt := range tests {
+		if got := anchorBaseDir(tt.testDir, "testdata"); got != tt.expected {
+			t.Errorf("anchorBaseDir(%q) = %s, want %s", tt.testDir, got, tt.expected)
+		}
+	}
+}
+
+func TestGoldenHyperlinks(t *testing.T) {
+	t.Parallel()
+
+	g := New(t, WithHyperlinks(true))
+	output := g.formatDiffError(filepath.Join("testdata", "out.golden.go"), "", "", nil)
+
+	abs, err := filepath.Abs(filepath.Join("testdata", "out.golden.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(output, "\033]8;;file://"+filepath.ToSlash(abs)+"\033\\") {
+		t.Errorf("failure output does not link the golden file:\n%q", output)
+	}
+}
+
+func TestGoldenAcceptLink(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("accept", "v1")
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithBaseDir(dir), WithHyperlinks(true)).Assert("accept", "v2")
+	})
+
+	failures := rec.failures()
+	if len(failures) != 1 {
+		t.Fatalf("Expected a single failure, got %v", failures)
+	}
+
+	link := regexp.MustCompile(`\x1b\]8;;(golden://accept\?[^\x1b]*)\x1b\\accept`).FindStringSubmatch(failures[0])
+	if link == nil {
+		t.Fatalf("failure output has no accept link:\n%q", failures[0])
+	}
+
+	filename, err := Accept(link[1])
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+
+	if data, err := os.ReadFile(filename); err != nil || string(data) != "v2" {
+		t.Errorf("golden file = %q, %v, want v2", data, err)
+	}
+
+	New(t, WithBaseDir(dir)).Assert("accept", "v2")
+
+	// Links only write golden files, with saved outputs
+	for _, bad := range []string{
+		"golden://accept?golden=/etc/passwd&actual=" + url.QueryEscape(filepath.Join(os.TempDir(), acceptDir, "x.actual")),
+		"golden://accept?golden=" + url.QueryEscape(filename) + "&actual=/etc/passwd",
+		"https://accept?golden=" + url.QueryEscape(filename),
+	} {
+		if _, err := Accept(bad); !errors.Is(err, ErrAcceptLink) {
+			t.Errorf("Accept(%s) error = %v, want ErrAcceptLink", bad, err)
+		}
+	}
+}
+
+// recordingTB captures fatal failures instead of stopping the test.
+type recordingTB struct {
+	testing.TB
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Logf(string, ...interface{}) {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (r *recordingTB) failures() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.messages
+}
+
+// runRecorded runs fn in its own goroutine so that Fatalf can stop it.
+func runRecorded(t *testing.T, fn func(tb testing.TB)) *recordingTB {
+	t.Helper()
+
+	rec := &recordingTB{TB: t}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		fn(rec)
+	}()
+
+	<-done
+
+	return rec
+}
+
+func TestGoldenImmutable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		g := New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+		g.Assert("contract", "v1")
+
+		if err := g.manager.SetImmutable(g.manager.GetFilename("contract"), true); err != nil {
+			tb.Fatalf("SetImmutable() error = %v", err)
+		}
+
+		// Update mode must refuse to rewrite the immutable golden
+		g.Assert("contract", "v2")
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "is immutable") {
+		t.Fatalf("expected immutability failure, got %v", failures)
+	}
+
+	rec = runRecorded(t, func(tb testing.TB) {
+		g := New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithForceImmutable(true))
+		g.Assert("contract", "v2")
+	})
+
+	if failures := rec.failures(); len(failures) != 0 {
+		t.Fatalf("forced update failed: %v", failures)
+	}
+}
+
+func FuzzCheckSerialization(f *testing.F) {
+	f.Add("name", 42, "tag")
+
+	f.Fuzz(func(t *testing.T, name string, value int, tag string) {
+		type record struct {
+			Name  string         `json:"name"`
+			Value int            `json:"value"`
+			Tags  map[string]int `json:"tags"`
+		}
+
+		CheckSerialization(t, record{Name: name, Value: value, Tags: map[string]int{tag: value, "fixed": 1}})
+	})
+}
+
+func TestGoldenFieldComparer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	// Signatures are valid when they have the expected length
+	sameLength := func(expected, actual interface{}) bool {
+		e, eok := expected.(string)
+		a, aok := actual.(string)
+
+		return eok && aok && len(e) == len(a)
+	}
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.Assert("signed", map[string]interface{}{"data": map[string]interface{}{"signature": "abcd", "body": "x"}})
+
+	g = New(t, WithUpdate(false), WithBaseDir(dir), WithFieldComparer("data.signature", sameLength))
+	g.Assert("signed", map[string]interface{}{"data": map[string]interface{}{"signature": "wxyz", "body": "x"}})
+}
+
+func TestGoldenAssertSchema(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).AssertSchema("shape", map[string]interface{}{"id": "a1", "count": 3})
+		New(tb, WithUpdate(false), WithBaseDir(dir)).AssertSchema("shape", map[string]interface{}{"id": "b2", "count": 7})
+		New(tb, WithUpdate(false), WithBaseDir(dir)).AssertSchema("shape", map[string]interface{}{"id": 1})
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "/count: required property is missing") {
+		t.Errorf("failures = %q, want a missing /count violation", failures)
+	}
+}
+
+func TestGoldenCmpOptions(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		ID        string
+		Name      string
+		UpdatedAt time.Time
+	}
+
+	dir := t.TempDir()
+	ignoreTime := cmpopts.IgnoreFields(record{}, "UpdatedAt")
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("record", record{ID: "1", Name: "a", UpdatedAt: time.Unix(0, 0).UTC()})
+		New(tb, WithUpdate(false), WithBaseDir(dir), WithCmpOptions(ignoreTime)).Assert("record", record{ID: "1", Name: "a", UpdatedAt: time.Now()})
+		New(tb, WithUpdate(false), WithBaseDir(dir), WithCmpOptions(ignoreTime)).Assert("record", record{ID: "1", Name: "b"})
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "go-cmp (-golden +actual)") {
+		t.Errorf("failures = %q, want a single go-cmp diff", failures)
+	}
+}
+
+func TestFormatJSONPreservesNumbers(t *testing.T) {
+	t.Parallel()
+
+	g := New(t)
+
+	got := string(g.formatValue(`{"id": 1234567890123456789, "price": 19.990000000000000001}`))
+	if !strings.Contains(got, "1234567890123456789") || !strings.Contains(got, "19.990000000000000001") {
+		t.Errorf("formatValue() = %s, want numbers as written", got)
+	}
+}
+
+func TestGoldenStrictJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("strict", `{"id": 1}`)
+		New(tb, WithUpdate(false), WithBaseDir(dir), WithStrictJSON(true)).Assert("strict", `{"id": 1, "id": 1}`)
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], `line 1, column 11: duplicate key "id"`) {
+		t.Errorf("failures = %q, want a duplicate key location", failures)
+	}
+}
+
+func TestGoldenScrubbers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	scrubbers := WithScrubbers(ScrubTempDir(), ScrubMemoryAddresses(), ScrubRegexp(`localhost:\d+`, "localhost:<PORT>"))
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), scrubbers)
+	g.Assert("output", fmt.Sprintf("wrote %s at 0xc000012345 via localhost:8080\n", filepath.Join(os.TempDir(), "a")))
+
+	data, err := os.ReadFile(g.manager.GetFilename("output"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	if want := "wrote " + filepath.Join("<TMPDIR>", "a") + " at <ADDR> via localhost:<PORT>\n"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+
+	g = New(t, WithUpdate(false), WithBaseDir(dir), scrubbers)
+	g.Assert("output", fmt.Sprintf("wrote %s at 0xc0000abcde via localhost:9090\n", filepath.Join(os.TempDir(), "a")))
+}
+
+func TestGoldenWithComparator(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c := comparator.New()
+	trim := func(data []byte) []byte { return bytes.TrimSpace(data) }
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.Assert("composed", "  padded output  ")
+
+	g = New(t, WithUpdate(false), WithBaseDir(dir), WithComparator(comparator.Transform(trim, comparator.Func(c.CompareText))))
+	g.Assert("composed", "padded output")
+}
+
+func TestGoldenFormatYAML(t *testing.T) {
+	t.Parallel()
+
+	type service struct {
+		Name     string   `json:"name"`
+		Replicas int      `json:"replicas"`
+		Ports    []string `json:"ports"`
+	}
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithFormat(YAML))
+	g.Assert("service", service{Name: "api", Replicas: 3, Ports: []string{"80", "443"}})
+
+	data, err := os.ReadFile(g.manager.GetFilename("service"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	if want := "name: api\nports:\n  - \"80\"\n  - \"443\"\nreplicas: 3\n"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+
+	if err := os.WriteFile(g.manager.GetFilename("service"), append([]byte("# reviewed\n"), data...), 0o600); err != nil {
+		t.Fatalf("Failed to write golden file: %v", err)
+	}
+
+	g = New(t, WithUpdate(false), WithBaseDir(dir), WithFormat(YAML))
+	g.Assert("service", service{Name: "api", Replicas: 3, Ports: []string{"80", "443"}})
+}
+
+func TestGoldenFormatTOML(t *testing.T) {
+	t.Parallel()
+
+	config := map[string]interface{}{
+		"title":  "app",
+		"server": map[string]interface{}{"port": 8080, "host": "localhost", "tls": nil},
+	}
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithFormat(TOML))
+	g.Assert("config", config)
+
+	data, err := os.ReadFile(g.manager.GetFilename("config"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	if want := "title = 'app'\n\n[server]\n  host = 'localhost'\n  port = 8080\n"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+
+	g = New(t, WithUpdate(false), WithBaseDir(dir), WithFormat(TOML))
+	g.Assert("config", map[string]interface{}{"server": map[string]interface{}{"host": "localhost", "port": 8080}, "title": "app"})
+}
+
+func TestGoldenFormatTOMLPlainString(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithFormat(TOML)).Assert("s", "hello world")
+	New(t, WithBaseDir(dir), WithFormat(TOML)).Assert("s", "hello world")
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithBaseDir(dir), WithFormat(TOML)).Assert("s", "hello there")
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "hello there") {
+		t.Errorf("failures = %q, want one with the text diff", failures)
+	}
+}
+
+func TestGoldenAssertFormatXML(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		XMLName xml.Name `xml:"item"`
+		ID      string   `xml:"id,attr"`
+		Kind    string   `xml:"kind,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.AssertFormat("item", XML, item{ID: "1", Kind: "book", Name: "Go"})
+
+	data, err := os.ReadFile(g.manager.GetFilename("item"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	if want := "<item id=\"1\" kind=\"book\">\n  <name>Go</name>\n</item>"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+
+	g = New(t, WithUpdate(false), WithBaseDir(dir), WithFormat(XML))
+	g.Assert("item", item{ID: "1", Kind: "book", Name: "Go"})
+}
+
+// upperSerializer writes the %v representation of values in upper case.
+type upperSerializer struct{}
+
+func (upperSerializer) Marshal(v interface{}) ([]byte, error) {
+	return []byte(strings.ToUpper(fmt.Sprint(v))), nil
+}
+
+func (upperSerializer) Extension() string { return "upper" }
+
+func TestGoldenWithSerializer(t *testing.T) {
+	t.Parallel()
+
+	if s, ok := LookupSerializer("yaml"); !ok || s.Extension() != "yaml" {
+		t.Errorf("LookupSerializer(yaml) = %v, %v, want the built-in YAML serializer", s, ok)
+	}
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithSerializer(upperSerializer{}))
+	g.Assert("names", []string{"a", "b"})
+
+	data, err := os.ReadFile(g.manager.GetFilename("names"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	if want := "[A B]"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+}
+
+// money is an amount of cents snapshotted as a decimal amount.
+type money int64
+
+func (m money) MarshalGolden() ([]byte, error) {
+	return []byte(fmt.Sprintf("$%d.%02d", m/100, m%100)), nil
+}
+
+func TestGoldenMarshaler(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithFormat(YAML))
+	g.Assert("price", money(1999))
+
+	data, err := os.ReadFile(g.manager.GetFilename("price"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	if want := "$19.99"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+}
+
+func TestFormatValueSortsAllMapKeys(t *testing.T) {
+	t.Parallel()
+
+	type point struct{ X, Y int }
+
+	type report struct {
+		Counts  map[point]int   `json:"counts"`
+		Flags   map[bool]string `json:"flags"`
+		Comment string          `json:"comment,omitempty"`
+	}
+
+	g := New(t)
+	value := report{
+		Counts: map[point]int{{2, 1}: 3, {1, 2}: 1, {1, 1}: 2},
+		Flags:  map[bool]string{true: "on", false: "off"},
+	}
+
+	want := `{
+  "counts": {
+    "{1 1}": 2,
+    "{1 2}": 1,
+    "{2 1}": 3
+  },
+  "flags": {
+    "false": "off",
+    "true": "on"
+  }
+}`
+
+	for range 5 {
+		if got := string(g.formatValue(value)); got != want {
+			t.Fatalf("formatValue() = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestGoldenJSONIndent(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]interface{}{"id": 1, "tags": []string{"a"}}
+
+	tests := []struct {
+		name string
+		opt  Option
+		want string
+	}{
+		{"tabs", WithJSONIndent("", "\t"), "{\n\t\"id\": 1,\n\t\"tags\": [\n\t\t\"a\"\n\t]\n}"},
+		{"four spaces", WithJSONIndent("", "    "), "{\n    \"id\": 1,\n    \"tags\": [\n        \"a\"\n    ]\n}"},
+		{"compact", WithCompactJSON(true), `{"id":1,"tags":["a"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := New(t, tt.opt)
+
+			if got := string(g.formatValue(value)); got != tt.want {
+				t.Errorf("formatValue() = %q, want %q", got, tt.want)
+			}
+
+			if got := string(g.formatValue(`{"tags": ["a"], "id": 1}`)); got != tt.want {
+				t.Errorf("formatValue() of JSON text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoldenEscapeHTML(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]string{"html": "<p>a & b</p>"}
+
+	if got, want := string(New(t).formatValue(value)), `"html": "<p>a & b</p>"`; !strings.Contains(got, want) {
+		t.Errorf("formatValue() = %s, want %s", got, want)
+	}
+
+	if got, want := string(New(t, WithEscapeHTML(true)).formatValue(value)), `"html": "\u003cp\u003ea \u0026 b\u003c/p\u003e"`; !strings.Contains(got, want) {
+		t.Errorf("formatValue() = %s, want %s", got, want)
+	}
+}
+
+// dumpNode is a linked list node with unexported state.
+type dumpNode struct {
+	Name    string
+	Next    *dumpNode
+	Labels  map[string]int
+	timeout time.Duration
+	created time.Time
+}
+
+func TestGoldenGoDump(t *testing.T) {
+	t.Parallel()
+
+	node := &dumpNode{Name: "a", Labels: map[string]int{"z": 1, "b": 2}, timeout: time.Second, created: time.Unix(0, 0).UTC()}
+	node.Next = &dumpNode{Name: "b", Next: node}
+
+	want := `&golden.dumpNode{
+  Name: "a",
+  Next: &golden.dumpNode{
+    Name: "b",
+    Next: <cycle *golden.dumpNode>,
+    Labels: map[string]int(nil),
+    timeout: time.Duration(0),
+    created: time.Time(0001-01-01T00:00:00Z),
+  },
+  Labels: map[string]int{
+    "b": 2,
+    "z": 1,
+  },
+  timeout: time.Duration(1000000000),
+  created: time.Time(1970-01-01T00:00:00Z),
+}`
+
+	if got := string(New(t, WithFormat(GoDump), WithUnexportedFields(true)).formatValue(node)); got != want {
+		t.Errorf("formatValue() = %s, want %s", got, want)
+	}
+
+	if got := string(New(t, WithFormat(GoDump)).formatValue(node)); strings.Contains(got, "timeout") {
+		t.Errorf("formatValue() = %s, want unexported fields omitted", got)
+	}
+}
+
+func TestGoldenTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	type job struct {
+		Started time.Time     `json:"started"`
+		Timeout time.Duration `json:"timeout"`
+		Retry   *time.Time    `json:"retry,omitempty"`
+	}
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	value := job{Started: time.Date(2024, 1, 2, 9, 4, 5, 123, tokyo), Timeout: 90 * time.Minute}
+
+	tests := []struct {
+		name string
+		opt  Option
+		want string
+	}{
+		{"defaults", WithTimeFormat("", nil), "{\n  \"started\": \"2024-01-02T00:04:05Z\",\n  \"timeout\": \"1h30m0s\"\n}"},
+		{"layout and zone", WithTimeFormat(time.DateTime, tokyo), "{\n  \"started\": \"2024-01-02 09:04:05\",\n  \"timeout\": \"1h30m0s\"\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := string(New(t, tt.opt).formatValue(value)); got != tt.want {
+				t.Errorf("formatValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// version renders as a semantic version.
+type version struct{ Major, Minor int }
+
+func (v version) String() string { return fmt.Sprintf("v%d.%d", v.Major, v.Minor) }
+
+func TestGoldenPreferStringer(t *testing.T) {
+	t.Parallel()
+
+	g := New(t, WithPreferStringer(true))
+
+	if got, want := string(g.formatValue(version{1, 2})), "v1.2"; got != want {
+		t.Errorf("formatValue() = %q, want %q", got, want)
+	}
+
+	release := map[string]interface{}{"version": version{1, 2}}
+	if got, want := string(g.formatValue(release)), "{\n  \"version\": \"v1.2\"\n}"; got != want {
+		t.Errorf("formatValue() = %q, want %q", got, want)
+	}
+
+	if got := string(New(t).formatValue(version{1, 2})); !strings.Contains(got, `"Major": 1`) {
+		t.Errorf("formatValue() = %q, want JSON without WithPreferStringer", got)
+	}
+}
+
+func TestGoldenAssertImage(t *testing.T) {
+	t.Parallel()
+
+	canvas := func(changes map[image.Point]uint8) *image.Gray {
+		img := image.NewGray(image.Rect(0, 0, 10, 10))
+		for i := range img.Pix {
+			img.Pix[i] = 255
+		}
+
+		for p, y := range changes {
+			img.SetGray(p.X, p.Y, color.Gray{Y: y})
+		}
+
+		return img
+	}
+
+	dir := t.TempDir()
+
+	var diffFile string
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		g := New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+		g.AssertImage("canvas", canvas(nil))
+		diffFile = strings.TrimSuffix(g.manager.GetFilename("canvas"), ".golden.go") + ".diff.png"
+
+		New(tb, WithUpdate(false), WithBaseDir(dir), WithImageTolerance(5, 0)).AssertImage("canvas", canvas(map[image.Point]uint8{{1, 1}: 252}))
+		New(tb, WithUpdate(false), WithBaseDir(dir), WithImageTolerance(0, 1)).AssertImage("canvas", canvas(map[image.Point]uint8{{1, 1}: 0}))
+		New(tb, WithUpdate(false), WithBaseDir(dir), WithImageTolerance(0, 1)).AssertImage("canvas", canvas(map[image.Point]uint8{{1, 1}: 0, {2, 2}: 0}))
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "differs in 2 of 100 pixels") {
+		t.Fatalf("failures = %q, want 2 differing pixels", failures)
+	}
+
+	data, err := os.ReadFile(diffFile)
+	if err != nil {
+		t.Fatalf("Failed to read diff image: %v", err)
+	}
+
+	diff, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode diff image: %v", err)
+	}
+
+	if r, g, b, _ := diff.At(2, 2).RGBA(); r != 0xffff || g != 0 || b != 0 {
+		t.Errorf("diff pixel = %d %d %d, want red", r, g, b)
+	}
+}
+
+func TestGoldenAssertHTTPResponse(t *testing.T) {
+	t.Parallel()
+
+	handler := func(id string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Date", time.Now().Format(http.TimeFormat))
+			w.Header().Set("X-Request-Id", id)
+			w.Header().Set("X-Debug", id)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"name": "a", "id": %q}`, id)
+		}
+	}
+
+	record := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(id)(rec, httptest.NewRequest(http.MethodPost, "/items", nil))
+
+		return rec
+	}
+
+	dir := t.TempDir()
+	headers := WithHTTPHeaders("content-type", "date", "x-request-id")
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), headers)
+	g.AssertResponseRecorder("created", record("1"))
+
+	data, err := os.ReadFile(g.manager.GetFilename("created"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	want := `{
+  "body": {
+    "id": "1",
+    "name": "a"
+  },
+  "headers": {
+    "Content-Type": "application/json",
+    "Date": "<SCRUBBED>",
+    "X-Request-Id": "<SCRUBBED>"
+  },
+  "status": 201
+}`
+	if string(data) != want {
+		t.Errorf("golden file = %s, want %s", data, want)
+	}
+
+	resp := record("2").Result()
+
+	g = New(t, WithUpdate(false), WithBaseDir(dir), headers, WithIgnoreFields("body.id"))
+	g.AssertHTTPResponse("created", resp)
+
+	if body, _ := io.ReadAll(resp.Body); !strings.Contains(string(body), `"id": "2"`) {
+		t.Errorf("response body = %s, want it readable after the assertion", body)
+	}
+}
+
+func TestFormatSQL(t *testing.T) {
+	t.Parallel()
+
+	query := `select id, name  from users -- active only
+	where status = 'on  hold' and "Group" = $1
+	  and created_at::date > ? limit :limit`
+	args := []any{"admin's", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), sql.Named("limit", 10)}
+
+	tests := []struct {
+		name string
+		mode SQLParams
+		want string
+	}{
+		{"list", SQLParamsList, "SELECT id, name FROM users -- active only\nWHERE status = 'on  hold' AND \"Group\" = $1 AND created_at::date > ? LIMIT :limit\n-- $1 = 'admin''s'\n-- $2 = '2024-01-02T00:00:00Z'\n-- :limit = 10"},
+		{"inline", SQLParamsInline, "SELECT id, name FROM users -- active only\nWHERE status = 'on  hold' AND \"Group\" = 'admin''s' AND created_at::date > '2024-01-02T00:00:00Z' LIMIT 10"},
+		{"label", SQLParamsLabel, "SELECT id, name FROM users -- active only\nWHERE status = 'on  hold' AND \"Group\" = $1 /* 'admin''s' */ AND created_at::date > ? /* '2024-01-02T00:00:00Z' */ LIMIT :limit /* 10 */"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := formatSQL(query, args, tt.mode); got != tt.want {
+				t.Errorf("formatSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoldenAssertRaw(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	line := []byte("[1,  2]\n")
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		g := New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+		g.AssertRaw("log", line)
+
+		if data, err := os.ReadFile(g.manager.GetFilename("log")); err != nil || !bytes.Equal(data, line) {
+			tb.Errorf("golden file = %q, %v, want %q", data, err, line)
+		}
+
+		New(tb, WithUpdate(false), WithBaseDir(dir)).AssertRaw("log", line)
+		New(tb, WithUpdate(false), WithBaseDir(dir), WithRawBytes(true)).Assert("log", "[1, 2]\n")
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "log") {
+		t.Errorf("failures = %q, want the respaced JSON to differ", failures)
+	}
+}
+
+func TestGoldenEncodings(t *testing.T) {
+	t.Parallel()
+
+	utf16le := func(s string) []byte {
+		data, err := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte(s))
+		if err != nil {
+			t.Fatalf("Failed to encode %q: %v", s, err)
+		}
+
+		return data
+	}
+
+	tests := []struct {
+		name string
+		run  func(tb testing.TB, dir string)
+		want string
+	}{
+		{"utf16", func(tb testing.TB, dir string) {
+			New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).AssertRaw("text", utf16le("name: héllo\n"))
+			New(tb, WithUpdate(false), WithBaseDir(dir)).Assert("text", utf16le("name: world\n"))
+		}, "héllo"},
+		{"latin1", func(tb testing.TB, dir string) {
+			New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("text", []byte("caf\xe9\n"))
+			New(tb, WithUpdate(false), WithBaseDir(dir)).AssertEncoded("text", charmap.ISO8859_1, []byte("caf\xe8\n"))
+		}, "café"},
+		{"binary", func(tb testing.TB, dir string) {
+			New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("data", []byte{0x00, 0x01, 0xff})
+			New(tb, WithUpdate(false), WithBaseDir(dir)).Assert("data", []byte{0x00, 0x02, 0xff})
+		}, "00000000  00 01 ff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			rec := runRecorded(t, func(tb testing.TB) { tt.run(tb, dir) })
+
+			if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], tt.want) {
+				t.Errorf("failures = %q, want a diff containing %q", failures, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoldenAssertSection(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		g := New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+		g.AssertSection("exchange", "request", "GET /users/1")
+		g.AssertSection("exchange", "response", map[string]interface{}{"id": 1, "name": "a"})
+
+		g = New(tb, WithUpdate(false), WithBaseDir(dir))
+		g.AssertSection("exchange", "request", "GET /users/1")
+		g.AssertSection("exchange", "response", map[string]interface{}{"id": 1, "name": "b"})
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "Section: \x1b[1;36mresponse") {
+		t.Errorf("failures = %q, want a diff scoped to the response section", failures)
+	}
+}
+
+func TestGoldenAssertDir(t *testing.T) {
+	t.Parallel()
+
+	write := func(tb testing.TB, root string, files map[string]string) {
+		for name, content := range files {
+			file := filepath.Join(root, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+				tb.Fatalf("Failed to create directory: %v", err)
+			}
+
+			if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+				tb.Fatalf("Failed to write %s: %v", name, err)
+			}
+		}
+	}
+
+	dir := t.TempDir()
+	opts := []Option{
+		WithBaseDir(dir),
+		WithDirExcludes("*.log"),
+		WithFileScrubbers("*.go", ScrubRegexp(`generated at \d+`, "generated at <TIME>")),
+	}
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		before := tb.TempDir()
+		write(tb, before, map[string]string{"main.go": "// generated at 1\npackage main\n", "api/types.go": "package api\n", "old.txt": "x", "build.log": "1"})
+		New(tb, append(opts, WithUpdate(true), WithReadOnly(false))...).AssertDir("tree", before)
+
+		after := tb.TempDir()
+		write(tb, after, map[string]string{"main.go": "// generated at 2\npackage main\n", "api/types.go": "package api2\n", "new.txt": "y", "build.log": "2"})
+		New(tb, append(opts, WithUpdate(false))...).AssertDir("tree", after)
+	})
+
+	failures := rec.failures()
+	if len(failures) != 1 {
+		t.Fatalf("failures = %q, want 1", failures)
+	}
+
+	for _, want := range []string{"~ api/types.go (changed)", "+ new.txt (added)", "- old.txt (removed)", "api2"} {
+		if !strings.Contains(failures[0], want) {
+			t.Errorf("failure = %q, want %q", failures[0], want)
+		}
+	}
+
+	if strings.Contains(failures[0], "main.go") || strings.Contains(failures[0], "build.log") {
+		t.Errorf("failure = %q, want scrubbed and excluded files unchanged", failures[0])
+	}
+}
+
+func TestGoldenAssertArchive(t *testing.T) {
+	t.Parallel()
+
+	buildZip := func(tb testing.TB, file string, modified time.Time, config string) {
+		var buf bytes.Buffer
+
+		zw := zip.NewWriter(&buf)
+
+		for _, entry := range []struct {
+			name, content string
+			mode          os.FileMode
+		}{{"bin/tool", "#!/bin/sh\n", 0o700}, {"config.json", config, 0o640}} {
+			header := &zip.FileHeader{Name: entry.name, Modified: modified}
+			header.SetMode(entry.mode)
+
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				tb.Fatalf("Failed to create %s: %v", entry.name, err)
+			}
+
+			_, _ = io.WriteString(w, entry.content)
+		}
+
+		if err := zw.Close(); err != nil {
+			tb.Fatalf("Failed to close zip: %v", err)
+		}
+
+		if err := os.WriteFile(file, buf.Bytes(), 0o600); err != nil {
+			tb.Fatalf("Failed to write zip: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "dist.zip")
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		g := New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithArchiveContents("*.json"))
+		buildZip(tb, archive, time.Unix(0, 0), `{"debug": false}`)
+		g.AssertArchive("dist", archive)
+
+		data, err := os.ReadFile(g.manager.GetFilename("dist"))
+		if want := "-- <entries> --\n-rwxr-xr-x         10 bin/tool\n-rw-r--r--         16 config.json\n\n-- config.json --\n{\"debug\": false}\n"; err != nil || string(data) != want {
+			tb.Errorf("golden file = %q, %v, want %q", data, err, want)
+		}
+
+		g = New(tb, WithUpdate(false), WithBaseDir(dir), WithArchiveContents("*.json"))
+		buildZip(tb, archive, time.Now(), `{"debug": false}`)
+		g.AssertArchive("dist", archive)
+
+		buildZip(tb, archive, time.Now(), `{"debug": true}`)
+		g.AssertArchive("dist", archive)
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "~ config.json (changed)") {
+		t.Errorf("failures = %q, want a changed config.json", failures)
+	}
+}
+
+func TestGoldenBlobThreshold(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	large := strings.Repeat("snapshot ", 16)
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithBlobThreshold(64))
+	g.Assert("small", "tiny")
+	g.Assert("large", large)
+
+	ref, err := os.ReadFile(g.manager.GetFilename("large"))
+	if err != nil {
+		t.Fatalf("Failed to read reference file: %v", err)
+	}
+
+	if !strings.HasPrefix(string(ref), "golden-blob sha256:") {
+		t.Errorf("Expected a blob reference, got %q", ref)
+	}
+
+	// Blobs are resolved on read even without the threshold option
+	New(t, WithBaseDir(dir)).Assert("large", large)
+
+	// Replacing the content leaves the old blob unreferenced
+	g.Assert("large", large+"v2")
+
+	if removed := g.CollectBlobs(); len(removed) != 1 {
+		t.Errorf("Expected 1 collected blob, got %v", removed)
+	}
+
+	if removed := g.CollectBlobs(); len(removed) != 0 {
+		t.Errorf("Expected no collected blobs, got %v", removed)
+	}
+
+	New(t, WithBaseDir(dir)).Assert("large", large+"v2")
+}
+
+func TestGoldenAssertTable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		g := New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+		g.AssertTable("scores", [][]string{
+			{"id", "name", "score", "updated"},
+			{"007", "alice", "1.50", "10:00"},
+			{"2", "bob|jr", "2e1", "10:01"},
+		})
+
+		data, err := os.ReadFile(g.manager.GetFilename("scores"))
+		want := "| id  | name    | score | updated |\n" +
+			"|-----|---------|-------|---------|\n" +
+			"| 007 | alice   | 1.5   | 10:00   |\n" +
+			"| 2   | bob\\|jr | 20    | 10:01   |\n"
+
+		if err != nil || string(data) != want {
+			tb.Errorf("golden file = %q, %v, want %q", data, err, want)
+		}
+
+		g = New(tb, WithBaseDir(dir), WithTableIgnoreColumns("updated"), WithUnorderedRows(true))
+		g.AssertTable("scores", [][]string{
+			{"id", "name", "score", "updated"},
+			{"2", "bob|jr", "20.0", "11:01"},
+			{"007", "alice", "1.5", "11:00"},
+		})
+
+		g.AssertTable("scores", [][]string{
+			{"id", "name", "score", "updated"},
+			{"007", "alice", "1.75", "10:00"},
+			{"2", "bob|jr", "20", "10:01"},
+		})
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "1.75") {
+		t.Errorf("failures = %q, want a changed score", failures)
+	}
+}
+
+func TestGoldenAssertTableXLSX(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	workbook := filepath.Join(dir, "report.xlsx")
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	for name, content := range map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Summary" r:id="rId1"/><sheet name="Data" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Target="worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>item</t></si><si><t>price</t></si><si><r><t>tea</t></r><r><t>pot</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row><c r="A1" t="inlineStr"><is><t>total</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>12.500000</v></c></row>` +
+			`</sheetData></worksheet>`,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+
+		_, _ = io.WriteString(w, content)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close workbook: %v", err)
+	}
+
+	if err := os.WriteFile(workbook, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.AssertTable("data", XLSXSheet{Path: workbook, Sheet: "Data"})
+
+	data, err := os.ReadFile(g.manager.GetFilename("data"))
+	if want := "| item   |  | price |\n|--------|--|-------|\n| teapot |  | 12.5  |\n"; err != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, err, want)
+	}
+}
+
+func TestGoldenAssertLogs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	logs := CaptureLogs(t)
+	logs.Logger().Info("request served", "path", "/users", "elapsed", 1500*time.Millisecond)
+	logs.StdLogger().Printf("goroutine 42 finished after 3m2.5s")
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.AssertLogs("served", logs)
+
+	data, err := os.ReadFile(g.manager.GetFilename("served"))
+	want := "time=<TIMESTAMP> level=INFO msg=\"request served\" path=/users elapsed=<DURATION>\n" +
+		"<TIMESTAMP> goroutine <ID> finished after <DURATION>\n"
+
+	if err != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, err, want)
+	}
+
+	logs.Reset()
+	logs.Logger().Info("request served", "path", "/users", "elapsed", time.Second)
+	logs.StdLogger().Printf("goroutine 7 finished after 10ms")
+
+	New(t, WithBaseDir(dir)).AssertLogs("served", logs)
+}
+
+func TestGoldenAssertTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tmpl := texttemplate.Must(texttemplate.New("list").Parse("\n<ul>  \n{{range .}}\n  <li>{{.}}</li>\t\n\n{{end}}\n</ul>\n\n"))
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.AssertTemplate("list", tmpl, []string{"a", "b"})
+
+	data, err := os.ReadFile(g.manager.GetFilename("list"))
+	if want := "<ul>\n\n  <li>a</li>\n\n  <li>b</li>\n\n</ul>\n"; err != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, err, want)
+	}
+
+	// Whitespace-only template changes keep matching
+	refactored := htmltemplate.Must(htmltemplate.New("list").Parse("<ul>\n{{- range .}}\n\n  <li>{{.}}</li>\n{{- end}}\n\n</ul>"))
+	New(t, WithBaseDir(dir)).AssertTemplate("list", refactored, []string{"a", "b"})
+
+	g = New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithWhitespacePolicy(KeepWhitespace))
+	g.AssertTemplate("raw", tmpl, []string{"a"})
+
+	data, err = os.ReadFile(g.manager.GetFilename("raw"))
+	if want := "\n<ul>  \n\n  <li>a</li>\t\n\n\n</ul>\n\n"; err != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, err, want)
+	}
+}
+
+// notFoundError is a custom error type for AssertError tests.
+type notFoundError struct{ key string }
+
+func (e *notFoundError) Error() string { return "not found: " + e.key }
+
+func TestGoldenAssertError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cause := &notFoundError{key: "user"}
+	err := fmt.Errorf("load handler.go:42: %w", errors.Join(cause, io.EOF))
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.AssertError("chain", err)
+	g.AssertError("nil", nil)
+
+	data, readErr := os.ReadFile(g.manager.GetFilename("chain"))
+	want := "*fmt.wrapError: load <FILE:LINE>: not found: user\\nEOF\n" +
+		"  *errors.joinError: not found: user\\nEOF\n" +
+		"    *golden.notFoundError: not found: user\n" +
+		"    *errors.errorString: EOF\n"
+
+	if readErr != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, readErr, want)
+	}
+
+	// The same message with a different wrapped type fails
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithBaseDir(dir)).AssertError("chain", fmt.Errorf("load handler.go:42: %w", errors.New("not found: user\nEOF")))
+	})
+
+	if failures := rec.failures(); len(failures) != 1 {
+		t.Errorf("failures = %q, want a changed error chain", failures)
+	}
+
+	New(t, WithBaseDir(dir)).AssertError("nil", nil)
+}
+
+func TestGoldenVars(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "project")
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithVars(map[string]string{"ROOT": root, "HOST": "build-7"}))
+	g.Assert("paths", "config: "+root+"/config.yaml on build-7, cost ${PRICE}")
+
+	data, err := os.ReadFile(g.manager.GetFilename("paths"))
+	if want := "config: ${ROOT}/config.yaml on ${HOST}, cost ${PRICE}"; err != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, err, want)
+	}
+
+	other := "/home/ci/project"
+	g = New(t, WithBaseDir(dir), WithVars(map[string]string{"ROOT": other, "HOST": "runner"}))
+	g.Assert("paths", "config: "+other+"/config.yaml on runner, cost ${PRICE}")
+
+	g = New(t, WithBaseDir(dir), WithEnvVars("PROJECT_ROOT"))
+	if _, err := os.Stat(filepath.Join(g.options.Vars["PROJECT_ROOT"], "go.mod")); err != nil {
+		t.Errorf("PROJECT_ROOT = %q, want the module root: %v", g.options.Vars["PROJECT_ROOT"], err)
+	}
+}
+
+func TestGoldenAssertProfile(t *testing.T) {
+	t.Parallel()
+
+	dump := func(id int, wait string, arg string) []byte {
+		return []byte(fmt.Sprintf("goroutine %d [chan receive%s]:\n"+
+			"main.worker(%s)\n"+
+			"\t/home/dev/app/worker.go:%d +0x45\n"+
+			"created by main.start in goroutine 1\n"+
+			"\t/home/dev/app/main.go:12 +0x1d\n\n"+
+			"goroutine %d [chan receive]:\n"+
+			"main.worker(0xc000010000)\n"+
+			"\t/home/dev/app/worker.go:20 +0x45\n"+
+			"created by main.start in goroutine 1\n"+
+			"\t/home/dev/app/main.go:12 +0x1d\n", id, wait, arg, 20+id, id+1))
+	}
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.AssertProfile("workers", dump(7, ", 2 minutes", "0xc000012345"))
+
+	data, err := os.ReadFile(g.manager.GetFilename("workers"))
+	want := "goroutine <ID> [chan receive]:\nmain.worker(...)\n\tworker.go\ncreated by main.start in goroutine <ID>\n\tmain.go\n"
+
+	if err != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, err, want)
+	}
+
+	New(t, WithBaseDir(dir)).AssertProfile("workers", dump(42, "", "0xc000098765"))
+
+	heap := "heap profile: 3: 1024 [5: 2048] @ heap/1048576\n" +
+		"1: 1024 [2: 2048] @ 0x43a5b6 0x4056ac\n" +
+		"#\t0x4f2f25\tmain.alloc+0xa5\t/home/dev/app/alloc.go:796\n\n" +
+		"# runtime.MemStats\n# Alloc = 12345\n"
+
+	g.AssertProfile("heap", []byte(heap))
+
+	data, err = os.ReadFile(g.manager.GetFilename("heap"))
+	if want := "heap profile: <N>: <N> [<N>: <N>] @ heap/1048576\n\n<N>: <N> [<N>: <N>] @\n#\tmain.alloc\talloc.go\n"; err != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, err, want)
+	}
+}
+
+func TestGoldenAssertOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	const spec = `openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      summary: List users
+      parameters:
+        - {name: limit, in: query}
+        - {name: cursor, in: query}
+      responses:
+        200:
+          description: OK
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id: {type: integer}
+        status: {type: string, enum: [active, banned]}
+`
+
+	// Documentation churn and reordering are ignored
+	reordered := strings.NewReplacer("version: 1.0.0", "version: 1.1.0", "List users", "Lists users",
+		"- {name: limit, in: query}\n        - {name: cursor, in: query}", "- {name: cursor, in: query}\n        - {name: limit, in: query}").Replace(spec)
+
+	changed := strings.NewReplacer("{name: cursor, in: query}", "{name: cursor, in: query, required: true}",
+		"id: {type: integer}", "id: {type: string}", "enum: [active, banned]", "enum: [active, suspended]",
+		"components:", "  /health:\n    get: {responses: {204: {description: OK}}}\ncomponents:").Replace(spec)
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).AssertOpenAPI("users", []byte(spec))
+		New(tb, WithBaseDir(dir), WithOpenAPIIgnoreDocs(true)).AssertOpenAPI("users", []byte(reordered))
+		New(tb, WithBaseDir(dir)).AssertOpenAPI("users", []byte(changed))
+	})
+
+	failures := rec.failures()
+	if len(failures) != 1 {
+		t.Fatalf("failures = %q, want one", failures)
+	}
+
+	breaking, compatible, _ := strings.Cut(failures[0], "Non-breaking changes:")
+	for _, want := range []string{
+		`- components.schemas.User.properties.status.enum.banned: removed`,
+		`~ components.schemas.User.properties.id.type: integer -> string`,
+		`+ paths["/users"].get.parameters["query:cursor"].required: added`,
+	} {
+		if !strings.Contains(breaking, want) {
+			t.Errorf("breaking changes = %q, want %q", breaking, want)
+		}
+	}
+
+	for _, want := range []string{
+		`+ components.schemas.User.properties.status.enum.suspended: added`,
+		`+ paths["/health"]: added`,
+	} {
+		if !strings.Contains(compatible, want) {
+			t.Errorf("non-breaking changes = %q, want %q", compatible, want)
+		}
+	}
+}
+
+// fixtureNaming names golden files "<test>/<name>.json".
+type fixtureNaming struct{}
+
+func (fixtureNaming) GenerateFilename(_, testFunc, goldenName string) string {
+	return filepath.Join(testFunc, goldenName+".json")
+}
+
+func (fixtureNaming) ParseFilename(filename string) (string, string, string, error) {
+	dir, file := filepath.Split(filename)
+
+	return "", filepath.Base(dir), strings.TrimSuffix(file, ".json"), nil
+}
+
+func TestGoldenWithNamingStrategy(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithNamingStrategy(fixtureNaming{})).Assert("user", map[string]string{"name": "alice"})
+
+	if _, err := os.Stat(filepath.Join(dir, "TestGoldenWithNamingStrategy", "user.json")); err != nil {
+		t.Errorf("Expected golden file named by the strategy: %v", err)
+	}
+
+	New(t, WithBaseDir(dir), WithNamingStrategy(fixtureNaming{})).Assert("user", map[string]string{"name": "alice"})
+}
+
+func TestGoldenSubtestNames(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, tc := range []struct{ name, output string }{{"first case", "one"}, {"second case", "two"}} {
+		t.Run(tc.name, func(t *testing.T) {
+			New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("output", tc.output)
+			New(t, WithBaseDir(dir)).Assert("output", tc.output)
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "golden_test_TestGoldenSubtestNames__first_case_output.golden.go")); err != nil {
+		t.Errorf("Expected a golden file per subtest: %v", err)
+	}
+}
+
+func TestGoldenFileExtensions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	// A golden file written before extensions were enabled keeps being used
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("legacy", "kept")
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithFileExtensions(true))
+	g.Assert("legacy", "kept")
+	g.Assert("user", map[string]string{"name": "alice"})
+	g.Assert("message", "hello")
+	g.AssertRaw("blob", []byte{0x00, 0x01, 0xff})
+	g.AssertFormat("config", YAML, map[string]int{"port": 8080})
+
+	for _, file := range []string{"legacy.golden.go", "user.golden.json", "message.golden.txt", "blob.golden.bin", "config.golden.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, "golden_test_TestGoldenFileExtensions_"+file)); err != nil {
+			t.Errorf("Expected golden file %s: %v", file, err)
+		}
+	}
+
+	g = New(t, WithBaseDir(dir), WithFileExtensions(true))
+	g.Assert("user", map[string]string{"name": "alice"})
+	g.AssertFormat("config", YAML, map[string]int{"port": 8080})
+
+	if data, err := g.Load("message"); err != nil || string(data) != "hello" {
+		t.Errorf("Load() = %q, %v, want hello", data, err)
+	}
+}
+
+func TestGoldenFS(t *testing.T) {
+	t.Parallel()
+
+	// Golden files are looked up in the file system, not on the local disk
+	fsys := fstest.MapFS{}
+	g := New(t, WithBaseDir("embedded"), WithFS(fsys))
+	fsys[filepath.ToSlash(g.manager.GetFilename("greeting"))] = &fstest.MapFile{Data: []byte("hello")}
+
+	g.AssertRaw("greeting", []byte("hello"))
+
+	if data, err := g.Load("greeting"); err != nil || string(data) != "hello" {
+		t.Errorf("Load() = %q, %v, want hello", data, err)
+	}
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithBaseDir("embedded"), WithFS(fsys)).AssertRaw("greeting", []byte("goodbye"))
+	})
+
+	if len(rec.failures()) == 0 {
+		t.Error("Expected a mismatch against the embedded golden file")
+	}
+
+	if _, err := os.Stat("embedded"); !os.IsNotExist(err) {
+		t.Errorf("Expected no golden files on disk, got %v", err)
+	}
+}
+
+func TestGoldenMaxFileSize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithMaxFileSize(8)).AssertRaw("huge", bytes.Repeat([]byte("x"), 16))
+	})
+
+	if failures := rec.failures(); len(failures) == 0 || !strings.Contains(failures[0], "WithMaxFileSize") {
+		t.Errorf("Expected a size limit failure, got %v", failures)
+	}
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithMaxFileSize(8))
+	if _, err := os.Stat(g.manager.GetFilename("huge")); !os.IsNotExist(err) {
+		t.Errorf("Expected no golden file to be written, got %v", err)
+	}
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithMaxFileSize(32)).AssertRaw("huge", bytes.Repeat([]byte("x"), 16))
+}
+
+func TestGoldenCheck(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	for _, name := range []string{"first", "second", "third"} {
+		g.Assert(name, name)
+	}
+
+	var matched []bool
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		g := New(tb, WithBaseDir(dir))
+
+		// Every case is checked, despite the earlier mismatches
+		matched = append(matched, g.Check("first", "changed"), g.Check("second", "second"), g.Check("third", "changed"))
+	})
+
+	if want := []bool{false, true, false}; fmt.Sprint(matched) != fmt.Sprint(want) {
+		t.Errorf("Check() = %v, want %v", matched, want)
+	}
+
+	failures := rec.failures()
+	if len(failures) != 2 || !strings.Contains(failures[0], "_first.golden") || !strings.Contains(failures[1], "_third.golden") {
+		t.Errorf("Expected a failure per mismatch, got %v", failures)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	result, err := Compare("report", "v1", WithBaseDir(dir), WithUpdate(true), WithReadOnly(false))
+	if err != nil || !result.Match || !result.Updated {
+		t.Fatalf("Compare() in update mode = %+v, %v, want a written golden file", result, err)
+	}
+
+	if want := filepath.Join(dir, "report.golden.go"); result.Filename != want {
+		t.Errorf("Filename = %s, want %s", result.Filename, want)
+	}
+
+	result, err = Compare("report", "v1", WithBaseDir(dir), WithUpdate(true), WithReadOnly(false))
+	if err != nil || result.Updated {
+		t.Errorf("Compare() with unchanged output = %+v, %v, want no update", result, err)
+	}
+
+	result, err = Compare("report", "v1", WithBaseDir(dir), WithUpdate(false))
+	if err != nil || !result.Match || result.Diff != "" {
+		t.Errorf("Compare() with matching output = %+v, %v, want a match", result, err)
+	}
+
+	result, err = Compare("report", "v2", WithBaseDir(dir), WithUpdate(false))
+	if err != nil || result.Match || !strings.Contains(result.Diff, "v2") || strings.Contains(result.Diff, "\033") {
+		t.Errorf("Compare() with changed output = %+v, %v, want a mismatch with a plain diff", result, err)
+	}
+
+	if _, err := Compare("missing", "v1", WithBaseDir(dir), WithUpdate(false)); !errors.Is(err, ErrComparison) {
+		t.Errorf("Compare() of a missing golden file error = %v, want ErrComparison", err)
+	}
+}
+
+func TestGoldenNDJSONRecordOrder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("events", "{\"event\":\"start\",\"tags\":[\"a\",\"b\"]}\n{\"event\":\"stop\"}\n")
+
+	// Array order within records is still ignored by default
+	New(t, WithBaseDir(dir)).Assert("events", "{\"event\":\"start\",\"tags\":[\"b\",\"a\"]}\n{\"event\":\"stop\"}\n")
+
+	reordered := "{\"event\":\"stop\"}\n{\"event\":\"start\",\"tags\":[\"a\",\"b\"]}\n"
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithBaseDir(dir)).Assert("events", reordered)
+	})
+	if len(rec.failures()) == 0 {
+		t.Error("Expected reordered records to fail")
+	}
+
+	New(t, WithBaseDir(dir), WithUnorderedRecords(true)).Assert("events", reordered)
+}
+
+func TestGoldenInvalidIgnoredValuePattern(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithBaseDir(dir), WithIgnoreValuesMatching("(unclosed"))
+	})
+
+	if failures := rec.failures(); len(failures) != 1 || !strings.Contains(failures[0], "(unclosed") {
+		t.Errorf("failures = %q, want one naming the invalid pattern", failures)
+	}
+
+	// Compare reports the invalid pattern instead of panicking
+	if _, err := Compare("report", "v1", WithBaseDir(dir), WithIgnoreValuesMatching(`price\$`)); !errors.Is(err, ErrComparison) || !strings.Contains(err.Error(), "invalid ignored value pattern") {
+		t.Errorf("Compare() error = %v, want the invalid pattern", err)
+	}
+}
+
+func TestGoldenSnapshot(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var first testing.TB
+
+	t.Run("first", func(t *testing.T) {
+		first = t
+
+		// Snapshots are counted across the Golden instances of the test
+		New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Snapshot("one")
+		New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Snapshot("two")
+	})
+
+	t.Run("second", func(t *testing.T) {
+		New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Snapshot("three")
+	})
+
+	snapshotMu.Lock()
+	_, kept := snapshotCounts[first]
+	snapshotMu.Unlock()
+
+	if kept {
+		t.Error("Expected the snapshot count to be dropped once the test finished")
+	}
+
+	want := map[string]string{
+		"golden_test_TestGoldenSnapshot__first_snapshot_1.golden.go":  "one",
+		"golden_test_TestGoldenSnapshot__first_snapshot_2.golden.go":  "two",
+		"golden_test_TestGoldenSnapshot__second_snapshot_1.golden.go": "three",
+	}
+
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("Snapshot %s = %q, %v, want %q", name, data, err, content)
+		}
+	}
+
+	// Reruns of a test with the same name, as with -count=2, start over
+	for _, update := range []bool{true, false} {
+		rec := runRecorded(t, func(tb testing.TB) {
+			g := New(tb, WithUpdate(update), WithReadOnly(false), WithBaseDir(dir))
+			g.Snapshot("one")
+			g.Snapshot("two")
+		})
+
+		if failures := rec.failures(); len(failures) > 0 {
+			t.Errorf("Expected the rerun to match, got %v", failures)
+		}
+	}
+}
+
+func TestGoldenSizeBudget(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	output := bytes.Repeat([]byte("x"), 16)
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithSizeBudget(8)).AssertRaw("big", output)
+	})
+
+	if failures := rec.failures(); len(failures) == 0 || !strings.Contains(failures[0], "size budget") {
+		t.Errorf("Expected a size budget failure, got %v", failures)
+	}
+
+	// Golden files over budget are still compared outside update mode
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).AssertRaw("big", output)
+	New(t, WithUpdate(false), WithBaseDir(dir), WithSizeBudget(8)).AssertRaw("big", output)
+}
+
+func TestGoldenMetadataHeader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithMetadataHeader(true))
+	g.Assert("user", map[string]string{"name": "alice"})
+
+	filename := g.manager.GetFilename("user")
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	header, _, ok := manager.ParseHeader(data)
+	if !ok || header.Test != t.Name() || header.Format != "json" || header.Created.IsZero() {
+		t.Fatalf("Unexpected header %+v in:\n%s", header, data)
+	}
+
+	// Updating keeps the creation time, and comparisons ignore the header
+	g.Assert("user", map[string]string{"name": "alice"})
+
+	updated, err := os.ReadFile(filename)
+	if err != nil || !bytes.Equal(updated, data) {
+		t.Errorf("Golden file changed on update:\n%s", updated)
+	}
+
+	New(t, WithBaseDir(dir)).Assert("user", map[string]string{"name": "alice"})
+}
+
+func TestGoldenReadOnly(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithBaseDir(dir), WithReadOnly(true)).Assert("config", "value")
+	})
+
+	if failures := rec.failures(); len(failures) == 0 || !strings.Contains(failures[0], "read-only") {
+		t.Errorf("Expected a read-only failure, got %v", failures)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected no golden files to be written, got %v, %v", entries, err)
+	}
+
+	// Comparisons still work
+	New(t, WithUpdate(true), WithBaseDir(dir), WithReadOnly(false)).Assert("config", "value")
+	New(t, WithBaseDir(dir), WithReadOnly(true)).Assert("config", "value")
+}
+
+func TestGoldenReadOnlyOnCI(t *testing.T) {
+	t.Setenv("CI", "true")
+	t.Setenv("GOLDEN_READ_ONLY", "")
+	os.Unsetenv("GOLDEN_READ_ONLY")
+
+	// Every write fails on CI, including in the temporary directory
+	dir := t.TempDir()
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithBaseDir(dir)).Assert("config", "value")
+	})
+
+	if failures := rec.failures(); len(failures) == 0 || !strings.Contains(failures[0], "read-only") {
+		t.Errorf("Expected a read-only failure, got %v", failures)
+	}
+
+	// Tests writing golden files on purpose opt out explicitly
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("config", "value")
+}
+
+func TestGoldenList(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir))
+	g.Assert("first", "1")
+	g.Assert("second", "2")
+
+	files := g.List()
+	if len(files) != 2 || files[0].GoldenName != "first" || files[1].GoldenName != "second" {
+		t.Errorf("List() = %+v, want first and second", files)
+	}
+
+	if files := g.Glob("*_second.golden.*"); len(files) != 1 || files[0].Path != g.manager.GetFilename("second") {
+		t.Errorf("Glob() = %+v, want the second golden file", files)
+	}
+}
+
+func TestGoldenMemory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mem := manager.NewMemoryFS()
+
+	// A dry run of update mode records the would-be writes
+	g := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithMemory(mem))
+	g.Assert("user", map[string]string{"name": "alice"})
+
+	if files := mem.Files(); len(files) != 1 || !strings.HasSuffix(files[0], "golden_test_TestGoldenMemory_user.golden.go") {
+		t.Errorf("Files() = %v, want the user golden file", files)
+	}
+
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("Expected nothing on disk, got %v, %v", entries, err)
+	}
+
+	New(t, WithBaseDir(dir), WithMemory(mem)).Assert("user", map[string]string{"name": "alice"})
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestGoldenWatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+
+	if err := os.WriteFile(input, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("output", "v1")
+
+	var out syncBuffer
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		w := &Watcher{Paths: []string{input}, Interval: 10 * time.Millisecond, Output: &out}
+		w.Run(ctx, t, func(tb testing.TB) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				tb.Errorf("Failed to read input: %v", err)
+
+				return
+			}
+
+			New(tb, WithBaseDir(dir)).Assert("output", string(data))
+		})
+	}()
+
+	waitFor := func(text string) {
+		t.Helper()
+
+		for deadline := time.Now().Add(5 * time.Second); !strings.Contains(out.String(), text); {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %q in:\n%s", text, out.String())
+			}
+
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor("run 1: PASS")
+
+	// Changing the input re-runs the comparison, which now fails with a diff
+	if err := os.WriteFile(input, []byte("v2 changed"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor("run 2: FAIL")
+
+	cancel()
+	<-done
+
+	if !strings.Contains(out.String(), "v2 changed") {
+		t.Errorf("Expected the diff in the output:\n%s", out.String())
+	}
+}
+
+func TestGoldenWatcherIgnoresOwnWrites(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var (
+		out  syncBuffer
+		runs atomic.Int32
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	w := &Watcher{Paths: []string{dir}, Interval: 10 * time.Millisecond, Output: &out}
+	w.Run(ctx, t, func(tb testing.TB) {
+		// Written like an updated golden file in the watched directory
+		n := runs.Add(1)
+		if err := os.WriteFile(filepath.Join(dir, "output.golden"), []byte(strconv.Itoa(int(n))), 0o600); err != nil {
+			tb.Errorf("Failed to write output: %v", err)
+		}
+	})
+
+	if n := runs.Load(); n != 1 {
+		t.Errorf("fn ran %d times without external changes, want 1:\n%s", n, out.String())
+	}
+}
+
+func TestGoldenWriteReport(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("report_ok", "same")
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("report_bad", "expected")
+
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithBaseDir(dir)).Assert("report_bad", "actual")
+	})
+	if len(rec.failures()) == 0 {
+		t.Fatal("Expected the changed output to fail")
+	}
+
+	archive := filepath.Join(t.TempDir(), "report.tar.gz")
+	if err := WriteReport(archive); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string)
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[header.Name] = string(data)
+	}
+
+	var manifest Report
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("Invalid manifest: %v\n%s", err, files["manifest.json"])
+	}
+
+	var failed *ReportEntry
+
+	statuses := make(map[string]string)
+
+	for i, entry := range manifest.Files {
+		if !strings.HasPrefix(entry.Golden, dir) {
+			continue
+		}
+
+		statuses[entry.Name] += entry.Status + " "
+
+		if entry.Status == reportFailed {
+			failed = &manifest.Files[i]
+		}
+
+		if !strings.HasPrefix(entry.Test, t.Name()) {
+			t.Errorf("Entry %s attributed to %q", entry.Name, entry.Test)
+		}
+
+		if files[archiveName(entry.Golden)] == "" {
+			t.Errorf("Golden file %s missing from the report", entry.Golden)
+		}
+	}
+
+	if statuses["report_ok"] != "updated " || statuses["report_bad"] != "updated failed " {
+		t.Fatalf("Statuses = %v", statuses)
+	}
+
+	if diff := files[failed.Diff]; !strings.Contains(diff, "Golden test failed") || strings.Contains(diff, "\033") {
+		t.Errorf("Diff = %q", diff)
+	}
+
+	if actual := files[failed.Actual]; actual != "actual" {
+		t.Errorf("Actual = %q, want %q", actual, "actual")
+	}
+
+	if failed.ChangedLines == 0 {
+		t.Errorf("ChangedLines = 0, want the changed lines of the diff")
+	}
+
+	r, err := ReadReport(archive)
+	if err != nil {
+		t.Fatalf("ReadReport() error = %v", err)
+	}
+
+	if len(r.Files) != len(manifest.Files) || len(r.Failures) == 0 {
+		t.Errorf("ReadReport() = %d files, %d failures", len(r.Files), len(r.Failures))
+	}
+}
+
+func TestReportRanksFailures(t *testing.T) {
+	t.Parallel()
+
+	r := &Report{Failures: rankFailures([]ReportEntry{
+		{Golden: "small", Status: reportFailed, ChangedPaths: 1, ChangedLines: 2},
+		{Golden: "ok", Status: reportCompared},
+		{Golden: "text", Status: reportFailed, ChangedLines: 40},
+		{Golden: "large", Status: reportFailed, ChangedPaths: 12, ChangedLines: 30},
+		{Golden: "wide", Status: reportFailed, ChangedPaths: 1, ChangedLines: 9},
+	})}
+
+	var order []string
+	for _, entry := range r.Failures {
+		order = append(order, entry.Golden)
+	}
+
+	if want := []string{"large", "wide", "small", "text"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("ranking = %v, want %v", order, want)
+	}
+
+	var out strings.Builder
+	if err := r.WriteFailures(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 5 || !strings.Contains(lines[1], "large") {
+		t.Errorf("WriteFailures() =\n%s", out.String())
+	}
+}
+
+func TestCompareReports(t *testing.T) {
+	t.Parallel()
+
+	failed := func(golden string) ReportEntry {
+		return ReportEntry{Golden: golden, Status: reportFailed}
+	}
+
+	previous := &Report{Failures: []ReportEntry{failed("fixed"), failed("still"), failed("removed")}}
+	current := &Report{
+		Files:    []ReportEntry{{Golden: "fixed", Status: reportCompared}, failed("still"), failed("new")},
+		Failures: []ReportEntry{failed("still"), failed("new")},
+	}
+
+	c := CompareReports(previous, current)
+
+	goldens := func(entries []ReportEntry) []string {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Golden)
+		}
+
+		return names
+	}
+
+	if got := goldens(c.NewlyBroken); !reflect.DeepEqual(got, []string{"new"}) {
+		t.Errorf("NewlyBroken = %v, want [new]", got)
+	}
+
+	if got := goldens(c.StillFailing); !reflect.DeepEqual(got, []string{"still"}) {
+		t.Errorf("StillFailing = %v, want [still]", got)
+	}
+
+	if got := goldens(c.Fixed); !reflect.DeepEqual(got, []string{"fixed"}) {
+		t.Errorf("Fixed = %v, want [fixed]", got)
+	}
+
+	var out strings.Builder
+	if err := c.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Newly broken: 1\n  new ()\nStill failing: 1\n  still ()\nFixed: 1\n  fixed ()\n"; out.String() != want {
+		t.Errorf("Write() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestGoldenDifferenceTable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("tags", map[string][]string{"tags": {"b", "c"}})
+
+	// Array order is ignored by default, yet the table points at the changed
+	// element as it appears in the golden file
+	rec := runRecorded(t, func(tb testing.TB) {
+		New(tb, WithBaseDir(dir)).Assert("tags", map[string][]string{"tags": {"z", "c"}})
+	})
+
+	failures := rec.failures()
+	if len(failures) != 1 {
+		t.Fatalf("Expected a single failure, got %v", failures)
+	}
+
+	table := failures[0][strings.Index(failures[0], "PATH"):strings.Index(failures[0], "Differences found")]
+	if !regexp.MustCompile(`/tags/0\s+changed\s+"b"\s+"z"`).MatchString(table) || strings.Contains(table, "/tags/1") {
+		t.Errorf("Expected a single row for /tags/0, got:\n%s", table)
+	}
+}
+
+func TestGoldenDetectMovesOptIn(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	expected := "alpha\nbeta\ngamma\ndelta\n"
+	actual := "gamma\ndelta\nalpha\nbeta\n"
+
+	runRecorded(t, func(tb testing.TB) {
+		New(tb, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).Assert("moves", expected)
+	})
+
+	for _, enabled := range []bool{false, true} {
+		rec := runRecorded(t, func(tb testing.TB) {
+			New(tb, WithBaseDir(dir), WithDetectMoves(enabled)).Assert("moves", actual)
+		})
+
+		failures := rec.failures()
+		if len(failures) != 1 {
+			t.Fatalf("expected one failure, got %v", failures)
+		}
+
+		if moved := strings.Contains(failures[0], "[moved"); moved != enabled {
+			t.Errorf("WithDetectMoves(%v): moved annotation present = %v:\n%s", enabled, moved, failures[0])
+		}
+	}
+}
+
+func TestGoldenWriteReportReadsThroughManager(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mem := manager.NewMemoryFS()
+
+	New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir), WithMemory(mem)).Assert("in_memory", "stored in memory")
+
+	archive := filepath.Join(t.TempDir(), "report.tar.gz")
+	if err := WriteReport(archive); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(header.Name, "_in_memory.golden") {
+			continue
+		}
+
+		if data, _ := io.ReadAll(tr); string(data) != "stored in memory" {
+			t.Errorf("archived golden = %q, want the in-memory content", data)
+		}
+
+		return
+	}
+
+	t.Error("in-memory golden file missing from the report")
+}
+
+func TestCaptureTBOutsideOfTests(t *testing.T) {
+	t.Parallel()
+
+	var dir string
+
+	ctb := newCaptureTB(nil, nil)
+	ctb.run(func(tb testing.TB) {
+		dir = tb.TempDir()
+		if tb.Context().Err() != nil {
+			tb.Error("context canceled during the assertion")
+		}
+
+		tb.Setenv("GOLDEN_CAPTURE_TB", "1")
+		tb.Error("not reached")
+	})
+
+	if failures := ctb.messages(); len(failures) != 1 || !strings.Contains(failures[0], "Setenv is not supported outside of tests") {
+		t.Errorf("failures = %v, want Setenv to be unsupported", failures)
+	}
+
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("temporary directory %s not removed: %v", dir, err)
+	}
+
+	if ctb.Context().Err() == nil {
+		t.Error("context not canceled once the assertion is done")
+	}
+}
+
+func TestGoldenHTTPRecorder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"path": %q, "query": %q}`, r.URL.Path, r.URL.RawQuery)
+	}))
+
+	get := func(client *http.Client, url string) string {
+		t.Helper()
+
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", url, err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(body)
+	}
+
+	recorder := New(t, WithUpdate(true), WithReadOnly(false), WithBaseDir(dir)).HTTPRecorder(nil)
+	recorded := get(&http.Client{Transport: recorder}, server.URL+"/api/users?page=2")
+
+	server.Close()
+
+	// Replaying does not reach the closed server
+	replayer := New(t, WithBaseDir(dir)).HTTPRecorder(nil)
+	if replayed := get(&http.Client{Transport: replayer}, server.URL+"/api/users?page=2"); !strings.Contains(replayed, `"/api/users"`) {
+		t.Errorf("replayed body = %s, want the recorded %s", replayed, recorded)
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.WriteHAR(&buf); err != nil {
+		t.Fatalf("WriteHAR() error = %v", err)
+	}
+
+	var har harFile
+	if err := json.Unmarshal(buf.Bytes(), &har); err != nil {
+		t.Fatalf("WriteHAR() wrote invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	if len(har.Log.Entries) != 1 {
+		t.Fatalf("WriteHAR() wrote %d entries, want 1", len(har.Log.Entries))
+	}
+
+	entry := har.Log.Entries[0]
+	if entry.Request.Method != http.MethodGet || entry.Response.Status != http.StatusOK || entry.Response.Content.Text != recorded {
+		t.Errorf("HAR entry = %+v, want the recorded exchange", entry)
+	}
+
+	if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0] != (harNameValue{Name: "page", Value: "2"}) {
+		t.Errorf("HAR query string = %v, want page=2", entry.Request.QueryString)
+	}
+}
+
+func TestGoldenImportHAR(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	harPath := filepath.Join(t.TempDir(), "capture.har")
+
+	har := `{"log": {"version": "1.2", "creator": {"name": "devtools", "version": ""}, "entries": [
+  {"request": {"method": "GET", "url": "https://example.com/api/items?limit=1"},
+   "response": {"status": 200, "headers": [{"name": "Content-Type", "value": "application/json"}, {"name": "Date", "value": "Tue, 01 Oct 2024 00:00:00 GMT"}],
+                "content": {"mimeType": "application/json", "text": "{\"items\": [1]}"}}},
+  {"request": {"method": "GET", "url": "https://example.com/api/items"},
+   "response": {"status": 404, "content": {"mimeType": "text/plain", "encoding": "base64", "text": "bm90IGZvdW5k"}}}
+]}}`
+	if err := os.WriteFile(harPath, []byte(har), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	New(t, WithBaseDir(dir), WithReadOnly(false)).ImportHAR(harPath)
+
+	client := &http.Client{Transport: New(t, WithBaseDir(dir)).HTTPRecorder(nil)}
+
+	for _, want := range []struct {
+		status int
+		body   string
+	}{
+		{http.StatusOK, `"items"`},
+		{http.StatusNotFound, "not found"},
+	} {
+		resp, err := client.Get("http://localhost.invalid/api/items")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != want.status || !strings.Contains(string(body), want.body) {
+			t.Errorf("replayed %d %s, want %d %s", resp.StatusCode, body, want.status, want.body)
+		}
+
+		if date := resp.Header.Get("Date"); date != "" && date != scrubbedHeader {
+			t.Errorf("Date header = %q, want it scrubbed", date)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package grpcgolden provides golden testing of gRPC responses and status
+// errors, and record/replay of unary calls.
+//
+// A call is snapshotted as JSON holding either the response, serialized like
+// protogolden.Marshal, or the status:
+//
+//	{"status": {"code": "NotFound", "message": "...", "details": [...]}}
+//
+// Status details are written in the protojson form of google.protobuf.Any,
+// so their message types must be linked into the test binary.
+package grpcgolden
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/sivchari/golden"
+	"github.com/sivchari/golden/protogolden"
+)
+
+var (
+	// errEmptySnapshot is returned for a snapshot without response or status.
+	errEmptySnapshot = errors.New("snapshot holds neither a response nor a status")
+	// errUnknownCode is returned for a status code without a name.
+	errUnknownCode = errors.New("unknown status code")
+)
+
+// snapshot is the golden representation of the outcome of a call.
+type snapshot struct {
+	Response json.RawMessage `json:"response,omitempty"`
+	Status   *statusSnapshot `json:"status,omitempty"`
+}
+
+// statusSnapshot is the golden representation of a status error.
+type statusSnapshot struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// Marshal serializes the outcome of a call: the status of callErr if it is
+// not nil, and resp otherwise.
+func Marshal(resp proto.Message, callErr error) ([]byte, error) {
+	var snap snapshot
+
+	if callErr != nil {
+		st, err := marshalStatus(status.Convert(callErr))
+		if err != nil {
+			return nil, err
+		}
+
+		snap.Status = st
+	} else {
+		data, err := protogolden.Marshal(resp)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Already wrapped by protogolden
+		}
+
+		snap.Response = data
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	return data, nil
+}
+
+// marshalStatus converts st into its snapshot.
+func marshalStatus(st *status.Status) (*statusSnapshot, error) {
+	snap := &statusSnapshot{Code: st.Code().String(), Message: st.Message()}
+
+	for _, detail := range st.Proto().GetDetails() {
+		data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(detail)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal status detail %s: %w", detail.GetTypeUrl(), err)
+		}
+
+		snap.Details = append(snap.Details, data)
+	}
+
+	return snap, nil
+}
+
+// Assert compares the outcome of a call, resp or the status of callErr,
+// with the golden file name of g, semantically and honoring the options g
+// was created with.
+func Assert(tb testing.TB, g *golden.Golden, name string, resp proto.Message, callErr error) {
+	tb.Helper()
+
+	data, err := Marshal(resp, callErr)
+	if err != nil {
+		tb.Fatalf("Failed to serialize %s: %v", name, err)
+	}
+
+	g.Assert(name, data)
+}
+
+// AssertStatus compares the status of err, including its details, with the
+// golden file name of g.
+func AssertStatus(tb testing.TB, g *golden.Golden, name string, err error) {
+	tb.Helper()
+
+	if err == nil {
+		tb.Fatalf("Expected a status error for %s, got nil", name)
+	}
+
+	Assert(tb, g, name, nil, err)
+}
+
+// Unmarshal restores the outcome of a call serialized by Marshal into resp,
+// returning the recorded status as an error.
+func Unmarshal(data []byte, resp proto.Message) error {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	switch {
+	case snap.Status != nil:
+		return unmarshalStatus(snap.Status)
+	case snap.Response != nil:
+		if err := protojson.Unmarshal(snap.Response, resp); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		return nil
+	default:
+		return errEmptySnapshot
+	}
+}
+
+// unmarshalStatus restores the status error of snap.
+func unmarshalStatus(snap *statusSnapshot) error {
+	code, err := parseCode(snap.Code)
+	if err != nil {
+		return err
+	}
+
+	pb := status.New(code, snap.Message).Proto()
+
+	for _, data := range snap.Details {
+		detail := &anypb.Any{}
+		if err := protojson.Unmarshal(data, detail); err != nil {
+			return fmt.Errorf("failed to decode status detail: %w", err)
+		}
+
+		pb.Details = append(pb.Details, detail)
+	}
+
+	return status.FromProto(pb).Err()
+}
+
+// parseCode parses the name of a status code, e.g. "NotFound".
+func parseCode(name string) (codes.Code, error) {
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		if code.String() == name {
+			return code, nil
+		}
+	}
+
+	return codes.Unknown, fmt.Errorf("%w %q", errUnknownCode, name)
+}
+
+// UnaryClientInterceptor records the outcome of unary calls into golden
+// files of g when g is updating, and replays them otherwise without calling
+// the server. Calls are named after their method and their position among
+// the calls to it, e.g. "helloworld.Greeter_SayHello_1".
+func UnaryClientInterceptor(tb testing.TB, g *golden.Golden) grpc.UnaryClientInterceptor {
+	tb.Helper()
+
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]int)
+	)
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		mu.Lock()
+		calls[method]++
+		name := callName(method, calls[method])
+		mu.Unlock()
+
+		resp, ok := reply.(proto.Message)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		if !g.Updating() {
+			data, err := g.Load(name)
+			if err != nil {
+				return fmt.Errorf("failed to replay %s: %w", name, err)
+			}
+
+			return Unmarshal(data, resp)
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		Assert(tb, g, name, resp, err)
+
+		return err
+	}
+}
+
+// callName names the nth call to method, e.g. "/pkg.Service/Method".
+func callName(method string, n int) string {
+	return strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", "_") + "_" + strconv.Itoa(n)
+}
@@ -0,0 +1,83 @@
+package grpcgolden
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/sivchari/golden"
+)
+
+func TestMarshalStatus(t *testing.T) {
+	t.Parallel()
+
+	st, err := status.New(codes.NotFound, "user 1 not found").WithDetails(&errdetails.ResourceInfo{ResourceType: "user", ResourceName: "1"})
+	if err != nil {
+		t.Fatalf("WithDetails() error = %v", err)
+	}
+
+	data, err := Marshal(nil, st.Err())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	for _, want := range []string{`"code":"NotFound"`, `"message":"user 1 not found"`, `"resource_type":"user"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Marshal() = %s, want %s", data, want)
+		}
+	}
+
+	restored := Unmarshal(data, &descriptorpb.FileDescriptorProto{})
+	if got := status.Convert(restored); got.Code() != codes.NotFound || len(got.Details()) != 1 {
+		t.Errorf("Unmarshal() = %v, want the recorded status with its detail", restored)
+	}
+}
+
+func TestUnaryClientInterceptorReplays(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	calls := 0
+	invoker := func(_ context.Context, _ string, _, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		calls++
+
+		if calls > 1 {
+			return status.Error(codes.Unavailable, "server is down")
+		}
+
+		proto.Merge(reply.(proto.Message), &descriptorpb.FileDescriptorProto{Name: proto.String("user.proto")}) //nolint:forcetypeassert // The test passes a message
+
+		return nil
+	}
+
+	record := UnaryClientInterceptor(t, golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false), golden.WithBaseDir(dir)))
+	if err := record(t.Context(), "/files.Service/Get", nil, &descriptorpb.FileDescriptorProto{}, nil, invoker); err != nil {
+		t.Fatalf("recording call error = %v", err)
+	}
+
+	if err := record(t.Context(), "/files.Service/Get", nil, &descriptorpb.FileDescriptorProto{}, nil, invoker); status.Code(err) != codes.Unavailable {
+		t.Fatalf("recording call error = %v, want Unavailable", err)
+	}
+
+	replay := UnaryClientInterceptor(t, golden.New(t, golden.WithUpdate(false), golden.WithBaseDir(dir)))
+
+	reply := &descriptorpb.FileDescriptorProto{}
+	if err := replay(t.Context(), "/files.Service/Get", nil, reply, nil, invoker); err != nil || reply.GetName() != "user.proto" {
+		t.Errorf("replayed call = %v, %v, want the recorded response", reply, err)
+	}
+
+	if err := replay(t.Context(), "/files.Service/Get", nil, &descriptorpb.FileDescriptorProto{}, nil, invoker); status.Code(err) != codes.Unavailable {
+		t.Errorf("replayed call error = %v, want the recorded Unavailable status", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("server called %d times, want 2 while recording only", calls)
+	}
+}
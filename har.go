@@ -0,0 +1,426 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// harVersion is the version of the HAR format written by WriteHAR.
+const harVersion = "1.2"
+
+// harFile is the root of an HTTP Archive, as exported by browser devtools
+// and proxies. Only the fields golden reads or writes are declared.
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"` // Milliseconds
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"` //nolint:tagliatelle // Field name of the HAR format
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"` // "base64" for binary content
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HTTPRecorder is an http.RoundTripper recording HTTP exchanges into golden
+// files when its Golden is updating, and replaying them otherwise without
+// sending the requests, like grpcgolden.UnaryClientInterceptor. Exchanges
+// are named after their method and URL path and their position among the
+// requests to them, e.g. "GET_api_users_1", and snapshotted like
+// AssertHTTPResponse. The exchanges of the test can be exported with
+// WriteHAR, and HAR files captured elsewhere imported with ImportHAR.
+type HTTPRecorder struct {
+	g    *Golden
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	calls   map[string]int
+	entries []harEntry
+}
+
+// HTTPRecorder returns a recorder sending requests with base, or
+// http.DefaultTransport if base is nil, when g is updating:
+//
+//	client := &http.Client{Transport: g.HTTPRecorder(nil)}
+func (g *Golden) HTTPRecorder(base http.RoundTripper) *HTTPRecorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &HTTPRecorder{g: g, base: base, calls: make(map[string]int)}
+}
+
+// RoundTrip records or replays the exchange of req.
+func (r *HTTPRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	r.mu.Lock()
+	key := httpCallKey(req.Method, req.URL.Path)
+	r.calls[key]++
+	name := key + "_" + strconv.Itoa(r.calls[key])
+	r.mu.Unlock()
+
+	started := time.Now()
+
+	var resp *http.Response
+
+	if r.g.Updating() {
+		resp, err = r.record(name, req)
+	} else {
+		resp, err = r.replay(name, req)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body of %s: %w", name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, newHAREntry(started, time.Since(started), req, reqBody, resp, respBody))
+
+	return resp, nil
+}
+
+// record sends req and compares the response with the golden file name,
+// which update mode writes.
+func (r *HTTPRecorder) record(name string, req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // The error of the wrapped transport is returned as is
+	}
+
+	body, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body of %s: %w", name, err)
+	}
+
+	r.g.assertHTTP(name, resp.StatusCode, resp.Header, body)
+
+	return resp, nil
+}
+
+// replay returns the response recorded in the golden file name.
+func (r *HTTPRecorder) replay(name string, req *http.Request) (*http.Response, error) {
+	data, err := r.g.Load(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay %s: %w", name, err)
+	}
+
+	var snapshot struct {
+		Status  int                        `json:"status"`
+		Headers map[string]json.RawMessage `json:"headers"`
+		Body    json.RawMessage            `json:"body"`
+	}
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+
+	header := make(http.Header, len(snapshot.Headers))
+
+	for key, raw := range snapshot.Headers {
+		var values []string
+		if err := json.Unmarshal(raw, &values); err != nil {
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("failed to decode header %s of %s: %w", key, name, err)
+			}
+
+			values = []string{value}
+		}
+
+		header[key] = values
+	}
+
+	body := []byte(snapshot.Body)
+
+	var text string
+	if json.Unmarshal(snapshot.Body, &text) == nil {
+		body = []byte(text)
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(snapshot.Status) + " " + http.StatusText(snapshot.Status),
+		StatusCode:    snapshot.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// WriteHAR writes the exchanges recorded or replayed so far as an HTTP
+// Archive, which browser devtools and proxies can open.
+func (r *HTTPRecorder) WriteHAR(w io.Writer) error {
+	r.mu.Lock()
+	entries := append([]harEntry{}, r.entries...)
+	r.mu.Unlock()
+
+	har := harFile{Log: harLog{
+		Version: harVersion,
+		Creator: harCreator{Name: "golden"},
+		Entries: entries,
+	}}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(har); err != nil {
+		return fmt.Errorf("failed to write HAR: %w", err)
+	}
+
+	return nil
+}
+
+// ImportHAR writes the responses of the HAR file path, e.g. exported from
+// browser devtools or a proxy, into golden files replayed by HTTPRecorder,
+// named like the exchanges it records. It writes them regardless of update
+// mode, subject to read-only mode, and fails the test if the file cannot be
+// read.
+func (g *Golden) ImportHAR(path string) {
+	g.t.Helper()
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: The HAR file is chosen by the test
+	if err != nil {
+		g.t.Fatalf("Failed to read HAR file %s: %v", path, err)
+	}
+
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		g.t.Fatalf("Failed to decode HAR file %s: %v", path, err)
+	}
+
+	update := g.with(WithUpdate(true))
+	calls := make(map[string]int)
+
+	for i, entry := range har.Log.Entries {
+		body, err := entry.Response.Content.decode()
+		if err != nil {
+			g.t.Fatalf("Failed to decode response %d of HAR file %s: %v", i+1, path, err)
+		}
+
+		key := httpCallKey(entry.Request.Method, requestPath(entry.Request.URL))
+		calls[key]++
+
+		header := make(http.Header)
+		for _, h := range entry.Response.Headers {
+			header.Add(h.Name, h.Value)
+		}
+
+		update.assertHTTP(key+"_"+strconv.Itoa(calls[key]), entry.Response.Status, header, body)
+	}
+}
+
+// decode returns the content of a HAR response.
+func (c harContent) decode() ([]byte, error) {
+	if c.Encoding != "base64" {
+		return []byte(c.Text), nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(c.Text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 content: %w", err)
+	}
+
+	return data, nil
+}
+
+// httpCallKey names the requests with method to path, e.g. "GET_api_users".
+func httpCallKey(method, path string) string {
+	key := strings.ToUpper(method)
+	if path = strings.Trim(path, "/"); path != "" {
+		key += "_" + strings.ReplaceAll(path, "/", "_")
+	}
+
+	return key
+}
+
+// requestPath returns the path of rawURL, ignoring the query.
+func requestPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Path
+}
+
+// readBody reads *body and replaces it, so that it can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(data))
+
+	return data, err //nolint:wrapcheck // Wrapped by the callers, which know what is read
+}
+
+// newHAREntry describes an exchange as a HAR entry.
+func newHAREntry(started time.Time, elapsed time.Duration, req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) harEntry {
+	ms := float64(elapsed) / float64(time.Millisecond)
+
+	entry := harEntry{
+		StartedDateTime: started,
+		Time:            ms,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: httpVersion(req.Proto),
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(req.Header),
+			QueryString: harQuery(req),
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: httpVersion(resp.Proto),
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(resp.Header),
+			Content:     newHARContent(resp.Header.Get("Content-Type"), respBody),
+			HeadersSize: -1,
+			BodySize:    len(respBody),
+		},
+		Timings: harTimings{Wait: ms},
+	}
+
+	if len(reqBody) > 0 {
+		entry.Request.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: string(reqBody)}
+	}
+
+	return entry
+}
+
+// newHARContent describes a response body, base64 encoded if binary.
+func newHARContent(mimeType string, body []byte) harContent {
+	content := harContent{Size: len(body), MimeType: mimeType, Text: string(body)}
+	if isBinary(body) {
+		content.Text, content.Encoding = base64.StdEncoding.EncodeToString(body), "base64"
+	}
+
+	return content
+}
+
+// harHeaders lists header in a stable order.
+func harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[key] {
+			headers = append(headers, harNameValue{Name: key, Value: value})
+		}
+	}
+
+	return headers
+}
+
+// harQuery lists the query parameters of req in a stable order.
+func harQuery(req *http.Request) []harNameValue {
+	query := req.URL.Query()
+	params := []harNameValue{}
+
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		for _, value := range query[key] {
+			params = append(params, harNameValue{Name: key, Value: value})
+		}
+	}
+
+	return params
+}
+
+// httpVersion returns proto, defaulting to HTTP/1.1 for requests built
+// without one.
+func httpVersion(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+
+	return proto
+}
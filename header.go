@@ -0,0 +1,54 @@
+package golden
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/sivchari/golden/manager"
+)
+
+// modulePath is the module path of the library, recorded in headers.
+const modulePath = "github.com/sivchari/golden"
+
+// withHeader prepends the metadata header to the content of the golden file
+// filename. The creation time of an existing header is kept, so that
+// updating an unchanged golden file leaves it untouched.
+func (g *Golden) withHeader(filename string, content []byte) []byte {
+	created := time.Now()
+
+	if existing, err := g.manager.ReadFile(filename); err == nil {
+		if header, _, ok := manager.ParseHeader(existing); ok && !header.Created.IsZero() {
+			created = header.Created
+		}
+	}
+
+	header := manager.Header{
+		Library: libraryVersion(),
+		Test:    g.t.Name(),
+		Created: created,
+		Format:  g.contentExtension(content),
+	}
+
+	return append(header.Bytes(), content...)
+}
+
+// libraryVersion returns the module path and, if known from the build
+// information, the version of the library.
+func libraryVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return modulePath
+	}
+
+	if info.Main.Path == modulePath {
+		return modulePath + " " + info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return modulePath + " " + dep.Version
+		}
+	}
+
+	return modulePath
+}
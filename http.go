@@ -0,0 +1,100 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+)
+
+// scrubbedHeader replaces the values of headers that change between runs.
+const scrubbedHeader = "<SCRUBBED>"
+
+// volatileHeaders are the headers whose values are scrubbed from HTTP
+// response snapshots.
+var volatileHeaders = []string{"Date", "Set-Cookie", "Request-Id", "X-Request-Id"}
+
+// httpSnapshot is the golden representation of an HTTP response.
+type httpSnapshot struct {
+	Status  int                    `json:"status"`
+	Headers map[string]interface{} `json:"headers,omitempty"`
+	Body    interface{}            `json:"body,omitempty"`
+}
+
+// AssertHTTPResponse compares the status, headers and body of resp with the
+// golden file name. Headers are limited to those set with WithHTTPHeaders,
+// if any, and the values of Date, Set-Cookie, Request-Id and X-Request-Id
+// are scrubbed. JSON bodies are embedded as JSON, so they are compared
+// semantically and ignore-field paths reach into them, e.g. "body.id".
+// resp.Body is read and replaced, so it can still be read afterwards.
+func (g *Golden) AssertHTTPResponse(name string, resp *http.Response) {
+	g.t.Helper()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		g.t.Fatalf("Failed to read response body for %s: %v", name, err)
+	}
+
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	g.assertHTTP(name, resp.StatusCode, resp.Header, body)
+}
+
+// AssertResponseRecorder is like AssertHTTPResponse for the response
+// recorded by rec.
+func (g *Golden) AssertResponseRecorder(name string, rec *httptest.ResponseRecorder) {
+	g.t.Helper()
+
+	g.assertHTTP(name, rec.Code, rec.Header(), rec.Body.Bytes())
+}
+
+func (g *Golden) assertHTTP(name string, status int, header http.Header, body []byte) {
+	snapshot := httpSnapshot{Status: status, Headers: g.snapshotHeaders(header)}
+
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		snapshot.Body = json.RawMessage(body)
+	default:
+		snapshot.Body = string(body)
+	}
+
+	data, err := g.jsonStyle().marshal(snapshot)
+	if err != nil {
+		g.t.Fatalf("Failed to serialize response %s: %v", name, err)
+	}
+
+	g.assertBytes(name, g.formatJSON(data))
+}
+
+// snapshotHeaders returns the selected headers of header, with volatile
+// values scrubbed. Headers with one value are stored as a string.
+func (g *Golden) snapshotHeaders(header http.Header) map[string]interface{} {
+	selected := make(map[string]bool, len(g.options.HTTPHeaders))
+	for _, name := range g.options.HTTPHeaders {
+		selected[http.CanonicalHeaderKey(name)] = true
+	}
+
+	headers := make(map[string]interface{})
+
+	for key, values := range header {
+		if len(selected) > 0 && !selected[key] {
+			continue
+		}
+
+		if slices.Contains(volatileHeaders, key) {
+			values = slices.Repeat([]string{scrubbedHeader}, len(values))
+		}
+
+		if len(values) == 1 {
+			headers[key] = values[0]
+		} else {
+			headers[key] = values
+		}
+	}
+
+	return headers
+}
@@ -0,0 +1,169 @@
+package golden
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sivchari/golden/manager"
+)
+
+// acceptDir is the directory under the temporary directory holding the
+// outputs that accept links write to golden files.
+const acceptDir = "golden-accept"
+
+// ErrAcceptLink is returned by Accept for links it does not handle.
+var ErrAcceptLink = errors.New("invalid golden accept link")
+
+// hyperlinkTerminals lists TERM_PROGRAM values of terminals known to support OSC 8.
+var hyperlinkTerminals = map[string]bool{
+	"iTerm.app": true,
+	"WezTerm":   true,
+	"vscode":    true,
+	"ghostty":   true,
+	"Hyper":     true,
+}
+
+// supportsHyperlinks reports whether the terminal is likely to render OSC 8
+// hyperlinks. FORCE_HYPERLINK=1 or 0 overrides the detection.
+func supportsHyperlinks() bool {
+	if force := os.Getenv("FORCE_HYPERLINK"); force != "" {
+		enabled, err := strconv.ParseBool(force)
+
+		return err == nil && enabled
+	}
+
+	if hyperlinkTerminals[os.Getenv("TERM_PROGRAM")] {
+		return true
+	}
+
+	if os.Getenv("WT_SESSION") != "" || os.Getenv("KONSOLE_VERSION") != "" {
+		return true
+	}
+
+	// VTE based terminals (GNOME Terminal, Tilix, ...) support OSC 8 since 0.50
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+
+	return false
+}
+
+// fileHyperlink renders text as an OSC 8 hyperlink to the file at path.
+func fileHyperlink(path, text string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return text
+	}
+
+	link := url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+
+	return hyperlink(link.String(), text)
+}
+
+// hyperlink renders text as an OSC 8 hyperlink to target.
+func hyperlink(target, text string) string {
+	return "\033]8;;" + target + "\033\\" + text + "\033]8;;\033\\"
+}
+
+// acceptLink saves update, the output update mode would write to filename,
+// under the temporary directory and renders a golden://accept link writing
+// it to filename once opened with Accept, e.g. by cmd/golden-accept. It
+// returns "" without hyperlinks, for nil update and for golden files not
+// stored as plain files.
+func (g *Golden) acceptLink(filename string, update []byte) string {
+	if !g.acceptable(update) {
+		return ""
+	}
+
+	golden, err := filepath.Abs(filename)
+	if err != nil {
+		return ""
+	}
+
+	content := g.collapseVars(update)
+	if g.options.MetadataHeader && !isBinary(content) {
+		content = g.withHeader(filename, content)
+	}
+
+	sum := sha256.Sum256(append([]byte(golden+"\x00"), content...))
+	pending := filepath.Join(os.TempDir(), acceptDir, hex.EncodeToString(sum[:8])+".actual")
+
+	if err := os.MkdirAll(filepath.Dir(pending), 0o700); err != nil {
+		return ""
+	}
+
+	if err := os.WriteFile(pending, content, 0o600); err != nil {
+		return ""
+	}
+
+	link := url.URL{Scheme: "golden", Host: "accept", RawQuery: url.Values{"golden": {golden}, "actual": {pending}}.Encode()}
+
+	return hyperlink(link.String(), "accept")
+}
+
+// acceptable reports whether an accept link can be offered for update.
+func (g *Golden) acceptable(update []byte) bool {
+	switch {
+	case !g.options.Hyperlinks || update == nil:
+		return false
+	case g.options.Deduplicate || g.options.Memory != nil || g.options.FS != nil:
+		// Not stored as a plain file
+		return false
+	default:
+		return g.options.BlobThreshold == 0 || len(update) <= g.options.BlobThreshold
+	}
+}
+
+// Accept handles a golden://accept link of failure output, writing the
+// output saved for it to its golden file, and returns the golden file. It
+// only writes golden files, with outputs saved by failed comparisons.
+func Accept(link string) (string, error) {
+	golden, pending, err := parseAcceptLink(link)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(pending) //nolint:gosec // G304: The path is checked to be a saved output
+	if err != nil {
+		return "", fmt.Errorf("failed to read saved output: %w", err)
+	}
+
+	// The manager compresses golden files by extension, as update mode does
+	if err := manager.New(filepath.Dir(golden), "", "").WriteFile(golden, data); err != nil {
+		return "", fmt.Errorf("failed to accept %s: %w", golden, err)
+	}
+
+	if err := os.Remove(pending); err != nil {
+		return golden, fmt.Errorf("failed to remove saved output: %w", err)
+	}
+
+	return golden, nil
+}
+
+// parseAcceptLink returns the golden file and saved output of an accept link.
+func parseAcceptLink(link string) (string, string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrAcceptLink, err)
+	}
+
+	query := u.Query()
+	golden, pending := query.Get("golden"), query.Get("actual")
+
+	if u.Scheme != "golden" || u.Host != "accept" || !filepath.IsAbs(golden) || !strings.Contains(filepath.Base(golden), ".golden.") {
+		return "", "", fmt.Errorf("%w: %s", ErrAcceptLink, link)
+	}
+
+	if rel, err := filepath.Rel(filepath.Join(os.TempDir(), acceptDir), pending); err != nil || !filepath.IsLocal(rel) {
+		return "", "", fmt.Errorf("%w: %s is not a saved output", ErrAcceptLink, pending)
+	}
+
+	return golden, pending, nil
+}
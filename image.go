@@ -0,0 +1,121 @@
+package golden
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"strings"
+)
+
+// AssertImage compares img with the PNG stored in the golden file name,
+// pixel by pixel. Small rendering differences can be tolerated with
+// WithImageTolerance. On failure, an image highlighting the differing pixels
+// in red is written next to the golden file.
+func (g *Golden) AssertImage(name string, img image.Image) {
+	g.t.Helper()
+
+	filename := g.goldenFilename(name, "png")
+
+	if g.options.Update {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			g.t.Fatalf("Failed to encode %s as PNG: %v", filename, err)
+		}
+
+		g.checkMutable(filename)
+		g.writeGolden(filename, buf.Bytes())
+
+		return
+	}
+
+	data, err := g.readGolden(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			g.t.Fatalf("Golden file %s does not exist. Run with update mode to create it.", filename)
+		}
+
+		g.t.Fatalf("Failed to read golden file %s: %v", filename, err)
+	}
+
+	expected, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		g.t.Fatalf("Failed to decode golden file %s as PNG: %v", filename, err)
+	}
+
+	if expected.Bounds().Size() != img.Bounds().Size() {
+		g.t.Fatalf("Image %s has size %v, want %v", filename, img.Bounds().Size(), expected.Bounds().Size())
+	}
+
+	diff, count := diffImages(expected, img, g.options.ImageTolerance)
+
+	total := expected.Bounds().Dx() * expected.Bounds().Dy()
+	if count == 0 || float64(count)*100 <= g.options.ImageMaxDiffPercent*float64(total) {
+		return
+	}
+
+	diffFile := filename
+	if i := strings.LastIndex(diffFile, ".golden."); i >= 0 {
+		diffFile = diffFile[:i]
+	}
+
+	diffFile += ".diff.png"
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, diff); err != nil {
+		g.t.Fatalf("Failed to encode the diff of %s: %v", filename, err)
+	}
+
+	if err := g.manager.WriteFile(diffFile, buf.Bytes()); err != nil {
+		g.t.Fatalf("Failed to write the diff of %s: %v", filename, err)
+	}
+
+	g.t.Fatalf("Image %s differs in %d of %d pixels (%.2f%%, tolerated %.2f%%), see %s",
+		filename, count, total, float64(count)*100/float64(total), g.options.ImageMaxDiffPercent, diffFile)
+}
+
+// diffImages counts the pixels of actual with a channel differing from
+// expected by more than tolerance. The returned image shows expected faded
+// to gray with the differing pixels in red.
+func diffImages(expected, actual image.Image, tolerance uint8) (*image.NRGBA, int) {
+	bounds := expected.Bounds()
+	offset := actual.Bounds().Min.Sub(bounds.Min)
+	diff := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	count := 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			exp := color.NRGBAModel.Convert(expected.At(x, y)).(color.NRGBA)                 //nolint:forcetypeassert // NRGBAModel returns NRGBA
+			act := color.NRGBAModel.Convert(actual.At(x+offset.X, y+offset.Y)).(color.NRGBA) //nolint:forcetypeassert // NRGBAModel returns NRGBA
+			point := image.Pt(x-bounds.Min.X, y-bounds.Min.Y)
+
+			if pixelsDiffer(exp, act, tolerance) {
+				diff.SetNRGBA(point.X, point.Y, color.NRGBA{R: 255, A: 255})
+
+				count++
+
+				continue
+			}
+
+			gray := color.GrayModel.Convert(exp).(color.Gray) //nolint:forcetypeassert // GrayModel returns Gray
+			faded := 255 - (255-gray.Y)/4
+			diff.SetNRGBA(point.X, point.Y, color.NRGBA{R: faded, G: faded, B: faded, A: 255})
+		}
+	}
+
+	return diff, count
+}
+
+// pixelsDiffer reports whether a channel of a and b differs by more than
+// tolerance.
+func pixelsDiffer(a, b color.NRGBA, tolerance uint8) bool {
+	for _, channels := range [][2]uint8{{a.R, b.R}, {a.G, b.G}, {a.B, b.B}, {a.A, b.A}} {
+		if max(channels[0], channels[1])-min(channels[0], channels[1]) > tolerance {
+			return true
+		}
+	}
+
+	return false
+}
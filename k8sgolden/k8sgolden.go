@@ -0,0 +1,175 @@
+// Package k8sgolden provides golden testing of Kubernetes objects.
+//
+// Objects are read through their JSON form, so typed runtime.Objects, lists
+// and unstructured maps are all supported without depending on the
+// Kubernetes client libraries. Fields populated by the API server are
+// stripped and named lists are sorted, so that snapshots of objects read
+// back from a cluster are stable.
+package k8sgolden
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sivchari/golden"
+	"github.com/sivchari/golden/comparator"
+)
+
+// DefaultStripFields are the server-populated fields removed from every
+// object, as dotted paths relative to the object.
+var DefaultStripFields = []string{
+	"metadata.managedFields",
+	"metadata.resourceVersion",
+	"metadata.uid",
+	"metadata.creationTimestamp",
+	"status",
+}
+
+// Option configures Normalize.
+type Option func(*config)
+
+// config holds the fields to strip.
+type config struct {
+	strip []string
+}
+
+// WithStripFields strips the dotted paths in addition to DefaultStripFields,
+// e.g. "metadata.generation" or "metadata.annotations".
+func WithStripFields(paths ...string) Option {
+	return func(c *config) {
+		c.strip = append(c.strip, paths...)
+	}
+}
+
+// WithKeepFields keeps the given DefaultStripFields, e.g. "status" when the
+// status is what the test asserts.
+func WithKeepFields(paths ...string) Option {
+	return func(c *config) {
+		c.strip = slices.DeleteFunc(c.strip, func(path string) bool {
+			return slices.Contains(paths, path)
+		})
+	}
+}
+
+// Normalize returns the JSON form of obj with the server-populated fields
+// of every object in it stripped, including list items and pod templates,
+// and with lists of named elements, such as containers or list items, sorted
+// by namespace and name.
+func Normalize(obj any, opts ...Option) (any, error) {
+	c := &config{strip: slices.Clone(DefaultStripFields)}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %w", obj, err)
+	}
+
+	value, err := comparator.DecodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %w", obj, err)
+	}
+
+	return c.normalize(value), nil
+}
+
+// Assert compares obj, normalized, with the golden file name of g as YAML.
+func Assert(tb testing.TB, g *golden.Golden, name string, obj any, opts ...Option) {
+	tb.Helper()
+
+	value, err := Normalize(obj, opts...)
+	if err != nil {
+		tb.Fatalf("Failed to normalize %s: %v", name, err)
+	}
+
+	g.AssertFormat(name, golden.YAML, value)
+}
+
+// normalize strips and sorts value recursively.
+func (c *config) normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if _, ok := v["metadata"].(map[string]any); ok {
+			for _, path := range c.strip {
+				deletePath(v, strings.Split(path, "."))
+			}
+		}
+
+		for key, child := range v {
+			v[key] = c.normalize(child)
+		}
+
+		return v
+	case []any:
+		for i, child := range v {
+			v[i] = c.normalize(child)
+		}
+
+		sortNamed(v)
+
+		return v
+	default:
+		return value
+	}
+}
+
+// deletePath removes the field at path from m, along with maps it leaves
+// empty.
+func deletePath(m map[string]any, path []string) {
+	if len(path) == 1 {
+		delete(m, path[0])
+
+		return
+	}
+
+	child, ok := m[path[0]].(map[string]any)
+	if !ok {
+		return
+	}
+
+	deletePath(child, path[1:])
+
+	if len(child) == 0 {
+		delete(m, path[0])
+	}
+}
+
+// sortNamed sorts list by name if all of its elements are named.
+func sortNamed(list []any) {
+	for _, elem := range list {
+		if _, ok := nameKey(elem); !ok {
+			return
+		}
+	}
+
+	slices.SortStableFunc(list, func(a, b any) int {
+		keyA, _ := nameKey(a)
+		keyB, _ := nameKey(b)
+
+		return strings.Compare(keyA, keyB)
+	})
+}
+
+// nameKey returns the sort key of a named element: the namespace and name
+// of objects, and the name of other elements such as containers.
+func nameKey(elem any) (string, bool) {
+	m, ok := elem.(map[string]any)
+	if !ok {
+		return "", false
+	}
+
+	if meta, ok := m["metadata"].(map[string]any); ok {
+		name, ok := meta["name"].(string)
+		namespace, _ := meta["namespace"].(string)
+
+		return namespace + "/" + name, ok
+	}
+
+	name, ok := m["name"].(string)
+
+	return name, ok
+}
@@ -0,0 +1,88 @@
+package k8sgolden
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sivchari/golden"
+)
+
+// objectMeta mirrors the JSON form of metav1.ObjectMeta.
+type objectMeta struct {
+	Name              string           `json:"name"`
+	Namespace         string           `json:"namespace,omitempty"`
+	UID               string           `json:"uid,omitempty"`
+	ResourceVersion   string           `json:"resourceVersion,omitempty"`
+	CreationTimestamp *string          `json:"creationTimestamp"`
+	ManagedFields     []map[string]any `json:"managedFields,omitempty"`
+}
+
+// pod mirrors the JSON form of a corev1.Pod.
+type pod struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   objectMeta `json:"metadata"`
+	Spec       struct {
+		Containers []map[string]any `json:"containers"`
+	} `json:"spec"`
+	Status map[string]any `json:"status"`
+}
+
+func TestNormalizeStripsServerFields(t *testing.T) {
+	t.Parallel()
+
+	created := "2024-01-01T00:00:00Z"
+	p := pod{APIVersion: "v1", Kind: "Pod", Status: map[string]any{"phase": "Running"}}
+	p.Metadata = objectMeta{
+		Name: "web", Namespace: "default", UID: "8f0e", ResourceVersion: "42",
+		CreationTimestamp: &created, ManagedFields: []map[string]any{{"manager": "kubectl"}},
+	}
+	p.Spec.Containers = []map[string]any{{"name": "sidecar"}, {"name": "app", "image": "app:v1"}}
+
+	dir := t.TempDir()
+	g := golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false), golden.WithBaseDir(dir))
+	Assert(t, g, "pod", p)
+
+	data, err := os.ReadFile(filepath.Join(dir, "k8sgolden_test_TestNormalizeStripsServerFields_pod.golden.go"))
+	want := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\n  namespace: default\nspec:\n  containers:\n    - image: app:v1\n      name: app\n    - name: sidecar\n"
+
+	if err != nil || string(data) != want {
+		t.Errorf("golden file = %q, %v, want %q", data, err, want)
+	}
+
+	p.Metadata.ResourceVersion = "43"
+	Assert(t, golden.New(t, golden.WithBaseDir(dir)), "pod", p)
+}
+
+func TestNormalizeList(t *testing.T) {
+	t.Parallel()
+
+	list := map[string]any{
+		"kind": "ConfigMapList",
+		"items": []any{
+			map[string]any{"metadata": map[string]any{"name": "b", "namespace": "x", "uid": "1"}},
+			map[string]any{"metadata": map[string]any{"name": "a", "namespace": "x", "uid": "2"}, "status": map[string]any{}},
+		},
+	}
+
+	value, err := Normalize(list, WithStripFields("metadata.namespace"), WithKeepFields("status"))
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	items := value.(map[string]any)["items"].([]any)
+	first := items[0].(map[string]any)
+
+	if name := first["metadata"].(map[string]any)["name"]; name != "a" || len(items) != 2 {
+		t.Errorf("items = %v, want items sorted by name", items)
+	}
+
+	if _, ok := first["status"]; !ok {
+		t.Errorf("items[0] = %v, want the status kept", first)
+	}
+
+	if meta := first["metadata"].(map[string]any); len(meta) != 1 {
+		t.Errorf("items[0].metadata = %v, want only the name", meta)
+	}
+}
@@ -0,0 +1,107 @@
+package golden
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// LogCapture buffers log output for golden assertions. It is an io.Writer,
+// so it can back any logger, and provides slog and log package loggers
+// writing to it. Timestamps, goroutine IDs and durations are scrubbed from
+// the captured output.
+type LogCapture struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	tb  testing.TB
+}
+
+// CaptureLogs returns a LogCapture for the test tb.
+func CaptureLogs(tb testing.TB) *LogCapture {
+	return &LogCapture{tb: tb}
+}
+
+// Write appends p to the captured output. It is safe for concurrent use.
+func (c *LogCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.buf.Write(p) //nolint:wrapcheck // bytes.Buffer.Write never fails
+}
+
+// Handler returns a slog text handler writing to c. opts may be nil.
+func (c *LogCapture) Handler(opts *slog.HandlerOptions) slog.Handler {
+	return slog.NewTextHandler(c, opts)
+}
+
+// JSONHandler returns a slog JSON handler writing to c. opts may be nil.
+func (c *LogCapture) JSONHandler(opts *slog.HandlerOptions) slog.Handler {
+	return slog.NewJSONHandler(c, opts)
+}
+
+// Logger returns a slog logger writing text records to c.
+func (c *LogCapture) Logger() *slog.Logger {
+	return slog.New(c.Handler(nil))
+}
+
+// StdLogger returns a log package logger writing to c with the standard
+// flags.
+func (c *LogCapture) StdLogger() *log.Logger {
+	return log.New(c, "", log.LstdFlags)
+}
+
+// SetDefault makes c the output of the default slog logger and of the log
+// package until the test ends. It must not be used in parallel tests.
+func (c *LogCapture) SetDefault() {
+	logger := slog.Default()
+	writer, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+
+	slog.SetDefault(c.Logger())
+	log.SetOutput(c)
+	log.SetFlags(log.LstdFlags)
+	log.SetPrefix("")
+
+	c.tb.Cleanup(func() {
+		slog.SetDefault(logger)
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	})
+}
+
+// Bytes returns the captured output with timestamps, goroutine IDs and
+// durations scrubbed.
+func (c *LogCapture) Bytes() []byte {
+	c.mu.Lock()
+	data := bytes.Clone(c.buf.Bytes())
+	c.mu.Unlock()
+
+	for _, scrubber := range []Scrubber{ScrubTimestamps(), ScrubGoroutineIDs(), ScrubDurations()} {
+		data = scrubber(data)
+	}
+
+	return data
+}
+
+// String returns the scrubbed captured output.
+func (c *LogCapture) String() string {
+	return string(c.Bytes())
+}
+
+// Reset discards the captured output.
+func (c *LogCapture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf.Reset()
+}
+
+// AssertLogs compares the scrubbed output captured by logs with the golden
+// file name.
+func (g *Golden) AssertLogs(name string, logs *LogCapture) {
+	g.t.Helper()
+
+	g.assertBytes(name, logs.Bytes())
+}
@@ -0,0 +1,180 @@
+package manager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// BlobDir is the directory under baseDir holding content-addressed blobs.
+	BlobDir = "blobs"
+	// blobRefPrefix starts the reference files that stand in for blobs.
+	blobRefPrefix = "golden-blob sha256:"
+	// maxBlobRefSize bounds the size of reference files, compressed or not.
+	maxBlobRefSize = 512
+)
+
+// ErrBlobCorrupt is returned when a blob does not match its reference.
+var ErrBlobCorrupt = errors.New("blob does not match its reference")
+
+// blobRef is a parsed reference file.
+type blobRef struct {
+	hash string
+	size int
+}
+
+// String formats the reference file content.
+func (r blobRef) String() string {
+	return fmt.Sprintf("%s%s size:%d\n", blobRefPrefix, r.hash, r.size)
+}
+
+// parseBlobRef parses data as a reference file.
+func parseBlobRef(data []byte) (blobRef, bool) {
+	line, ok := bytes.CutPrefix(data, []byte(blobRefPrefix))
+	if !ok || bytes.Count(line, []byte("\n")) != 1 || !bytes.HasSuffix(line, []byte("\n")) {
+		return blobRef{}, false
+	}
+
+	hash, size, ok := strings.Cut(strings.TrimSuffix(string(line), "\n"), " size:")
+	if !ok || len(hash) != sha256.Size*2 {
+		return blobRef{}, false
+	}
+
+	if _, err := hex.DecodeString(hash); err != nil {
+		return blobRef{}, false
+	}
+
+	n, err := strconv.Atoi(size)
+	if err != nil || n < 0 {
+		return blobRef{}, false
+	}
+
+	return blobRef{hash: hash, size: n}, true
+}
+
+// WriteBlob stores data in BlobDir under its sha256 and writes a small
+// reference to it to filename, keeping large content out of normal diffs.
+func (m *Manager) WriteBlob(filename string, data []byte) error {
+	sum := sha256.Sum256(data)
+	ref := blobRef{hash: hex.EncodeToString(sum[:]), size: len(data)}
+
+	if err := m.WriteFile(m.blobFilename(ref.hash), data); err != nil {
+		return err
+	}
+
+	return m.WriteFile(filename, []byte(ref.String()))
+}
+
+// ResolveBlob returns the blob content if data is a reference file, and data
+// itself otherwise.
+func (m *Manager) ResolveBlob(data []byte) ([]byte, error) {
+	ref, ok := parseBlobRef(data)
+	if !ok {
+		return data, nil
+	}
+
+	blob, err := m.ReadFile(m.blobFilename(ref.hash))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(blob)
+	if hex.EncodeToString(sum[:]) != ref.hash || len(blob) != ref.size {
+		return nil, fmt.Errorf("%w: sha256:%s", ErrBlobCorrupt, ref.hash)
+	}
+
+	return blob, nil
+}
+
+// CollectBlobs removes the blobs no golden file under baseDir refers to and
+// returns their hashes.
+func (m *Manager) CollectBlobs() ([]string, error) {
+	referenced, err := m.blobRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(m.baseDir, BlobDir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read blob directory %s: %w", dir, err)
+	}
+
+	var removed []string
+
+	for _, entry := range entries {
+		if entry.IsDir() || referenced[entry.Name()] {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove blob %s: %w", entry.Name(), err)
+		}
+
+		removed = append(removed, entry.Name())
+	}
+
+	sort.Strings(removed)
+
+	return removed, nil
+}
+
+// blobRefs returns the hashes referenced by the files under baseDir.
+func (m *Manager) blobRefs() (map[string]bool, error) {
+	referenced := make(map[string]bool)
+
+	err := filepath.WalkDir(m.baseDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if path == filepath.Join(m.baseDir, BlobDir) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		// Reference files are small, whatever the naming strategy
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxBlobRefSize {
+			return err
+		}
+
+		data, err := m.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if ref, ok := parseBlobRef(data); ok {
+			referenced[ref.hash] = true
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to scan golden files in %s: %w", m.baseDir, err)
+	}
+
+	return referenced, nil
+}
+
+// blobFilename returns the path of the blob with hash.
+func (m *Manager) blobFilename(hash string) string {
+	return filepath.Join(m.baseDir, BlobDir, hash)
+}
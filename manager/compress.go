@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+const (
+	// goldenExtension is the extension of uncompressed golden files.
+	goldenExtension = ".golden.go"
+	// compressedExtension is the extension of gzipped golden files.
+	compressedExtension = ".golden.gz"
+)
+
+// SetCompression controls whether golden files are gzipped, which keeps
+// large golden files small in the repository. Compressed golden files are
+// named *.golden.gz and are read transparently even when compression is
+// disabled.
+func (m *Manager) SetCompression(enabled bool) {
+	m.compress = enabled
+}
+
+// compressedName returns the compressed counterpart of filename, if
+// compression is enabled or only the compressed file exists.
+func (m *Manager) compressedName(filename string) string {
+	if !strings.HasSuffix(filename, goldenExtension) {
+		return filename
+	}
+
+	compressed := strings.TrimSuffix(filename, goldenExtension) + compressedExtension
+	if m.compress {
+		return compressed
+	}
+
+	if _, err := m.stat(filename); errors.Is(err, fs.ErrNotExist) {
+		if _, err := m.stat(compressed); err == nil {
+			return compressed
+		}
+	}
+
+	return filename
+}
+
+// isCompressed reports whether filename holds gzipped content.
+func isCompressed(filename string) bool {
+	return strings.HasSuffix(filename, ".gz")
+}
+
+// compress gzips data. The header carries no timestamp, so that unchanged
+// content yields identical files.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress gunzips data.
+func decompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+
+	return out, nil
+}
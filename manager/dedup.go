@@ -0,0 +1,209 @@
+package manager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// SharedDir is the directory under baseDir holding deduplicated goldens.
+	SharedDir = "shared"
+	// MappingFile maps golden names to the content hash of their shared file.
+	MappingFile = "golden.map.json"
+)
+
+// ErrSharedCollision is returned when a shared golden file holds other
+// content than the content hashing to its name.
+var ErrSharedCollision = errors.New("shared golden file collision")
+
+// mappingMu serializes read-modify-write cycles on mapping files, which are
+// shared between all Manager instances in the process.
+var mappingMu sync.Mutex
+
+// ContentHash returns the hash used to name shared golden files.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// WriteShared stores data in a content-addressed shared golden file and maps
+// filename to it. Shared files no longer referenced by the mapping are
+// removed. It returns the other golden names sharing the same content.
+func (m *Manager) WriteShared(filename string, data []byte) ([]string, error) {
+	hash := ContentHash(data)
+
+	key, err := m.mappingKey(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Shared files are written under the lock, so that they are not
+	// collected before being mapped
+	mappingMu.Lock()
+	defer mappingMu.Unlock()
+
+	if err := m.writeSharedFile(hash, data); err != nil {
+		return nil, err
+	}
+
+	mapping, err := m.readMapping()
+	if err != nil {
+		return nil, err
+	}
+
+	mapping[key] = hash
+
+	if err := m.writeMapping(mapping); err != nil {
+		return nil, err
+	}
+
+	if err := m.collectShared(mapping); err != nil {
+		return nil, err
+	}
+
+	var shared []string
+
+	for name, h := range mapping {
+		if h == hash && name != key {
+			shared = append(shared, name)
+		}
+	}
+
+	sort.Strings(shared)
+
+	return shared, nil
+}
+
+// writeSharedFile stores data as the shared file for hash, failing if the
+// file exists with other content, as the truncated hash may collide.
+func (m *Manager) writeSharedFile(hash string, data []byte) error {
+	name := m.sharedFilename(hash)
+
+	current, err := m.ReadFile(name)
+
+	switch {
+	case err == nil && !bytes.Equal(current, data):
+		return fmt.Errorf("%w: %s holds other content with the same hash", ErrSharedCollision, name)
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	return m.WriteFile(name, data)
+}
+
+// collectShared removes the shared files not referenced by mapping.
+func (m *Manager) collectShared(mapping map[string]string) error {
+	referenced := make(map[string]bool, len(mapping))
+	for _, hash := range mapping {
+		referenced[hash+".golden.go"] = true
+	}
+
+	dir := filepath.Join(m.baseDir, SharedDir)
+
+	entries, err := m.readDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read shared directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || referenced[entry.Name()] || !strings.HasSuffix(entry.Name(), ".golden.go") {
+			continue
+		}
+
+		name := filepath.Join(dir, entry.Name())
+
+		unlock := m.lockFile(name, true)
+		err := m.removeFile(name)
+
+		unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadShared reads the golden content for filename through the mapping file,
+// falling back to filename itself when it has no mapping entry.
+func (m *Manager) ReadShared(filename string) ([]byte, error) {
+	key, err := m.mappingKey(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	mappingMu.Lock()
+	mapping, err := m.readMapping()
+	mappingMu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if hash, ok := mapping[key]; ok {
+		return m.ReadFile(m.sharedFilename(hash))
+	}
+
+	return m.ReadFile(filename)
+}
+
+// sharedFilename returns the path of the shared golden file for hash.
+func (m *Manager) sharedFilename(hash string) string {
+	return filepath.Join(m.baseDir, SharedDir, hash+".golden.go")
+}
+
+// mappingKey returns the name under which filename is stored in the mapping.
+func (m *Manager) mappingKey(filename string) (string, error) {
+	key, err := filepath.Rel(m.baseDir, filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s relative to %s: %w", filename, m.baseDir, err)
+	}
+
+	return filepath.ToSlash(key), nil
+}
+
+// readMapping loads the mapping file, returning an empty mapping if it does not exist.
+func (m *Manager) readMapping() (map[string]string, error) {
+	mapping := make(map[string]string)
+
+	data, err := m.ReadFile(filepath.Join(m.baseDir, MappingFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return mapping, nil
+		}
+
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping file: %w", err)
+	}
+
+	return mapping, nil
+}
+
+// writeMapping stores the mapping file with sorted keys.
+func (m *Manager) writeMapping(mapping map[string]string) error {
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode mapping file: %w", err)
+	}
+
+	return m.WriteFile(filepath.Join(m.baseDir, MappingFile), append(data, '\n'))
+}
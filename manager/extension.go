@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SetExtensions controls whether golden files are named with an extension
+// matching their content, e.g. .golden.json or .golden.yaml, so that
+// editors and code review tools highlight them. Existing .golden.go files
+// keep being used.
+func (m *Manager) SetExtensions(enabled bool) {
+	m.extensions = enabled
+}
+
+// GetFilenameExt returns the path of the golden file goldenName holding
+// content with the extension ext, such as "json". The legacy .golden.go
+// path is returned if that file exists, if extensions are disabled, or if
+// compression or a custom naming strategy names the file.
+func (m *Manager) GetFilenameExt(goldenName, ext string) string {
+	filename := m.GetFilename(goldenName)
+	if !m.extensions || ext == "" || !strings.HasSuffix(filename, goldenExtension) || m.exists(filename) {
+		return filename
+	}
+
+	return strings.TrimSuffix(filename, goldenExtension) + ".golden." + ext
+}
+
+// existingExtension returns the golden file with the legacy .golden.go path
+// filename under any content extension, or filename if there is none.
+func (m *Manager) existingExtension(filename string) string {
+	if !m.extensions || !strings.HasSuffix(filename, goldenExtension) || m.exists(filename) {
+		return filename
+	}
+
+	dir, base := filepath.Split(strings.TrimSuffix(filename, goldenExtension))
+
+	entries, err := m.readDir(dir)
+	if err != nil {
+		return filename
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, base+".golden.") && !strings.HasSuffix(name, ".tmp") {
+			return filepath.Join(dir, name)
+		}
+	}
+
+	return filename
+}
+
+// exists reports whether filename exists.
+func (m *Manager) exists(filename string) bool {
+	_, err := m.stat(filename)
+
+	return err == nil
+}
@@ -0,0 +1,211 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// CompressionThreshold is the size in bytes above which an uncompressed
+	// golden file is considered for compression.
+	CompressionThreshold = 64 * 1024
+	// compressionGain is the minimum ratio of uncompressed to compressed
+	// size making a golden file a compression candidate.
+	compressionGain = 2
+)
+
+// FileSize is the size of a golden file.
+type FileSize struct {
+	Path           string // Path of the file, including the base directory
+	Size           int64  // Size on disk in bytes
+	CompressedSize int64  // Size once gzipped, only set for compression candidates
+}
+
+// Footprint summarizes the golden files under a base directory, including
+// blobs and shared files.
+type Footprint struct {
+	Dir   string     // Base directory
+	Size  int64      // Total size in bytes
+	Files []FileSize // Golden files, largest first
+
+	// Uncompressed golden files larger than CompressionThreshold that gzip
+	// would at least halve, largest first
+	CompressionCandidates []FileSize
+}
+
+// Largest returns the n largest golden files of the footprint.
+func (f *Footprint) Largest(n int) []FileSize {
+	return f.Files[:max(0, min(n, len(f.Files)))]
+}
+
+// Measure returns the footprint of the golden files under dir.
+func Measure(dir string) (*Footprint, error) {
+	footprint := &Footprint{Dir: dir}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !isGoldenFile(dir, path) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+
+		file := FileSize{Path: path, Size: info.Size()}
+		footprint.Files = append(footprint.Files, file)
+		footprint.Size += file.Size
+
+		if file.Size > CompressionThreshold && !isCompressed(path) {
+			return footprint.checkCompression(file)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to measure golden files in %s: %w", dir, err)
+	}
+
+	bySize := func(files []FileSize) {
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].Size > files[j].Size
+		})
+	}
+
+	bySize(footprint.Files)
+	bySize(footprint.CompressionCandidates)
+
+	return footprint, nil
+}
+
+// checkCompression adds file to the compression candidates if gzip shrinks
+// it enough.
+func (f *Footprint) checkCompression(file FileSize) error {
+	data, err := os.ReadFile(file.Path) //nolint:gosec // G304: Measuring the golden files is the point
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file.Path, err)
+	}
+
+	compressed, err := compress(data)
+	if err != nil {
+		return err
+	}
+
+	if file.CompressedSize = int64(len(compressed)); file.CompressedSize*compressionGain <= file.Size {
+		f.CompressionCandidates = append(f.CompressionCandidates, file)
+	}
+
+	return nil
+}
+
+// isGoldenFile reports whether path, found under dir, is a golden file,
+// a blob or a shared file, as opposed to other test data or bookkeeping
+// files.
+func isGoldenFile(dir, path string) bool {
+	if strings.HasSuffix(path, ".tmp") || strings.HasSuffix(path, ImmutableSuffix) {
+		return false
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	top, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+
+	return top == BlobDir || top == SharedDir || strings.Contains(filepath.Base(path), ".golden.")
+}
+
+// MeasureTree returns the footprint of every testdata directory under root,
+// i.e. of the golden files of every package, skipping hidden and vendor
+// directories. Directories without golden files are left out.
+func MeasureTree(root string) ([]*Footprint, error) {
+	var footprints []*Footprint
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		name := entry.Name()
+		if path != root && (name == "vendor" || strings.HasPrefix(name, ".")) {
+			return fs.SkipDir
+		}
+
+		if name != "testdata" {
+			return nil
+		}
+
+		footprint, err := Measure(path)
+		if err != nil {
+			return err
+		}
+
+		if len(footprint.Files) > 0 {
+			footprints = append(footprints, footprint)
+		}
+
+		// Nested testdata directories are part of this one
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure golden files under %s: %w", root, err)
+	}
+
+	return footprints, nil
+}
+
+// WriteFootprints writes a report of footprints to w, listing the top
+// largest files and the compression candidates of each.
+func WriteFootprints(w io.Writer, footprints []*Footprint, top int) error {
+	var b strings.Builder
+
+	for _, f := range footprints {
+		fmt.Fprintf(&b, "%s: %d file(s), %s\n", f.Dir, len(f.Files), formatSize(f.Size))
+
+		for _, file := range f.Largest(top) {
+			fmt.Fprintf(&b, "  %10s  %s\n", formatSize(file.Size), file.Path)
+		}
+
+		for _, file := range f.CompressionCandidates {
+			fmt.Fprintf(&b, "  compression candidate: %s (%s -> %s gzipped)\n", file.Path, formatSize(file.Size), formatSize(file.CompressedSize))
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write footprint report: %w", err)
+	}
+
+	return nil
+}
+
+// formatSize formats size in bytes with a binary unit.
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value, exp := float64(size)/unit, 0
+	for value >= unit && exp < 2 {
+		value /= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", value, "KMG"[exp])
+}
@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetFS makes the manager read golden files from fsys instead of the local
+// disk, e.g. from an embed.FS holding the testdata directory. fsys is rooted
+// at the package directory, as embedded files are, so that the golden file
+// testdata/x.golden.go is read from fsys as testdata/x.golden.go. Writes
+// still go to the local disk.
+func (m *Manager) SetFS(fsys fs.FS) {
+	m.fsys = fsys
+}
+
+// fsPath returns the name of filename in the manager's fs.FS. Absolute
+// paths are made relative to the root of the file system.
+func fsPath(filename string) (string, error) {
+	name := filepath.ToSlash(filepath.Clean(filename))
+	if filepath.IsAbs(filename) {
+		name = strings.TrimLeft(filepath.ToSlash(strings.TrimPrefix(filepath.Clean(filename), filepath.VolumeName(filename))), "/")
+	}
+
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "open", Path: filename, Err: fs.ErrInvalid}
+	}
+
+	return name, nil
+}
+
+// readFS reads filename from the manager's fs.FS.
+func (m *Manager) readFS(filename string) ([]byte, error) {
+	if err := m.checkPath(filename); err != nil {
+		return nil, err
+	}
+
+	name, err := fsPath(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden file %s: %w", filename, err)
+	}
+
+	data, err := fs.ReadFile(m.fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden file %s: %w", filename, err)
+	}
+
+	return data, nil
+}
+
+// stat returns the file info of filename, from the manager's fs.FS if set.
+func (m *Manager) stat(filename string) (fs.FileInfo, error) {
+	if m.fsys == nil {
+		return os.Lstat(filename) //nolint:wrapcheck // Callers only check for existence
+	}
+
+	name, err := fsPath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.Stat(m.fsys, name) //nolint:wrapcheck // Callers only check for existence
+}
+
+// readDir lists dir, from the manager's fs.FS if set.
+func (m *Manager) readDir(dir string) ([]fs.DirEntry, error) {
+	if m.fsys == nil {
+		return os.ReadDir(dir) //nolint:wrapcheck // Callers ignore unreadable directories
+	}
+
+	name, err := fsPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.ReadDir(m.fsys, name) //nolint:wrapcheck // Callers ignore unreadable directories
+}
@@ -0,0 +1,71 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// headerStart and headerEnd delimit the metadata header of golden files.
+	headerStart = "# golden-header\n"
+	headerEnd   = "# end golden-header\n"
+)
+
+// Header is the metadata optionally written at the top of golden files,
+// recording where they come from.
+type Header struct {
+	Library string    // Module path and version of the library that wrote the file
+	Test    string    // Name of the test owning the file
+	Created time.Time // Creation time of the file
+	Format  string    // Format of the content, e.g. json or txt
+}
+
+// Bytes formats the header, including its delimiters.
+func (h Header) Bytes() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(headerStart)
+	fmt.Fprintf(&buf, "# library: %s\n", h.Library)
+	fmt.Fprintf(&buf, "# test: %s\n", h.Test)
+	fmt.Fprintf(&buf, "# created: %s\n", h.Created.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "# format: %s\n", h.Format)
+	buf.WriteString(headerEnd)
+
+	return buf.Bytes()
+}
+
+// ParseHeader splits golden file content into its metadata header and the
+// content itself. It reports false, returning data unchanged, if data has no
+// header. Unknown header fields are ignored.
+func ParseHeader(data []byte) (Header, []byte, bool) {
+	rest, ok := bytes.CutPrefix(data, []byte(headerStart))
+	if !ok {
+		return Header{}, data, false
+	}
+
+	fields, body, ok := bytes.Cut(rest, []byte(headerEnd))
+	if !ok {
+		return Header{}, data, false
+	}
+
+	var header Header
+
+	for _, line := range strings.Split(strings.TrimSuffix(string(fields), "\n"), "\n") {
+		key, value, _ := strings.Cut(strings.TrimPrefix(line, "# "), ": ")
+
+		switch key {
+		case "library":
+			header.Library = value
+		case "test":
+			header.Test = value
+		case "created":
+			header.Created, _ = time.Parse(time.RFC3339, value)
+		case "format":
+			header.Format = value
+		}
+	}
+
+	return header, body, true
+}
@@ -0,0 +1,42 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ImmutableSuffix is appended to a golden file name to form its sidecar
+// attribute file. A golden with a sidecar is immutable and must not be
+// rewritten by update mode without an explicit override.
+const ImmutableSuffix = ".immutable"
+
+// IsImmutable reports whether filename is marked immutable. The sidecar is
+// looked up where golden files are read from.
+func (m *Manager) IsImmutable(filename string) (bool, error) {
+	_, err := m.stat(filename + ImmutableSuffix)
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("failed to check immutability of %s: %w", filename, err)
+}
+
+// SetImmutable marks or unmarks filename as immutable by creating or
+// removing its sidecar attribute file.
+func (m *Manager) SetImmutable(filename string, immutable bool) error {
+	sidecar := filename + ImmutableSuffix
+
+	if !immutable {
+		unlock := m.lockFile(sidecar, true)
+		defer unlock()
+
+		return m.removeFile(sidecar)
+	}
+
+	return m.WriteFile(sidecar, []byte("This golden file is immutable and may only change through review.\n"))
+}
@@ -0,0 +1,112 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// listMarker stands in for the golden name when List derives the filename
+// prefix of the manager's test.
+const listMarker = "golden-list-marker"
+
+// GoldenFile is a golden file found under the base directory.
+type GoldenFile struct {
+	Path       string // Path of the file, including the base directory
+	TestFile   string // Components parsed by the naming strategy
+	TestFunc   string
+	GoldenName string
+}
+
+// List returns the golden files of the manager's test, including those of
+// its subtests, in lexical order. Files are matched by the filename prefix
+// the naming strategy gives the test, so with DefaultNaming the files of a
+// test named like the test followed by an underscore are returned as well.
+func (m *Manager) List() ([]GoldenFile, error) {
+	generated := filepath.ToSlash(m.naming.GenerateFilename(m.testFile, m.testFunc, listMarker))
+	prefix, _, _ := strings.Cut(generated, listMarker)
+
+	return m.find(func(rel string) bool {
+		return strings.HasPrefix(rel, prefix)
+	})
+}
+
+// Glob returns the golden files whose path relative to the base directory,
+// with forward slashes, matches pattern, in lexical order. The pattern
+// syntax is that of path.Match, e.g. "*_TestAPI_*" or "TestAPI/*/*".
+func (m *Manager) Glob(pattern string) ([]GoldenFile, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+
+	return m.find(func(rel string) bool {
+		matched, _ := path.Match(pattern, rel)
+
+		return matched
+	})
+}
+
+// find returns the golden files under baseDir whose slash separated path
+// relative to baseDir satisfies match and which the naming strategy can
+// parse. Blobs, shared files and other bookkeeping files are skipped.
+func (m *Manager) find(match func(rel string) bool) ([]GoldenFile, error) {
+	fsys, root := os.DirFS(m.baseDir), "."
+	if m.fsys != nil {
+		name, err := fsPath(m.baseDir)
+		if err != nil {
+			return nil, err
+		}
+
+		fsys, root = m.fsys, name
+	}
+
+	var files []GoldenFile
+
+	err := fs.WalkDir(fsys, root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel := name
+		if root != "." {
+			rel = strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		}
+
+		if entry.IsDir() {
+			if rel == BlobDir || rel == SharedDir {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !strings.Contains(rel, ".golden.") || strings.HasSuffix(rel, ".tmp") || strings.HasSuffix(rel, ImmutableSuffix) || !match(rel) {
+			return nil
+		}
+
+		filename := filepath.FromSlash(rel)
+
+		testFile, testFunc, goldenName, err := m.naming.ParseFilename(filename)
+		if err != nil {
+			return nil //nolint:nilerr // Files the naming strategy does not recognize are not listed
+		}
+
+		files = append(files, GoldenFile{
+			Path:       filepath.Join(m.baseDir, filename),
+			TestFile:   testFile,
+			TestFunc:   testFunc,
+			GoldenName: goldenName,
+		})
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list golden files in %s: %w", m.baseDir, err)
+	}
+
+	return files, nil
+}
@@ -2,9 +2,13 @@
 package manager
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 )
@@ -18,9 +22,48 @@ type Manager struct {
 	// File naming strategy
 	naming NamingStrategy
 
+	// Refuse paths traversing symlinks, or pointing outside of baseDir and
+	// symlinkRoots
+	noFollowSymlinks bool
+	symlinkRoots     []string
+
+	// Gzip golden files
+	compress bool
+
+	// Name golden files after their content type
+	extensions bool
+
+	// Read golden files from fsys instead of the local disk, and write
+	// them to memory if set
+	fsys   fs.FS
+	memory *MemoryFS
+
+	// Fetch golden files missing locally from remoteURL, caching them in
+	// remoteCache
+	remoteURL, remoteCache string
+
+	// Platform specificity of new golden files, and the current platform
+	platform     Platform
+	goos, goarch string
+
+	// Size limit of golden files, disabled if zero
+	maxFileSize int64
+
+	// Counts of written and unchanged golden files
+	statsMu            sync.Mutex
+	updated, unchanged int
+
 	// Thread safety
 	mu    sync.RWMutex
-	locks map[string]*sync.RWMutex
+	locks map[string]*fileLock
+}
+
+// fileLock is the lock of a golden file, counting its holders and waiters
+// so that it can be dropped once unused.
+type fileLock struct {
+	sync.RWMutex
+
+	refs int
 }
 
 // NamingStrategy defines how golden files are named.
@@ -36,7 +79,9 @@ func New(baseDir, testFile, testFunc string) *Manager {
 		testFile: testFile,
 		testFunc: testFunc,
 		naming:   &DefaultNaming{},
-		locks:    make(map[string]*sync.RWMutex),
+		goos:     runtime.GOOS,
+		goarch:   runtime.GOARCH,
+		locks:    make(map[string]*fileLock),
 	}
 }
 
@@ -44,7 +89,14 @@ func New(baseDir, testFile, testFunc string) *Manager {
 func (m *Manager) GetFilename(goldenName string) string {
 	filename := m.naming.GenerateFilename(m.testFile, m.testFunc, goldenName)
 
-	return filepath.Join(m.baseDir, filename)
+	return m.platformFilename(filepath.Join(m.baseDir, filename))
+}
+
+// SetNamingStrategy sets how golden files are named, DefaultNaming by
+// default. Filenames generated by s may contain directories, which are
+// created under the base directory when golden files are written.
+func (m *Manager) SetNamingStrategy(s NamingStrategy) {
+	m.naming = s
 }
 
 // ReadFile reads a golden file.
@@ -52,7 +104,62 @@ func (m *Manager) ReadFile(filename string) ([]byte, error) {
 	unlock := m.lockFile(filename, false)
 	defer unlock()
 
-	data, err := os.ReadFile(filename) //nolint:gosec // G304: File reading is necessary for golden file functionality
+	return m.readFile(filename)
+}
+
+// readFile reads a golden file, which the caller has locked.
+func (m *Manager) readFile(filename string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if m.fsys != nil {
+		data, err = m.readFS(filename)
+	} else {
+		data, err = m.readDisk(filename)
+	}
+
+	if errors.Is(err, fs.ErrNotExist) && m.remoteURL != "" {
+		data, err = m.fetchRemote(filename)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.checkSize(filename, int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	if isCompressed(filename) {
+		if data, err = decompress(data); err != nil {
+			return nil, err
+		}
+
+		if err := m.checkSize(filename, int64(len(data))); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// readDisk reads filename from the local disk, following symlinks.
+func (m *Manager) readDisk(filename string) ([]byte, error) {
+	path, err := m.resolvePath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse oversized files before loading them into memory
+	if info, err := os.Stat(path); err == nil {
+		if err := m.checkSize(filename, info.Size()); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: File reading is necessary for golden file functionality
 	if err != nil {
 		return nil, fmt.Errorf("failed to read golden file %s: %w", filename, err)
 	}
@@ -65,50 +172,130 @@ func (m *Manager) WriteFile(filename string, data []byte) error {
 	unlock := m.lockFile(filename, true)
 	defer unlock()
 
+	return m.writeFile(filename, data)
+}
+
+// writeFile writes data to a golden file, which the caller has locked.
+func (m *Manager) writeFile(filename string, data []byte) error {
+	if err := m.checkSize(filename, int64(len(data))); err != nil {
+		return err
+	}
+
+	if isCompressed(filename) {
+		var err error
+		if data, err = compress(data); err != nil {
+			return err
+		}
+	}
+
+	if m.memory != nil {
+		return m.writeMemory(filename, data)
+	}
+
+	// Write through symlinks to their target
+	target, err := m.resolvePath(filename)
+	if err != nil {
+		return err
+	}
+
+	// Leave unchanged files untouched, so that their modification time does
+	// not trigger build caches and file watchers
+	if current, err := os.ReadFile(target); err == nil && bytes.Equal(current, data) { //nolint:gosec // G304: File reading is necessary for golden file functionality
+		m.recordWrite(false)
+
+		return nil
+	}
+
 	// Ensure directory exists
-	dir := filepath.Dir(filename)
+	dir := filepath.Dir(target)
 	if err := os.MkdirAll(dir, 0o750); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
 	// Write to temporary file first for atomic operation
-	tmpFile := filename + ".tmp"
+	tmpFile := target + ".tmp"
 	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
 		return fmt.Errorf("failed to write temporary file %s: %w", tmpFile, err)
 	}
 
 	// Atomically move temporary file to final location
-	if err := os.Rename(tmpFile, filename); err != nil {
+	if err := os.Rename(tmpFile, target); err != nil {
 		_ = os.Remove(tmpFile) // Clean up on failure, ignore error
 
-		return fmt.Errorf("failed to rename %s to %s: %w", tmpFile, filename, err)
+		return fmt.Errorf("failed to rename %s to %s: %w", tmpFile, target, err)
+	}
+
+	m.recordWrite(true)
+
+	return nil
+}
+
+// removeFile removes filename, which the caller has locked, from where
+// golden files are written. Removing a missing file is not an error.
+func (m *Manager) removeFile(filename string) error {
+	if err := m.checkPath(filename); err != nil {
+		return err
+	}
+
+	if m.memory != nil {
+		name, err := fsPath(filename)
+		if err != nil {
+			return err
+		}
+
+		m.memory.Remove(name)
+
+		return nil
+	}
+
+	if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", filename, err)
 	}
 
 	return nil
 }
 
-// lockFile provides thread-safe file operations.
+// lockFile provides thread-safe file operations. Locks are reference
+// counted and removed once released by everyone, so that memory does not
+// grow with the number of golden files.
 func (m *Manager) lockFile(filename string, exclusive bool) func() {
 	m.mu.Lock()
 
 	lock, exists := m.locks[filename]
 	if !exists {
-		lock = &sync.RWMutex{}
+		lock = &fileLock{}
 		m.locks[filename] = lock
 	}
+
+	lock.refs++
 	m.mu.Unlock()
 
 	if exclusive {
 		lock.Lock()
-
-		return func() { lock.Unlock() }
+	} else {
+		lock.RLock()
 	}
 
-	lock.RLock()
+	return func() {
+		if exclusive {
+			lock.Unlock()
+		} else {
+			lock.RUnlock()
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
 
-	return func() { lock.RUnlock() }
+		if lock.refs--; lock.refs == 0 {
+			delete(m.locks, filename)
+		}
+	}
 }
 
+// subtestReplacer flattens subtest names, as returned by testing.T.Name,
+// into a single filename component.
+var subtestReplacer = strings.NewReplacer("/", "__")
+
 // DefaultNaming implements the default naming strategy
 // Format: TestFunction_goldenName.golden.go.
 type DefaultNaming struct{}
@@ -116,7 +303,11 @@ type DefaultNaming struct{}
 // GenerateFilename generates a filename using the default strategy.
 func (dn *DefaultNaming) GenerateFilename(testFile, testFunc, goldenName string) string {
 	// Remove .go extension from test file
-	baseFile := strings.TrimSuffix(testFile, ".go")
+	baseFile := SanitizeName(strings.TrimSuffix(testFile, ".go"))
+
+	// Subtests are named after their parents, e.g. TestFunction/case_1
+	testFunc = SanitizeName(subtestReplacer.Replace(testFunc))
+	goldenName = SanitizeName(goldenName)
 
 	// Generate filename: TestFile_TestFunction_goldenName.golden.go
 	return fmt.Sprintf("%s_%s_%s.golden.go", baseFile, testFunc, goldenName)
@@ -124,8 +315,11 @@ func (dn *DefaultNaming) GenerateFilename(testFile, testFunc, goldenName string)
 
 // ParseFilename parses a filename to extract components.
 func (dn *DefaultNaming) ParseFilename(filename string) (testFile, testFunc, goldenName string, err error) {
-	// Remove .golden.go extension
-	base := strings.TrimSuffix(filename, ".golden.go")
+	// Remove the .golden.go, .golden.gz or content extension
+	base := filename
+	if i := strings.LastIndex(base, ".golden."); i >= 0 {
+		base = base[:i]
+	}
 
 	// Split by underscore
 	parts := strings.Split(base, "_")
@@ -140,3 +334,55 @@ func (dn *DefaultNaming) ParseFilename(filename string) (testFile, testFunc, gol
 
 	return testFile, testFunc, goldenName, nil
 }
+
+// NestedNaming implements a directory-per-test naming strategy
+// Format: TestFunction/subtest/goldenName.golden.go.
+type NestedNaming struct{}
+
+// GenerateFilename generates a filename nested in a directory per test and
+// subtest. The test file is not part of the path, as test names are unique
+// within a package.
+func (nn *NestedNaming) GenerateFilename(_, testFunc, goldenName string) string {
+	parts := strings.Split(testFunc, "/")
+	for i, part := range parts {
+		parts[i] = SanitizeName(part)
+	}
+
+	return filepath.Join(append(parts, SanitizeName(goldenName)+goldenExtension)...)
+}
+
+// ParseFilename parses a filename, relative to the base directory, to
+// extract components. The test file is not recorded and returned empty.
+func (nn *NestedNaming) ParseFilename(filename string) (testFile, testFunc, goldenName string, err error) {
+	dir, base := filepath.Split(filepath.ToSlash(filename))
+	if dir == "" {
+		return "", "", "", fmt.Errorf("invalid filename format: %s", filename)
+	}
+
+	base = strings.TrimSuffix(strings.TrimSuffix(base, goldenExtension), compressedExtension)
+
+	return "", strings.TrimSuffix(dir, "/"), base, nil
+}
+
+// FlatNaming implements a naming strategy ignoring the test, for golden
+// files used outside of tests, such as those of golden.Compare
+// Format: goldenName.golden.go.
+type FlatNaming struct{}
+
+// GenerateFilename generates a filename from the golden name alone.
+func (fn *FlatNaming) GenerateFilename(_, _, goldenName string) string {
+	return SanitizeName(goldenName) + goldenExtension
+}
+
+// ParseFilename parses a filename to extract the golden name. The test file
+// and function are not recorded and returned empty.
+func (fn *FlatNaming) ParseFilename(filename string) (testFile, testFunc, goldenName string, err error) {
+	base := filepath.Base(filename)
+
+	i := strings.LastIndex(base, ".golden.")
+	if i <= 0 {
+		return "", "", "", fmt.Errorf("invalid filename format: %s", filename)
+	}
+
+	return "", "", base[:i], nil
+}
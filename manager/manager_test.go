@@ -1,7 +1,22 @@
 package manager
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func TestNamingStrategy(t *testing.T) {
@@ -17,6 +32,7 @@ func TestNamingStrategy(t *testing.T) {
 		expected   string
 	}{
 		{"test.go", "TestBasic", "output", "test_TestBasic_output.golden.go"},
+		{"test.go", "TestTable/empty_input", "output", "test_TestTable__empty_input_output.golden.go"},
 	}
 
 	for _, tt := range tests {
@@ -38,3 +54,701 @@ func TestNamingStrategy(t *testing.T) {
 			testFile, testFunc, goldenName)
 	}
 }
+
+func TestNestedNaming(t *testing.T) {
+	t.Parallel()
+
+	naming := &NestedNaming{}
+
+	filename := naming.GenerateFilename("test.go", "TestTable/empty input", "output")
+	if want := filepath.Join("TestTable", "empty_input", "output.golden.go"); filename != want {
+		t.Errorf("GenerateFilename() = %s, want %s", filename, want)
+	}
+
+	testFile, testFunc, goldenName, err := naming.ParseFilename(filename)
+	if err != nil || testFile != "" || testFunc != "TestTable/empty_input" || goldenName != "output" {
+		t.Errorf("ParseFilename() = (%s, %s, %s, %v), want (, TestTable/empty_input, output)", testFile, testFunc, goldenName, err)
+	}
+
+	m := New(t.TempDir(), "test.go", "TestTable/empty input")
+	m.SetNamingStrategy(naming)
+
+	if err := m.WriteFile(m.GetFilename("output"), []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestSymlinkedGoldenFiles(t *testing.T) {
+	t.Parallel()
+
+	shared := t.TempDir()
+	baseDir := t.TempDir()
+	target := filepath.Join(shared, "fixture.golden.go")
+	link := filepath.Join(baseDir, "fixture.golden.go")
+
+	if err := os.WriteFile(target, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	// Links out of the base directory are refused unless their target is
+	// an allowed root
+	m := New(baseDir, "test.go", "TestSymlink")
+	if err := m.WriteFile(link, []byte("new")); !errors.Is(err, ErrSymlinkEscape) {
+		t.Fatalf("WriteFile() error = %v, want ErrSymlinkEscape", err)
+	}
+
+	m.SetSymlinkRoots(shared)
+
+	if err := m.WriteFile(link, []byte("new")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// The link must survive and the shared target must be updated
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symlink was replaced: %v", err)
+	}
+
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("target content = %q, want %q", data, "new")
+	}
+
+	m.SetFollowSymlinks(false)
+
+	if _, err := m.ReadFile(link); !errors.Is(err, ErrSymlink) {
+		t.Errorf("ReadFile() error = %v, want ErrSymlink", err)
+	}
+}
+
+func TestSections(t *testing.T) {
+	t.Parallel()
+
+	m := New(t.TempDir(), "sections_test.go", "TestSections")
+	filename := m.GetFilename("exchange")
+
+	for _, section := range []struct{ name, data string }{
+		{"request", "GET /users\n"},
+		{"response", `{"id": 1}`},
+		{"request", "GET /users/1\n"},
+	} {
+		if err := m.WriteSection(filename, section.name, []byte(section.data)); err != nil {
+			t.Fatalf("WriteSection(%s) error = %v", section.name, err)
+		}
+	}
+
+	data, err := m.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	if want := "-- request --\nGET /users/1\n\n-- response --\n{\"id\": 1}\n"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+
+	if got, err := m.ReadSection(filename, "request"); err != nil || string(got) != "GET /users/1\n" {
+		t.Errorf("ReadSection(request) = %q, %v, want the rewritten request", got, err)
+	}
+
+	if _, err := m.ReadSection(filename, "logs"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("ReadSection(logs) error = %v, want ErrSectionNotFound", err)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	m := New(dir, "compress_test.go", "TestCompression")
+	m.SetCompression(true)
+
+	filename := m.GetFilename("large")
+	if !strings.HasSuffix(filename, ".golden.gz") {
+		t.Fatalf("GetFilename() = %s, want a .golden.gz file", filename)
+	}
+
+	if err := m.WriteFile(filename, []byte("payload")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	if !bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+		t.Errorf("golden file is not gzipped: %q", raw)
+	}
+
+	// Without compression the existing .golden.gz file is still found and read.
+	plain := New(dir, "compress_test.go", "TestCompression")
+	if got := plain.GetFilename("large"); got != filename {
+		t.Errorf("GetFilename() = %s, want %s", got, filename)
+	}
+
+	data, err := plain.ReadFile(filename)
+	if err != nil || string(data) != "payload" {
+		t.Errorf("ReadFile() = %q, %v, want %q", data, err, "payload")
+	}
+
+	naming := &DefaultNaming{}
+	if _, _, name, err := naming.ParseFilename(filepath.Base(filename)); err != nil || name != "large" {
+		t.Errorf("ParseFilename() = %s, %v, want large", name, err)
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"output", "output"},
+		{"a/b:c é", "a%2Fb%3Ac_%C3%A9"},
+		{"", "_"},
+		{"..", "%2E%2E"},
+		{"x.", "x%2E"},
+		{"CON", "_CON"},
+		{"nul.txt", "_nul.txt"},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeName(tt.name); got != tt.expected {
+			t.Errorf("SanitizeName(%q) = %s, want %s", tt.name, got, tt.expected)
+		}
+	}
+
+	naming := &DefaultNaming{}
+	if got := naming.GenerateFilename("test.go", "TestBasic", "a/b"); got != "test_TestBasic_a%2Fb.golden.go" {
+		t.Errorf("GenerateFilename() = %s, want test_TestBasic_a%%2Fb.golden.go", got)
+	}
+}
+
+func TestClaimCollision(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	first := New(dir, "test.go", "TestA")
+	second := New(dir, "test.go", "TestA_foo")
+
+	// Both golden files are named test_TestA_foo_bar.golden.go
+	filename := first.GetFilename("foo_bar")
+	if other := second.GetFilename("bar"); other != filename {
+		t.Fatalf("GetFilename() = %s and %s, want the same filename", filename, other)
+	}
+
+	release1, err := first.Claim(filename, "foo_bar")
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+
+	release2, err := first.Claim(filename, "foo_bar")
+	if err != nil {
+		t.Errorf("Claim() by the same golden file error = %v", err)
+	}
+
+	if _, err := second.Claim(filename, "bar"); !errors.Is(err, ErrFilenameCollision) {
+		t.Errorf("Claim() error = %v, want %v", err, ErrFilenameCollision)
+	}
+
+	release1()
+	release2()
+
+	release, err := second.Claim(filename, "bar")
+	if err != nil {
+		t.Errorf("Claim() after release error = %v", err)
+	}
+
+	release()
+
+	claimsMu.Lock()
+	defer claimsMu.Unlock()
+
+	if _, ok := claims[filename]; ok {
+		t.Errorf("released claim of %s is still recorded", filename)
+	}
+}
+
+func TestPlatformVariants(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	m := New(dir, "test.go", "TestPlatform")
+	m.goos, m.goarch = "windows", "amd64"
+
+	generic := filepath.Join(dir, "test_TestPlatform_output.golden.go")
+	if got := m.GetFilename("output"); got != generic {
+		t.Errorf("GetFilename() = %s, want %s", got, generic)
+	}
+
+	// New golden files are created at the configured specificity
+	m.SetPlatform(PlatformOS)
+
+	osFile := filepath.Join(dir, "test_TestPlatform_output.windows.golden.go")
+	if got := m.GetFilename("output"); got != osFile {
+		t.Errorf("GetFilename() = %s, want %s", got, osFile)
+	}
+
+	if err := m.WriteFile(osFile, []byte("windows")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// Existing variants are used whatever the specificity, and other
+	// platforms fall back to the generic file
+	m.SetPlatform(PlatformGeneric)
+
+	if got := m.GetFilename("output"); got != osFile {
+		t.Errorf("GetFilename() = %s, want %s", got, osFile)
+	}
+
+	m.goos = "linux"
+	if got := m.GetFilename("output"); got != generic {
+		t.Errorf("GetFilename() on linux = %s, want %s", got, generic)
+	}
+
+	m.goos = "windows"
+	m.SetPlatform(PlatformOSArch)
+
+	if got, want := m.GetFilename("output"), filepath.Join(dir, "test_TestPlatform_output.windows_amd64.golden.go"); got != want {
+		t.Errorf("GetFilename() = %s, want %s", got, want)
+	}
+}
+
+func TestMaxFileSize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	m := New(dir, "test.go", "TestMaxFileSize")
+	filename := m.GetFilename("output")
+
+	if err := m.WriteFile(filename, []byte("0123456789")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	m.SetMaxFileSize(5)
+
+	if err := m.WriteFile(filename, []byte("0123456789")); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("WriteFile() error = %v, want %v", err, ErrFileTooLarge)
+	}
+
+	if _, err := m.ReadFile(filename); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("ReadFile() error = %v, want %v", err, ErrFileTooLarge)
+	}
+
+	m.SetMaxFileSize(0)
+
+	if _, err := m.ReadFile(filename); err != nil {
+		t.Errorf("ReadFile() without limit error = %v", err)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	t.Parallel()
+
+	header := Header{
+		Library: "github.com/sivchari/golden v1.0.0",
+		Test:    "TestHeader/case",
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Format:  "json",
+	}
+
+	data := append(header.Bytes(), "{}\n"...)
+
+	parsed, body, ok := ParseHeader(data)
+	if !ok || parsed != header || string(body) != "{}\n" {
+		t.Errorf("ParseHeader() = %+v, %q, %v, want %+v, %q", parsed, body, ok, header, "{}\n")
+	}
+
+	if _, body, ok := ParseHeader([]byte("plain\n")); ok || string(body) != "plain\n" {
+		t.Errorf("ParseHeader() of content without header = %q, %v", body, ok)
+	}
+}
+
+func TestUnchangedWrites(t *testing.T) {
+	t.Parallel()
+
+	m := New(t.TempDir(), "test.go", "TestUnchangedWrites")
+	filename := m.GetFilename("output")
+
+	if err := m.WriteFile(filename, []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filename, past, past); err != nil {
+		t.Fatalf("os.Chtimes() error = %v", err)
+	}
+
+	if err := m.WriteFile(filename, []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if info, err := os.Stat(filename); err != nil || !info.ModTime().Equal(past) {
+		t.Errorf("Unchanged golden file was rewritten: %v, %v", info.ModTime(), err)
+	}
+
+	if err := m.WriteFile(filename, []byte("changed")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if updated, unchanged := m.WriteStats(); updated != 2 || unchanged != 1 {
+		t.Errorf("WriteStats() = %d, %d, want 2, 1", updated, unchanged)
+	}
+}
+
+func TestLocksReleased(t *testing.T) {
+	t.Parallel()
+
+	m := New(t.TempDir(), "test.go", "TestLocksReleased")
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			filename := m.GetFilename(fmt.Sprintf("output_%d", i%5))
+			if err := m.WriteFile(filename, []byte("data")); err != nil {
+				t.Errorf("WriteFile() error = %v", err)
+			}
+
+			if _, err := m.ReadFile(filename); err != nil {
+				t.Errorf("ReadFile() error = %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(m.locks) != 0 {
+		t.Errorf("len(locks) = %d after all operations, want 0", len(m.locks))
+	}
+}
+
+// escapingNaming names golden files after their golden name, unsanitized.
+type escapingNaming struct{ DefaultNaming }
+
+func (*escapingNaming) GenerateFilename(_, _, goldenName string) string {
+	return goldenName
+}
+
+func TestPathEscape(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "testdata")
+
+	m := New(baseDir, "test.go", "TestPathEscape")
+	m.SetNamingStrategy(&escapingNaming{})
+
+	for _, name := range []string{"../outside.golden.go", "sub/../../outside.golden.go"} {
+		if err := m.WriteFile(m.GetFilename(name), []byte("data")); !errors.Is(err, ErrPathEscape) {
+			t.Errorf("WriteFile(%s) error = %v, want ErrPathEscape", name, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "outside.golden.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file outside the base directory, got %v", err)
+	}
+
+	// Directories linking out of the base directory are refused as well
+	if err := os.MkdirAll(baseDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(t.TempDir(), filepath.Join(baseDir, "linked")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := m.WriteFile(m.GetFilename("linked/new.golden.go"), []byte("data")); !errors.Is(err, ErrSymlinkEscape) {
+		t.Errorf("WriteFile() error = %v, want ErrSymlinkEscape", err)
+	}
+}
+
+func TestListAndGlob(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, test := range []string{"TestAPI", "TestAPI/empty", "TestOther"} {
+		m := New(dir, "api_test.go", test)
+		if err := m.WriteFile(m.GetFilename("response"), []byte("data")); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	m := New(dir, "api_test.go", "TestAPI")
+	if err := m.WriteBlob(m.GetFilename("large"), []byte("blob")); err != nil {
+		t.Fatalf("WriteBlob() error = %v", err)
+	}
+
+	files, err := m.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file.Path))
+	}
+
+	want := []string{"api_test_TestAPI__empty_response.golden.go", "api_test_TestAPI_large.golden.go", "api_test_TestAPI_response.golden.go"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+
+	files, err = m.Glob("*_TestOther_*")
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Glob() = %v, %v, want a single file", files, err)
+	}
+
+	if got := files[0]; got.TestFile != "api_test.go" || got.TestFunc != "TestOther" || got.GoldenName != "response" {
+		t.Errorf("Glob() = %+v, want the components of TestOther's golden file", got)
+	}
+
+	if _, err := m.Glob("["); err == nil {
+		t.Error("Glob() with an invalid pattern should fail")
+	}
+}
+
+func TestMemory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	m := NewMemory(dir, "test.go", "TestMemory")
+	m.SetCompression(true)
+
+	filename := m.GetFilename("output")
+	if err := m.WriteFile(filename, []byte("data")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if data, err := m.ReadFile(filename); err != nil || string(data) != "data" {
+		t.Errorf("ReadFile() = %q, %v, want data", data, err)
+	}
+
+	if files, err := m.List(); err != nil || len(files) != 1 || files[0].Path != filename {
+		t.Errorf("List() = %+v, %v, want %s", files, err, filename)
+	}
+
+	if files := m.Memory().Files(); len(files) != 1 || !strings.HasSuffix(files[0], ".golden.gz") {
+		t.Errorf("Files() = %v, want the compressed golden file", files)
+	}
+
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("Expected nothing on disk, got %v, %v", entries, err)
+	}
+}
+
+func TestImmutableSidecar(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	m := NewMemory(dir, "test.go", "TestImmutableSidecar")
+	filename := m.GetFilename("contract")
+
+	if err := m.SetImmutable(filename, true); err != nil {
+		t.Fatalf("SetImmutable(true) error = %v", err)
+	}
+
+	if immutable, err := m.IsImmutable(filename); err != nil || !immutable {
+		t.Errorf("IsImmutable() = %v, %v, want true", immutable, err)
+	}
+
+	if err := m.SetImmutable(filename, false); err != nil {
+		t.Fatalf("SetImmutable(false) error = %v", err)
+	}
+
+	if immutable, err := m.IsImmutable(filename); err != nil || immutable {
+		t.Errorf("IsImmutable() = %v, %v, want false", immutable, err)
+	}
+
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("Expected nothing on disk, got %v, %v", entries, err)
+	}
+
+	// Sidecars are read from the file system golden files are read from
+	embedded := New("testdata", "test.go", "TestImmutableSidecar")
+	embedded.SetFS(fstest.MapFS{"testdata/test_TestImmutableSidecar_contract.golden.go" + ImmutableSuffix: {}})
+
+	if immutable, err := embedded.IsImmutable(embedded.GetFilename("contract")); err != nil || !immutable {
+		t.Errorf("IsImmutable() from fs.FS = %v, %v, want true", immutable, err)
+	}
+}
+
+func TestSharedFiles(t *testing.T) {
+	t.Parallel()
+
+	m := NewMemory(t.TempDir(), "test.go", "TestSharedFiles")
+	a, b := m.GetFilename("a"), m.GetFilename("b")
+
+	write := func(filename, data string) {
+		t.Helper()
+
+		if _, err := m.WriteShared(filename, []byte(data)); err != nil {
+			t.Fatalf("WriteShared(%s) error = %v", filename, err)
+		}
+	}
+
+	sharedFiles := func() int {
+		n := 0
+
+		for _, name := range m.Memory().Files() {
+			if strings.Contains(name, "/"+SharedDir+"/") {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	write(a, "one")
+	write(b, "one")
+	write(a, "two")
+
+	// "one" is still referenced by b
+	if n := sharedFiles(); n != 2 {
+		t.Errorf("got %d shared files, want 2", n)
+	}
+
+	write(b, "two")
+
+	if n := sharedFiles(); n != 1 {
+		t.Errorf("got %d shared files, want 1 once unreferenced ones are collected", n)
+	}
+
+	if data, err := m.ReadShared(a); err != nil || string(data) != "two" {
+		t.Errorf("ReadShared() = %q, %v, want two", data, err)
+	}
+
+	// A shared file with other content than its hash names is not reused
+	if err := m.WriteFile(m.sharedFilename(ContentHash([]byte("three"))), []byte("colliding")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.WriteShared(a, []byte("three")); !errors.Is(err, ErrSharedCollision) {
+		t.Errorf("WriteShared() error = %v, want ErrSharedCollision", err)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	t.Parallel()
+
+	baseline := []byte("released output")
+	sum := sha256.Sum256(baseline)
+	other := sha256.Sum256([]byte("other output"))
+
+	var fetches atomic.Int32
+
+	files := map[string][]byte{
+		"/test_TestRemote_output.golden.go":         baseline,
+		"/test_TestRemote_output.golden.go.sha256":  []byte(hex.EncodeToString(sum[:]) + "  test_TestRemote_output.golden.go\n"),
+		"/test_TestRemote_corrupt.golden.go":        []byte("tampered"),
+		"/test_TestRemote_corrupt.golden.go.sha256": []byte(hex.EncodeToString(other[:]) + "\n"),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if !strings.HasSuffix(r.URL.Path, ".sha256") {
+			fetches.Add(1)
+		}
+
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	m := New(t.TempDir(), "test.go", "TestRemote")
+	m.SetRemote(server.URL+"/", t.TempDir())
+
+	for range 2 {
+		data, err := m.ReadFile(m.GetFilename("output"))
+		if err != nil || !bytes.Equal(data, baseline) {
+			t.Fatalf("ReadFile() = %q, %v, want %q", data, err, baseline)
+		}
+	}
+
+	// The second read is served from the cache
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("Baseline fetched %d times, want 1", n)
+	}
+
+	if _, err := m.ReadFile(m.GetFilename("corrupt")); !errors.Is(err, ErrRemoteChecksum) {
+		t.Errorf("ReadFile() error = %v, want ErrRemoteChecksum", err)
+	}
+
+	if _, err := m.ReadFile(m.GetFilename("missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile() error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestFootprint(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "pkg", "testdata")
+
+	m := New(dir, "api_test.go", "TestAPI")
+	files := map[string][]byte{
+		"small": []byte("data"),
+		"large": bytes.Repeat([]byte("compressible "), CompressionThreshold/8),
+	}
+
+	for name, data := range files {
+		if err := m.WriteFile(m.GetFilename(name), data); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	// Other test data is not counted
+	if err := os.WriteFile(filepath.Join(dir, "input.txt"), []byte("input"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	footprints, err := MeasureTree(root)
+	if err != nil {
+		t.Fatalf("MeasureTree() error = %v", err)
+	}
+
+	if len(footprints) != 1 {
+		t.Fatalf("MeasureTree() = %d footprints, want 1", len(footprints))
+	}
+
+	f := footprints[0]
+	if want := int64(len(files["small"]) + len(files["large"])); len(f.Files) != 2 || f.Size != want {
+		t.Errorf("Footprint = %d files, %d bytes, want 2 files, %d bytes", len(f.Files), f.Size, want)
+	}
+
+	if largest := f.Largest(1); len(largest) != 1 || largest[0].Path != m.GetFilename("large") {
+		t.Errorf("Largest(1) = %v, want the large golden file", largest)
+	}
+
+	if len(f.CompressionCandidates) != 1 || f.CompressionCandidates[0].CompressedSize >= f.CompressionCandidates[0].Size {
+		t.Errorf("CompressionCandidates = %v, want the large golden file", f.CompressionCandidates)
+	}
+
+	var report strings.Builder
+	if err := WriteFootprints(&report, footprints, 5); err != nil {
+		t.Fatalf("WriteFootprints() error = %v", err)
+	}
+
+	if !strings.Contains(report.String(), dir+": 2 file(s)") || !strings.Contains(report.String(), "compression candidate: ") {
+		t.Errorf("WriteFootprints() =\n%s", report.String())
+	}
+}
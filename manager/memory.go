@@ -0,0 +1,130 @@
+package manager
+
+import (
+	"bytes"
+	"io/fs"
+	"sort"
+	"sync"
+	"testing/fstest"
+)
+
+// MemoryFS stores golden files in memory. It is safe for concurrent use.
+type MemoryFS struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemoryFS returns an empty MemoryFS.
+func NewMemoryFS() *MemoryFS {
+	return &MemoryFS{files: make(map[string][]byte)}
+}
+
+// Open implements fs.FS.
+func (m *MemoryFS) Open(name string) (fs.File, error) {
+	return m.snapshot().Open(name) //nolint:wrapcheck // fs.FS errors are returned as is
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (m *MemoryFS) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	data, ok := m.files[name]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+// WriteFile stores data as the file name, a slash separated path as used by
+// fs.FS.
+func (m *MemoryFS) WriteFile(name string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[name] = append([]byte(nil), data...)
+}
+
+// Remove deletes the file name, if it exists.
+func (m *MemoryFS) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.files, name)
+}
+
+// Files returns the names of the stored files in lexical order.
+func (m *MemoryFS) Files() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// snapshot returns a copy of the stored files, which fstest.MapFS serves
+// with the directories they imply.
+func (m *MemoryFS) snapshot() fstest.MapFS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot := make(fstest.MapFS, len(m.files))
+	for name, data := range m.files {
+		snapshot[name] = &fstest.MapFile{Data: data, Mode: 0o600}
+	}
+
+	return snapshot
+}
+
+// NewMemory creates a Manager storing golden files in memory instead of on
+// disk, for tests of code built on the manager and for dry runs of update
+// mode. Written files can be inspected through Memory. Symlinks and blob
+// collection only apply to golden files on disk.
+func NewMemory(baseDir, testFile, testFunc string) *Manager {
+	m := New(baseDir, testFile, testFunc)
+	m.SetMemory(NewMemoryFS())
+
+	return m
+}
+
+// SetMemory makes the manager read and write golden files in mem.
+func (m *Manager) SetMemory(mem *MemoryFS) {
+	m.memory = mem
+	m.fsys = mem
+}
+
+// Memory returns the in-memory storage of the manager, or nil if golden
+// files are stored on disk.
+func (m *Manager) Memory() *MemoryFS {
+	return m.memory
+}
+
+// writeMemory stores data, already compressed if needed, as filename.
+func (m *Manager) writeMemory(filename string, data []byte) error {
+	if err := m.checkPath(filename); err != nil {
+		return err
+	}
+
+	name, err := fsPath(filename)
+	if err != nil {
+		return err
+	}
+
+	if current, err := m.memory.ReadFile(name); err == nil && bytes.Equal(current, data) {
+		m.recordWrite(false)
+
+		return nil
+	}
+
+	m.memory.WriteFile(name, data)
+	m.recordWrite(true)
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package manager
+
+import "strings"
+
+// Platform is the platform specificity of golden files.
+type Platform int
+
+const (
+	// PlatformGeneric golden files, e.g. name.golden.go, are shared by all
+	// platforms.
+	PlatformGeneric Platform = iota
+	// PlatformOS golden files, e.g. name.linux.golden.go, are specific to
+	// an operating system.
+	PlatformOS
+	// PlatformOSArch golden files, e.g. name.windows_amd64.golden.go, are
+	// specific to an operating system and architecture.
+	PlatformOSArch
+)
+
+// SetPlatform sets the platform specificity of the golden files created by
+// the manager, PlatformGeneric by default. Golden files are always looked up
+// from the most specific existing variant down to p, so that platforms
+// without their own variant fall back to the generic golden file.
+func (m *Manager) SetPlatform(p Platform) {
+	m.platform = p
+}
+
+// platformFilename returns the platform variant of filename to use: the most
+// specific existing one, or the one at the manager's platform specificity.
+func (m *Manager) platformFilename(filename string) string {
+	i := strings.LastIndex(filename, ".golden.")
+	if i < 0 {
+		return m.resolveFilename(filename)
+	}
+
+	stem, suffix := filename[:i], filename[i:]
+	tags := map[Platform]string{
+		PlatformOSArch: m.goos + "_" + m.goarch,
+		PlatformOS:     m.goos,
+	}
+
+	for p := PlatformOSArch; p > PlatformGeneric; p-- {
+		variant := m.resolveFilename(stem + "." + tags[p] + suffix)
+		if p == m.platform || m.exists(variant) {
+			return variant
+		}
+	}
+
+	return m.resolveFilename(filename)
+}
+
+// resolveFilename returns the existing compressed or extension variant of
+// filename, if any.
+func (m *Manager) resolveFilename(filename string) string {
+	return m.existingExtension(m.compressedName(filename))
+}
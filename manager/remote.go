@@ -0,0 +1,122 @@
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrRemoteChecksum is returned when a remote baseline does not match its
+// published checksum.
+var ErrRemoteChecksum = errors.New("remote baseline does not match its checksum")
+
+// remoteClient fetches remote baselines.
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// SetRemote makes the manager fetch golden files missing locally from
+// baseURL, e.g. the artifact store of the last release. The golden file
+// testdata/a/b.golden.go is fetched from baseURL/a/b.golden.go, and must
+// be published along with its sha256 checksum at baseURL/a/b.golden.go.sha256,
+// in the format of sha256sum. Fetched files are cached by checksum in
+// cacheDir, so only checksums are downloaded again while the baseline does
+// not change. An empty baseURL disables fetching.
+func (m *Manager) SetRemote(baseURL, cacheDir string) {
+	m.remoteURL = strings.TrimSuffix(baseURL, "/")
+	m.remoteCache = cacheDir
+}
+
+// fetchRemote returns the remote baseline of filename. It returns an error
+// wrapping fs.ErrNotExist if the server has none.
+func (m *Manager) fetchRemote(filename string) ([]byte, error) {
+	rel, err := filepath.Rel(m.baseDir, filename)
+	if err != nil || !filepath.IsLocal(rel) {
+		return nil, fmt.Errorf("%w: %s is not under %s", ErrPathEscape, filename, m.baseDir)
+	}
+
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	location := m.remoteURL + "/" + strings.Join(segments, "/")
+
+	sum, err := httpGet(location + ".sha256")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(sum))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return nil, fmt.Errorf("%w: malformed checksum for %s", ErrRemoteChecksum, location)
+	}
+
+	hash := strings.ToLower(fields[0])
+	cached := filepath.Join(m.remoteCache, hash)
+
+	if data, err := os.ReadFile(cached); err == nil && checksum(data) == hash { //nolint:gosec // G304: The cache is keyed by checksum
+		return data, nil
+	}
+
+	data, err := httpGet(location)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum(data) != hash {
+		return nil, fmt.Errorf("%w: %s", ErrRemoteChecksum, location)
+	}
+
+	// Caching is best effort, the baseline is fetched again otherwise
+	if err := os.MkdirAll(m.remoteCache, 0o750); err == nil {
+		_ = os.WriteFile(cached, data, 0o600)
+	}
+
+	return data, nil
+}
+
+// checksum returns the hex encoded sha256 of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// httpGet returns the body of location, or an error wrapping
+// fs.ErrNotExist if the server answers 404.
+func httpGet(location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote baseline %s: %w", location, err)
+	}
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote baseline %s: %w", location, err)
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("remote baseline %s: %w", location, fs.ErrNotExist)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch remote baseline %s: %s", location, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote baseline %s: %w", location, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrFilenameCollision is returned when two different golden files of a
+// test run map to the same filename.
+var ErrFilenameCollision = errors.New("golden filename collision")
+
+// reservedNames are the device names Windows reserves, with or without an
+// extension.
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// claim is the owner of a golden filename, counting the claims not yet
+// released.
+type claim struct {
+	owner string
+	refs  int
+}
+
+var (
+	// claimsMu guards claims.
+	claimsMu sync.Mutex
+	// claims maps the golden filenames in use in this process to their owner.
+	claims = make(map[string]*claim)
+)
+
+// SanitizeName makes name safe to use as a single path component on all
+// platforms. Spaces become underscores, and characters other than ASCII
+// letters, digits, '-', '.' and '_', such as '/', ':' or non-ASCII letters,
+// are percent-encoded, e.g. "a/b" becomes "a%2Fb". Names consisting of dots
+// or reserved on Windows are escaped as well.
+func SanitizeName(name string) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+
+		switch {
+		case c == ' ':
+			buf.WriteByte('_')
+		case isSafeByte(c):
+			buf.WriteByte(c)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+
+	sanitized := buf.String()
+
+	switch {
+	case sanitized == "":
+		return "_"
+	case strings.Trim(sanitized, ".") == "":
+		return strings.ReplaceAll(sanitized, ".", "%2E")
+	case strings.HasSuffix(sanitized, "."):
+		// Windows drops trailing dots
+		return sanitized[:len(sanitized)-1] + "%2E"
+	}
+
+	if base, _, _ := strings.Cut(sanitized, "."); reservedNames[strings.ToUpper(base)] {
+		return "_" + sanitized
+	}
+
+	return sanitized
+}
+
+// isSafeByte reports whether c may appear unescaped in a filename.
+func isSafeByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_'
+}
+
+// Claim records that filename holds the golden file goldenName of the
+// manager's test until the returned release function is called, e.g. when
+// the test finishes. It returns ErrFilenameCollision if another golden file,
+// of a different test or name, is using filename in this process, as both
+// would otherwise silently share the file. Claims are reference counted, so
+// that memory does not grow with the number of tests run.
+func (m *Manager) Claim(filename, goldenName string) (func(), error) {
+	key, err := filepath.Abs(filename)
+	if err != nil {
+		key = filename
+	}
+
+	owner := fmt.Sprintf("%s %s %q", m.testFile, m.testFunc, goldenName)
+
+	claimsMu.Lock()
+	defer claimsMu.Unlock()
+
+	c, ok := claims[key]
+
+	switch {
+	case !ok:
+		c = &claim{owner: owner}
+		claims[key] = c
+	case c.owner != owner:
+		return nil, fmt.Errorf("%w: %s is used by both %s and %s", ErrFilenameCollision, filename, c.owner, owner)
+	}
+
+	c.refs++
+
+	return func() {
+		claimsMu.Lock()
+		defer claimsMu.Unlock()
+
+		if c.refs--; c.refs == 0 {
+			delete(claims, key)
+		}
+	}, nil
+}
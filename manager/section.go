@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// ErrSectionNotFound is returned when a golden file lacks a section.
+var ErrSectionNotFound = errors.New("section not found in golden file")
+
+// Section is a named part of a golden file holding several snapshots.
+type Section struct {
+	Name string
+	Data []byte
+}
+
+// ParseSections splits data into sections. Like txtar archives, every
+// section starts with a "-- name --" line; content before the first one is
+// dropped. Content lines must therefore not look like section headers.
+func ParseSections(data []byte) []Section {
+	var sections []Section
+
+	for len(data) > 0 {
+		line, rest, _ := bytes.Cut(data, []byte("\n"))
+		data = rest
+
+		if name, ok := sectionName(line); ok {
+			sections = append(sections, Section{Name: name})
+
+			continue
+		}
+
+		if len(sections) > 0 {
+			last := &sections[len(sections)-1]
+			last.Data = append(append(last.Data, line...), '\n')
+		}
+	}
+
+	// FormatSections terminates every section with a newline
+	for i := range sections {
+		sections[i].Data = bytes.TrimSuffix(sections[i].Data, []byte("\n"))
+	}
+
+	return sections
+}
+
+// FormatSections joins sections into golden file content.
+func FormatSections(sections []Section) []byte {
+	var buf bytes.Buffer
+
+	for _, section := range sections {
+		fmt.Fprintf(&buf, "-- %s --\n", section.Name)
+		buf.Write(section.Data)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+// sectionName returns the name of a section header line.
+func sectionName(line []byte) (string, bool) {
+	s := string(line)
+	if !strings.HasPrefix(s, "-- ") || !strings.HasSuffix(s, " --") || len(s) < len("-- x --") {
+		return "", false
+	}
+
+	return strings.TrimSpace(s[len("-- ") : len(s)-len(" --")]), true
+}
+
+// ReadSection reads the section name of a golden file.
+func (m *Manager) ReadSection(filename, name string) ([]byte, error) {
+	unlock := m.lockFile(filename, false)
+	defer unlock()
+
+	data, err := m.readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, section := range ParseSections(data) {
+		if section.Name == name {
+			return section.Data, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s in %s", ErrSectionNotFound, name, filename)
+}
+
+// WriteSection replaces the section name of a golden file with data, or
+// appends it, keeping the other sections in place.
+func (m *Manager) WriteSection(filename, name string, data []byte) error {
+	unlock := m.lockFile(filename, true)
+	defer unlock()
+
+	existing, err := m.readFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	sections := ParseSections(existing)
+
+	found := false
+
+	for i := range sections {
+		if sections[i].Name == name {
+			sections[i].Data = data
+			found = true
+		}
+	}
+
+	if !found {
+		sections = append(sections, Section{Name: name, Data: data})
+	}
+
+	return m.writeFile(filename, FormatSections(sections))
+}
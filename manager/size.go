@@ -0,0 +1,26 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFileTooLarge is returned when a golden file exceeds the maximum size.
+var ErrFileTooLarge = errors.New("golden file too large")
+
+// SetMaxFileSize limits the size of the golden files the manager reads and
+// writes to size bytes, so that a snapshot of an unexpectedly huge value
+// fails instead of landing in the repository. Zero or less disables the
+// limit, which is the default.
+func (m *Manager) SetMaxFileSize(size int64) {
+	m.maxFileSize = size
+}
+
+// checkSize fails if size exceeds the manager's maximum file size.
+func (m *Manager) checkSize(filename string, size int64) error {
+	if m.maxFileSize > 0 && size > m.maxFileSize {
+		return fmt.Errorf("%w: %s is %d bytes, exceeding the limit of %d bytes", ErrFileTooLarge, filename, size, m.maxFileSize)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package manager
+
+// WriteStats returns the number of golden files the manager wrote with new
+// content and the number it left untouched because their content was
+// unchanged.
+func (m *Manager) WriteStats() (updated, unchanged int) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	return m.updated, m.unchanged
+}
+
+// recordWrite counts a write of a golden file, which changed it or not.
+func (m *Manager) recordWrite(changed bool) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	if changed {
+		m.updated++
+	} else {
+		m.unchanged++
+	}
+}
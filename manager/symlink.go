@@ -0,0 +1,174 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrSymlink is returned when a golden path contains a symlink while
+	// following symlinks is disabled.
+	ErrSymlink = errors.New("symlink in golden file path")
+	// ErrPathEscape is returned when a golden path, e.g. built from a golden
+	// name containing "..", is not under the base directory.
+	ErrPathEscape = errors.New("golden file path escapes the base directory")
+	// ErrSymlinkEscape is returned when a golden path links outside of the
+	// base directory and of the allowed symlink roots.
+	ErrSymlinkEscape = errors.New("golden file symlink escapes the base directory")
+)
+
+// SetFollowSymlinks controls whether golden paths may traverse symlinks.
+// Following is enabled by default, which supports testdata directories that
+// link into a shared fixtures repository.
+func (m *Manager) SetFollowSymlinks(follow bool) {
+	m.noFollowSymlinks = !follow
+}
+
+// SetSymlinkRoots sets the directories outside of the base directory that
+// followed symlinks may point into, such as a shared fixtures repository.
+// Symlinks pointing anywhere else are refused.
+func (m *Manager) SetSymlinkRoots(roots ...string) {
+	m.symlinkRoots = roots
+}
+
+// resolvePath returns the path reads and writes of filename should operate on.
+// When filename is itself a symlink, the link target is returned so that
+// writes update the shared file instead of replacing the link, and temporary
+// files are created next to the target on the same filesystem.
+func (m *Manager) resolvePath(filename string) (string, error) {
+	if err := m.checkPath(filename); err != nil {
+		return "", err
+	}
+
+	if m.noFollowSymlinks {
+		if err := m.checkNoSymlinks(filename); err != nil {
+			return "", err
+		}
+
+		return filename, nil
+	}
+
+	resolved, err := evalExisting(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve golden file %s: %w", filename, err)
+	}
+
+	if err := m.checkSymlinkTarget(filename, resolved); err != nil {
+		return "", err
+	}
+
+	if _, err := os.Lstat(filename); err != nil {
+		return filename, nil //nolint:nilerr // Missing files are created at their own path
+	}
+
+	return resolved, nil
+}
+
+// checkPath fails if filename, once cleaned, is not under baseDir.
+func (m *Manager) checkPath(filename string) error {
+	rel, err := filepath.Rel(m.baseDir, filename)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w: %s is not under %s", ErrPathEscape, filename, m.baseDir)
+	}
+
+	return nil
+}
+
+// checkSymlinkTarget fails if resolved, the path filename resolves to, is
+// neither under baseDir nor under one of the symlink roots.
+func (m *Manager) checkSymlinkTarget(filename, resolved string) error {
+	for _, root := range append([]string{m.baseDir}, m.symlinkRoots...) {
+		resolvedRoot, err := evalExisting(root)
+		if err != nil {
+			continue
+		}
+
+		if within(resolvedRoot, resolved) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s resolves to %s", ErrSymlinkEscape, filename, resolved)
+}
+
+// evalExisting evaluates the symlinks of path, or of its closest existing
+// ancestor for paths that do not exist yet, and returns the absolute result.
+func evalExisting(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	var missing []string
+
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+
+		parent := filepath.Dir(path)
+		if !errors.Is(err, os.ErrNotExist) || parent == path {
+			return "", err //nolint:wrapcheck // Callers add the golden file context
+		}
+
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
+
+// within reports whether the absolute path is dir or under it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// checkNoSymlinks fails if baseDir or any existing component of filename
+// below it is a symlink.
+func (m *Manager) checkNoSymlinks(filename string) error {
+	rel, err := filepath.Rel(m.baseDir, filename)
+	if err != nil || !filepath.IsLocal(rel) {
+		// Outside baseDir, only the file itself is checked
+		_, err := checkSymlink(filename)
+
+		return err
+	}
+
+	path := m.baseDir
+	components := strings.Split(rel, string(filepath.Separator))
+
+	for i := -1; i < len(components); i++ {
+		if i >= 0 {
+			path = filepath.Join(path, components[i])
+		}
+
+		exists, err := checkSymlink(path)
+		if err != nil || !exists {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSymlink fails if path is a symlink and reports whether it exists.
+func checkSymlink(path string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return true, fmt.Errorf("%w: %s", ErrSymlink, path)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,356 @@
+// Package migrate imports the snapshots of other Go snapshot libraries as
+// golden files, so that adopting golden does not require regenerating every
+// fixture.
+//
+// Snapshots are copied byte for byte where golden.New in the same test
+// would look for them. Libraries that do not name snapshots within a test
+// get the golden name DefaultName, so the test asserts them with, e.g.,
+// g.Assert(migrate.DefaultName, value). Snapshots of values that golden
+// formats differently, e.g. structs dumped by cupaloy, need one run in
+// update mode after migrating the assertions.
+package migrate
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sivchari/golden/manager"
+)
+
+// DefaultName is the golden name of snapshots that are not named in their
+// test.
+const DefaultName = "snapshot"
+
+// Format is the layout of another snapshot library.
+type Format int
+
+const (
+	// Cupaloy imports the .snapshots/<Test> files of
+	// github.com/bradleyjkemp/cupaloy. Suffixes added by SnapshotMulti,
+	// <Test>-<name>, become golden names.
+	Cupaloy Format = iota
+	// GoSnaps imports the __snapshots__/<file>.snap files of
+	// github.com/gkampitakis/go-snaps. Their n-th snapshot of a test gets the
+	// golden name snapshot_<n>.
+	GoSnaps
+	// Goldie imports the testdata/<name>.golden files of
+	// github.com/sebdah/goldie, named after their test.
+	Goldie
+)
+
+// ErrUnknownFormat is returned for formats other than the declared ones.
+var ErrUnknownFormat = errors.New("unknown snapshot format")
+
+// Mapping records where a snapshot was imported.
+type Mapping struct {
+	Source   string // File holding the snapshot
+	TestFile string // Test file the snapshot belongs to
+	Test     string // Name of the test, as returned by testing.T.Name
+	Name     string // Golden name of the snapshot
+	Target   string // Golden file written
+}
+
+// snapshot is a snapshot read from another library's layout.
+type snapshot struct {
+	source string
+	test   string
+	name   string
+	data   []byte
+}
+
+// Option configures Import.
+type Option func(*config)
+
+// config holds the import settings.
+type config struct {
+	baseDir       string
+	naming        manager.NamingStrategy
+	removeSources bool
+}
+
+// WithBaseDir writes golden files to dir instead of the testdata directory
+// of the package, e.g. when tests use golden.WithBaseDir.
+func WithBaseDir(dir string) Option {
+	return func(c *config) {
+		c.baseDir = dir
+	}
+}
+
+// WithNamingStrategy names golden files with s, as tests using
+// golden.WithNamingStrategy expect.
+func WithNamingStrategy(s manager.NamingStrategy) Option {
+	return func(c *config) {
+		c.naming = s
+	}
+}
+
+// WithRemoveSources removes the imported snapshot files once all golden
+// files are written.
+func WithRemoveSources(remove bool) Option {
+	return func(c *config) {
+		c.removeSources = remove
+	}
+}
+
+// Import converts the snapshots of format found in the package directory
+// pkgDir into golden files and returns where each one was written, sorted
+// by source. Tests are attributed to the *_test.go file of pkgDir declaring
+// them.
+func Import(pkgDir string, format Format, opts ...Option) ([]Mapping, error) {
+	cfg := &config{baseDir: filepath.Join(pkgDir, "testdata")}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	snapshots, err := readSnapshots(pkgDir, format)
+	if err != nil {
+		return nil, err
+	}
+
+	testFiles, err := testFileIndex(pkgDir)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make([]Mapping, 0, len(snapshots))
+	sources := make(map[string]bool)
+
+	for _, snap := range snapshots {
+		top, _, _ := strings.Cut(snap.test, "/")
+
+		testFile, ok := testFiles[top]
+		if !ok {
+			return mappings, fmt.Errorf("no test file in %s declares %s, the test of %s", pkgDir, top, snap.source)
+		}
+
+		m := manager.New(cfg.baseDir, testFile, snap.test)
+		if cfg.naming != nil {
+			m.SetNamingStrategy(cfg.naming)
+		}
+
+		target := m.GetFilename(snap.name)
+		if err := m.WriteFile(target, snap.data); err != nil {
+			return mappings, fmt.Errorf("failed to import %s: %w", snap.source, err)
+		}
+
+		mappings = append(mappings, Mapping{Source: snap.source, TestFile: testFile, Test: snap.test, Name: snap.name, Target: target})
+		sources[snap.source] = true
+	}
+
+	if cfg.removeSources {
+		for source := range sources {
+			if err := os.Remove(source); err != nil {
+				return mappings, fmt.Errorf("failed to remove imported snapshot %s: %w", source, err)
+			}
+		}
+	}
+
+	return mappings, nil
+}
+
+// Report writes the mappings as a table of sources and golden files, with
+// the golden name to assert in the test.
+func Report(w io.Writer, mappings []Mapping) error {
+	for _, m := range mappings {
+		if _, err := fmt.Fprintf(w, "%s -> %s (%s: %q)\n", m.Source, m.Target, m.Test, m.Name); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Imported %d snapshot(s)\n", len(mappings))
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// readSnapshots reads the snapshots of format in pkgDir, sorted by source.
+func readSnapshots(pkgDir string, format Format) ([]snapshot, error) {
+	var (
+		snapshots []snapshot
+		err       error
+	)
+
+	switch format {
+	case Cupaloy:
+		snapshots, err = readCupaloy(filepath.Join(pkgDir, ".snapshots"))
+	case GoSnaps:
+		snapshots, err = readGoSnaps(filepath.Join(pkgDir, "__snapshots__"))
+	case Goldie:
+		snapshots, err = readGoldie(filepath.Join(pkgDir, "testdata"))
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownFormat, format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].source < snapshots[j].source
+	})
+
+	return snapshots, nil
+}
+
+// readCupaloy reads the cupaloy snapshots in dir, named <Test> or
+// <Test>-<name>, where subtest separators are replaced by '-' too.
+func readCupaloy(dir string) ([]snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cupaloy snapshots: %w", err)
+	}
+
+	var snapshots []snapshot
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		source := filepath.Join(dir, entry.Name())
+
+		data, err := os.ReadFile(source) //nolint:gosec // G304: Reading the snapshots to import is the point
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cupaloy snapshot: %w", err)
+		}
+
+		test, name, ok := strings.Cut(entry.Name(), "-")
+		if !ok {
+			name = DefaultName
+		}
+
+		snapshots = append(snapshots, snapshot{source: source, test: test, name: name, data: data})
+	}
+
+	return snapshots, nil
+}
+
+// goSnapsHeader starts a snapshot in go-snaps files: "[TestName - 1]".
+var goSnapsHeader = regexp.MustCompile(`^\[(.+) - (\d+)\]$`)
+
+// readGoSnaps reads the go-snaps snapshots of the .snap files in dir.
+func readGoSnaps(dir string) ([]snapshot, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.snap"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list go-snaps snapshots: %w", err)
+	}
+
+	var snapshots []snapshot
+
+	for _, source := range files {
+		data, err := os.ReadFile(source) //nolint:gosec // G304: Reading the snapshots to import is the point
+		if err != nil {
+			return nil, fmt.Errorf("failed to read go-snaps snapshot: %w", err)
+		}
+
+		snapshots = append(snapshots, parseSnap(source, data)...)
+	}
+
+	return snapshots, nil
+}
+
+// parseSnap splits a go-snaps file into its snapshots. Every snapshot
+// starts with a header line and ends with a "---" line.
+func parseSnap(source string, data []byte) []snapshot {
+	var (
+		snapshots []snapshot
+		current   *snapshot
+		lines     []string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch match := goSnapsHeader.FindStringSubmatch(line); {
+		case current == nil && match != nil:
+			current = &snapshot{source: source, test: match[1], name: DefaultName + "_" + match[2]}
+			lines = nil
+		case current != nil && line == "---":
+			current.data = []byte(strings.Join(lines, "\n"))
+			snapshots = append(snapshots, *current)
+			current = nil
+		case current != nil:
+			lines = append(lines, line)
+		}
+	}
+
+	return snapshots
+}
+
+// readGoldie reads the goldie fixtures under dir, named after their test
+// with a .golden suffix. Golden files of this library, such as
+// x.golden.go, do not match.
+func readGoldie(dir string) ([]snapshot, error) {
+	var snapshots []snapshot
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(path, ".golden") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+
+		data, err := os.ReadFile(path) //nolint:gosec // G304: Reading the snapshots to import is the point
+		if err != nil {
+			return fmt.Errorf("failed to read goldie fixture: %w", err)
+		}
+
+		test := strings.TrimSuffix(filepath.ToSlash(rel), ".golden")
+		snapshots = append(snapshots, snapshot{source: path, test: test, name: DefaultName, data: data})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read goldie fixtures: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// testFuncPattern matches the declarations of top-level tests.
+var testFuncPattern = regexp.MustCompile(`(?m)^func (Test\w*)\(`)
+
+// testFileIndex maps the top-level tests declared in the *_test.go files of
+// pkgDir to their file name.
+func testFileIndex(pkgDir string) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(pkgDir, "*_test.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test files: %w", err)
+	}
+
+	index := make(map[string]string)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file) //nolint:gosec // G304: Reading the package's test files is the point
+		if err != nil {
+			return nil, fmt.Errorf("failed to read test file: %w", err)
+		}
+
+		for _, match := range testFuncPattern.FindAllSubmatch(data, -1) {
+			index[string(match[1])] = filepath.Base(file)
+		}
+	}
+
+	return index, nil
+}
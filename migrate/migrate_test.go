@@ -0,0 +1,155 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// importedFiles returns the content of the imported golden files by base
+// name.
+func importedFiles(t *testing.T, mappings []Mapping) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+
+	for _, m := range mappings {
+		data, err := os.ReadFile(m.Target)
+		if err != nil {
+			t.Fatalf("Failed to read imported golden file: %v", err)
+		}
+
+		files[filepath.Base(m.Target)] = string(data)
+	}
+
+	return files
+}
+
+const testSource = "package pkg\n\nfunc TestRender(t *testing.T) {}\n\nfunc TestParse(t *testing.T) {}\n"
+
+func TestImportCupaloy(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"render_test.go":           testSource,
+		".snapshots/TestRender":    "rendered",
+		".snapshots/TestParse-ast": "parsed",
+	})
+
+	mappings, err := Import(dir, Cupaloy, WithRemoveSources(true))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	want := map[string]string{
+		"render_test_TestRender_snapshot.golden.go": "rendered",
+		"render_test_TestParse_ast.golden.go":       "parsed",
+	}
+
+	got := importedFiles(t, mappings)
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("Imported %s = %q, want %q (all: %v)", name, got[name], content, got)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".snapshots", "TestRender")); !os.IsNotExist(err) {
+		t.Errorf("Expected the source snapshot to be removed, got %v", err)
+	}
+}
+
+func TestImportGoSnaps(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"render_test.go": testSource,
+		"__snapshots__/render_test.snap": "\n[TestRender - 1]\nfirst\nline\n---\n\n" +
+			"[TestRender/empty - 1]\n\n---\n\n[TestRender - 2]\nsecond\n---\n",
+	})
+
+	mappings, err := Import(dir, GoSnaps)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	got := importedFiles(t, mappings)
+	want := map[string]string{
+		"render_test_TestRender_snapshot_1.golden.go":        "first\nline",
+		"render_test_TestRender__empty_snapshot_1.golden.go": "",
+		"render_test_TestRender_snapshot_2.golden.go":        "second",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Imported %v, want %v", got, want)
+	}
+
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("Imported %s = %q, want %q", name, got[name], content)
+		}
+	}
+}
+
+func TestImportGoldie(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"render_test.go":                 testSource,
+		"testdata/TestRender.golden":     "rendered",
+		"testdata/TestParse/json.golden": "{}",
+		"testdata/existing.golden.go":    "not goldie",
+	})
+
+	mappings, err := Import(dir, Goldie)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	got := importedFiles(t, mappings)
+	if len(got) != 2 || got["render_test_TestRender_snapshot.golden.go"] != "rendered" || got["render_test_TestParse__json_snapshot.golden.go"] != "{}" {
+		t.Errorf("Imported %v", got)
+	}
+
+	var report strings.Builder
+	if err := Report(&report, mappings); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	if !strings.Contains(report.String(), "TestRender.golden -> ") || !strings.HasSuffix(report.String(), "Imported 2 snapshot(s)\n") {
+		t.Errorf("Report() =\n%s", report.String())
+	}
+}
+
+func TestImportUnknownTest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"render_test.go":         testSource,
+		".snapshots/TestMissing": "orphan",
+	})
+
+	if _, err := Import(dir, Cupaloy); err == nil || !strings.Contains(err.Error(), "TestMissing") {
+		t.Errorf("Import() error = %v, want an error naming TestMissing", err)
+	}
+}
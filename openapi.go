@@ -0,0 +1,342 @@
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/sivchari/golden/comparator"
+)
+
+// openAPIDocFields are the documentation fields WithOpenAPIIgnoreDocs
+// ignores.
+var openAPIDocFields = []string{"description", "summary"}
+
+// openAPIChange is a difference between two OpenAPI documents.
+type openAPIChange struct {
+	path          []string
+	kind          byte // '+' added, '-' removed, '~' changed
+	element       bool // The last path segment is a value of a list of scalars
+	before, after interface{}
+}
+
+// AssertOpenAPI compares the OpenAPI or Swagger document spec, in JSON or
+// YAML, with the golden file name, stored as YAML. Documents are compared
+// structurally: key order, the order of parameters and of lists such as
+// required or enum do not matter. With WithOpenAPIIgnoreDocs, info.version,
+// descriptions and summaries are ignored. On mismatch, the differences are
+// reported in two groups: breaking changes, such as removed paths,
+// operations, responses or properties, new required parameters or fields
+// and changed types, and non-breaking ones.
+func (g *Golden) AssertOpenAPI(name string, spec []byte) {
+	g.t.Helper()
+
+	filename := g.goldenFilename(name, "yaml")
+
+	actual, err := decodeOpenAPI(spec)
+	if err != nil {
+		g.t.Fatalf("Failed to decode OpenAPI document %s: %v", name, err)
+	}
+
+	data, err := marshalYAML(actual)
+	if err != nil {
+		g.t.Fatalf("Failed to serialize OpenAPI document %s: %v", name, err)
+	}
+
+	data = g.scrub(data)
+
+	if g.options.Update {
+		g.checkMutable(filename)
+		g.writeGolden(filename, data)
+
+		return
+	}
+
+	golden, err := g.readGolden(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			g.t.Fatalf("Golden file %s does not exist. Run with update mode to create it.", filename)
+		}
+
+		g.t.Fatalf("Failed to read golden file %s: %v", filename, err)
+	}
+
+	if report := g.diffOpenAPI(golden, data); report != "" {
+		g.t.Fatalf("%s", g.formatDiffError(filename, "", report, nil))
+	}
+}
+
+// decodeOpenAPI decodes a JSON or YAML document.
+func decodeOpenAPI(data []byte) (interface{}, error) {
+	docs, err := comparator.DecodeYAML(data)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Errors are already wrapped by the comparator
+	}
+
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("%w: found %d documents", errNotSingleDocument, len(docs))
+	}
+
+	return docs[0], nil
+}
+
+// errNotSingleDocument is returned for OpenAPI input holding several or no
+// YAML documents.
+var errNotSingleDocument = errors.New("expected a single document")
+
+// diffOpenAPI returns a report of the changes from the expected to the
+// actual document, or "" if they are equivalent.
+func (g *Golden) diffOpenAPI(expected, actual []byte) string {
+	oldDoc, err := decodeOpenAPI(expected)
+	if err != nil {
+		return fmt.Sprintf("Failed to decode golden file: %v", err)
+	}
+
+	newDoc, err := decodeOpenAPI(actual)
+	if err != nil {
+		return fmt.Sprintf("Failed to decode actual document: %v", err)
+	}
+
+	if g.options.OpenAPIIgnoreDocs {
+		oldDoc, newDoc = stripOpenAPIDocs(oldDoc, nil), stripOpenAPIDocs(newDoc, nil)
+	}
+
+	var changes []openAPIChange
+
+	diffOpenAPIValues(oldDoc, newDoc, nil, &changes)
+
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var breaking, compatible strings.Builder
+
+	for _, change := range changes {
+		out := &compatible
+		if change.breaking() {
+			out = &breaking
+		}
+
+		fmt.Fprintf(out, "  %c %s\n", change.kind, change)
+	}
+
+	var report strings.Builder
+
+	if breaking.Len() > 0 {
+		report.WriteString("Breaking changes:\n" + breaking.String())
+	}
+
+	if compatible.Len() > 0 {
+		report.WriteString("Non-breaking changes:\n" + compatible.String())
+	}
+
+	return report.String()
+}
+
+// stripOpenAPIDocs removes info.version and documentation fields from v, at
+// path. Properties named like documentation fields are kept.
+func stripOpenAPIDocs(v interface{}, path []string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		stripped := make(map[string]interface{}, len(val))
+
+		for key, child := range val {
+			isProperty := len(path) > 0 && path[len(path)-1] == "properties"
+			if !isProperty && (slices.Contains(openAPIDocFields, key) || (len(path) == 1 && path[0] == "info" && key == "version")) {
+				continue
+			}
+
+			stripped[key] = stripOpenAPIDocs(child, append(slices.Clone(path), key))
+		}
+
+		return stripped
+	case []interface{}:
+		stripped := make([]interface{}, len(val))
+		for i, child := range val {
+			stripped[i] = stripOpenAPIDocs(child, append(slices.Clone(path), fmt.Sprint(i)))
+		}
+
+		return stripped
+	default:
+		return v
+	}
+}
+
+// diffOpenAPIValues appends the changes from oldVal to newVal at path.
+func diffOpenAPIValues(oldVal, newVal interface{}, path []string, changes *[]openAPIChange) {
+	oldMap, oldIsMap := oldVal.(map[string]interface{})
+	newMap, newIsMap := newVal.(map[string]interface{})
+
+	if oldIsMap && newIsMap {
+		diffOpenAPIMaps(oldMap, newMap, path, changes)
+
+		return
+	}
+
+	oldList, oldIsList := oldVal.([]interface{})
+	newList, newIsList := newVal.([]interface{})
+
+	if oldIsList && newIsList {
+		diffOpenAPILists(oldList, newList, path, changes)
+
+		return
+	}
+
+	if !reflect.DeepEqual(oldVal, newVal) {
+		*changes = append(*changes, openAPIChange{path: path, kind: '~', before: oldVal, after: newVal})
+	}
+}
+
+// diffOpenAPIMaps appends the changes between two objects, key by key.
+func diffOpenAPIMaps(oldMap, newMap map[string]interface{}, path []string, changes *[]openAPIChange) {
+	for _, key := range sortedUnion(oldMap, newMap) {
+		childPath := append(slices.Clone(path), key)
+		oldChild, inOld := oldMap[key]
+		newChild, inNew := newMap[key]
+
+		switch {
+		case !inNew:
+			*changes = append(*changes, openAPIChange{path: childPath, kind: '-', before: oldChild})
+		case !inOld:
+			*changes = append(*changes, openAPIChange{path: childPath, kind: '+', after: newChild})
+		default:
+			diffOpenAPIValues(oldChild, newChild, childPath, changes)
+		}
+	}
+}
+
+// diffOpenAPILists appends the changes between two lists. Lists of
+// scalars, such as required or enum, are compared as sets, and lists of
+// named objects, such as parameters, by name. Other lists are compared by
+// index.
+func diffOpenAPILists(oldList, newList []interface{}, path []string, changes *[]openAPIChange) {
+	if oldKeys, ok := openAPIListKeys(oldList); ok {
+		if newKeys, ok := openAPIListKeys(newList); ok {
+			diffOpenAPIKeyed(oldKeys, newKeys, path, changes)
+
+			return
+		}
+	}
+
+	if len(oldList) != len(newList) {
+		*changes = append(*changes, openAPIChange{path: path, kind: '~', before: oldList, after: newList})
+
+		return
+	}
+
+	for i := range oldList {
+		diffOpenAPIValues(oldList[i], newList[i], append(slices.Clone(path), fmt.Sprint(i)), changes)
+	}
+}
+
+// diffOpenAPIKeyed appends the changes between two keyed lists.
+func diffOpenAPIKeyed(oldKeys, newKeys map[string]interface{}, path []string, changes *[]openAPIChange) {
+	for _, key := range sortedUnion(oldKeys, newKeys) {
+		oldElem, inOld := oldKeys[key]
+		newElem, inNew := newKeys[key]
+		_, scalar := oldElem.(string)
+		_, newScalar := newElem.(string)
+		childPath := append(slices.Clone(path), key)
+
+		switch {
+		case !inNew:
+			*changes = append(*changes, openAPIChange{path: childPath, kind: '-', element: scalar, before: oldElem})
+		case !inOld:
+			*changes = append(*changes, openAPIChange{path: childPath, kind: '+', element: newScalar, after: newElem})
+		default:
+			diffOpenAPIValues(oldElem, newElem, childPath, changes)
+		}
+	}
+}
+
+// openAPIListKeys keys the elements of list by their value if they are all
+// scalars, or by location and name if they are all named objects.
+func openAPIListKeys(list []interface{}) (map[string]interface{}, bool) {
+	keys := make(map[string]interface{}, len(list))
+
+	for _, elem := range list {
+		switch val := elem.(type) {
+		case map[string]interface{}:
+			name, ok := val["name"].(string)
+			if !ok {
+				return nil, false
+			}
+
+			if in, ok := val["in"].(string); ok {
+				name = in + ":" + name
+			}
+
+			keys[name] = val
+		case []interface{}:
+			return nil, false
+		default:
+			keys[fmt.Sprint(val)] = fmt.Sprint(val)
+		}
+	}
+
+	return keys, true
+}
+
+// breaking reports whether the change may break existing clients.
+func (c openAPIChange) breaking() bool {
+	last := c.path[len(c.path)-1]
+
+	parent := ""
+	if len(c.path) > 1 {
+		parent = c.path[len(c.path)-2]
+	}
+
+	switch c.kind {
+	case '-':
+		// Dropping documentation or a requirement is compatible, dropping
+		// anything else, such as an enum value, path or property, is not
+		return !(c.element && parent == "required") && last != "required" && !slices.Contains(openAPIDocFields, last)
+	case '+':
+		// New required fields and parameters break existing requests
+		return (c.element && parent == "required") || makesRequired(last, c.after) || (parent == "parameters" && isRequired(c.after))
+	default:
+		return slices.Contains([]string{"type", "format", "$ref", "in"}, last) || makesRequired(last, c.after)
+	}
+}
+
+// makesRequired reports whether the field key set to value makes a
+// parameter or request body required.
+func makesRequired(key string, value interface{}) bool {
+	return key == "required" && value == true
+}
+
+// isRequired reports whether value is a required parameter.
+func isRequired(value interface{}) bool {
+	obj, ok := value.(map[string]interface{})
+
+	return ok && obj["required"] == true
+}
+
+// String formats the change as its path and, for changed values, the old
+// and new value.
+func (c openAPIChange) String() string {
+	var path strings.Builder
+
+	for i, segment := range c.path {
+		switch {
+		case strings.ContainsAny(segment, "./{}: ") || segment == "":
+			fmt.Fprintf(&path, "[%q]", segment)
+		case i > 0:
+			path.WriteString("." + segment)
+		default:
+			path.WriteString(segment)
+		}
+	}
+
+	switch c.kind {
+	case '-':
+		return path.String() + ": removed"
+	case '+':
+		return path.String() + ": added"
+	default:
+		return fmt.Sprintf("%s: %v -> %v", path.String(), c.before, c.after)
+	}
+}
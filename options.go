@@ -1,28 +1,124 @@
 package golden
 
 import (
+	"errors"
 	"io"
+	"io/fs"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/encoding"
+
+	"github.com/sivchari/golden/comparator"
+	"github.com/sivchari/golden/manager"
 )
 
 // Options configures Golden test behavior.
 type Options struct {
 	// Basic settings
-	Update bool // Update mode to create/update golden files
+	Update         bool // Update mode to create/update golden files
+	ForceImmutable bool // Allow update mode to rewrite immutable golden files
+	ReadOnly       bool // Fail any golden file write (default: true on CI)
 
 	// Advanced settings
-	IgnoreOrder   bool                               // Array order handling (default: true for JSON)
-	IgnoreFields  []string                           // Specific JSON fields to ignore
-	CustomCompare func(expected, actual []byte) bool // Custom comparison function
+	IgnoreOrder       bool                               // Array order handling (default: true for JSON)
+	IgnoreWhitespace  bool                               // Ignore whitespace-only differences
+	CaseInsensitive   bool                               // Ignore letter case differences in strings
+	Locale            string                             // Locale whose case rules CaseInsensitive applies
+	IgnoreFields      []string                           // Specific JSON fields to ignore
+	SortedLines       bool                               // Sort lines before text comparison
+	Deduplicate       bool                               // Store identical goldens once by content hash
+	CustomCompare     func(expected, actual []byte) bool // Custom comparison function
+	Comparator        comparator.Interface               // Replaces the built-in comparator
+	DiffAnchorKey     string                             // Align JSON array elements by this key in diffs
+	DiffAlgorithm     string                             // Name of a registered diff algorithm
+	DetectMoves       bool                               // Render moved blocks in diffs instead of deletes and inserts
+	Placeholders      bool                               // Treat <<NAME>> tokens in golden files as wildcards
+	Vars              map[string]string                  // Values of ${NAME} placeholders in golden files
+	Timestamps        []comparator.TimestampRule         // Timestamp tolerance and normalization rules
+	FieldComparers    []comparator.FieldComparer         // Custom comparison of specific JSON fields
+	OrderKeys         []comparator.OrderKey              // Keys to sort arrays of objects by
+	CmpOptions        []cmp.Option                       // Compare structured values with go-cmp
+	StrictJSON        bool                               // Reject duplicate keys, invalid escapes and trailing data
+	Scrubbers         []Scrubber                         // Rewrite output before writing and comparing
+	NullAsMissing     bool                               // Treat JSON fields set to null as absent
+	IgnoreExtraFields bool                               // Ignore JSON fields missing from the golden file
+	FieldDecoders     []comparator.FieldDecoder          // Decode encoded JSON fields before comparison
+	HTML              bool                               // Compare content as normalized HTML
+	StripAttributes   []string                           // HTML attributes ignored when comparing HTML
+	IgnoreValues      []string                           // Regexps of values ignored regardless of their path
+	Tokens            bool                               // Compare source code token by token
+	FormatGo          bool                               // Format Go source before comparing tokens
+	YAML              bool                               // Compare content as a YAML stream
+	YAMLDocumentKeys  []string                           // Keys pairing the documents of YAML streams
+	UnorderedRecords  bool                               // Compare NDJSON records regardless of their order
+	Format            Format                             // Serialization of structured values
+	Serializer        Serializer                         // Serializer overriding Format
+	RawBytes          bool                               // Store and compare strings and bytes exactly
+	Encoding          encoding.Encoding                  // Encoding of golden content (default: detected)
+	JSONPrefix        string                             // Prefix of every indented JSON line
+	JSONIndent        string                             // Indentation of JSON (default: two spaces)
+	CompactJSON       bool                               // Write JSON on a single line
+	EscapeHTML        bool                               // Escape <, > and & in JSON strings
+	UnexportedFields  bool                               // Include unexported fields in GoDump output
+	FormatTimes       bool                               // Render times and durations as strings
+	TimeLayout        string                             // Layout of times (default: time.RFC3339)
+	TimeLocation      *time.Location                     // Location of times (default: UTC)
+	PreferStringer    bool                               // Render Stringers and TextMarshalers as text
+
+	// HTTP settings
+	HTTPHeaders []string // Response headers included in HTTP snapshots (default: all)
+
+	// Directory and archive settings
+	DirExcludes     []string       // Globs of paths skipped by AssertDir
+	FileScrubbers   []FileScrubber // Scrubbers of the files matching a glob in AssertDir
+	ArchiveContents []string       // Globs of archive entries whose content AssertArchive stores
+
+	// SQL settings
+	SQLParams SQLParams // Rendering of bind parameters in AssertSQL
+
+	// OpenAPI settings
+	OpenAPIIgnoreDocs bool // Ignore info.version, descriptions and summaries in AssertOpenAPI
+
+	// Template settings
+	WhitespacePolicy WhitespacePolicy // Whitespace normalization of AssertTemplate output
+
+	// Table settings
+	TableIgnoreColumns []string // Columns AssertTable leaves out of comparisons
+	UnorderedRows      bool     // Compare AssertTable rows regardless of order
+
+	// Image settings
+	ImageTolerance      uint8   // Per-channel difference tolerated in AssertImage
+	ImageMaxDiffPercent float64 // Percentage of pixels allowed to differ in AssertImage
 
 	// Path settings
-	BaseDir string // Base directory for golden files (default: "testdata")
+	BaseDir        string   // Base directory for golden files (default: "testdata")
+	FollowSymlinks bool     // Allow golden paths to traverse symlinks (default: true)
+	SymlinkRoots   []string // Directories symlinks may point into besides BaseDir
+	Compression    bool     // Gzip golden files into *.golden.gz
+	FileExtensions bool     // Name golden files after their content, e.g. *.golden.json
+	BlobThreshold  int      // Store goldens larger than this many bytes as blobs
+
+	NamingStrategy manager.NamingStrategy // Naming of golden files (default: manager.DefaultNaming)
+	FS             fs.FS                  // Read golden files from FS instead of the local disk
+	Memory         *manager.MemoryFS      // Read and write golden files in memory instead of on disk
+	RemoteBaseline string                 // URL to fetch golden files missing locally from
+	Platform       manager.Platform       // Platform specificity of new golden files
+	MetadataHeader bool                   // Prefix golden files with a provenance header
+	SizeBudget     int64                  // Fail update mode for golden files larger than this many bytes
+
+	// Output settings
+	Hyperlinks bool // Render file paths and accept actions as clickable OSC 8 links (default: detected from terminal)
+	DiffWidth  int  // Soft-wrap diff lines to this width (default: $COLUMNS, 0 disables)
 
 	// Internal settings
 	contextLines int       // Lines of context in diff
 	bufferSize   int       // Buffer size for file operations
 	maxFileSize  int64     // Safety limit
+	err          error     // Invalid option, reported when the options are used
 	input        io.Reader // For testing
 	output       io.Writer // For testing
 }
@@ -37,14 +133,75 @@ func WithUpdate(update bool) Option {
 	}
 }
 
-// WithIgnoreFields ignores specific JSON fields during comparison
-// Example: WithIgnoreFields("created_at", "updated_at", "id").
+// WithForceImmutable allows update mode to rewrite golden files marked
+// immutable. It can also be enabled with GOLDEN_FORCE_IMMUTABLE=true.
+func WithForceImmutable(force bool) Option {
+	return func(o *Options) {
+		o.ForceImmutable = force
+	}
+}
+
+// WithReadOnly makes any golden file write fail, including in update mode,
+// so that a test committed with WithUpdate(true) left on fails instead of
+// always passing. It is enabled by default when CI=true, and can also be set
+// with GOLDEN_READ_ONLY=true or false.
+func WithReadOnly(readOnly bool) Option {
+	return func(o *Options) {
+		o.ReadOnly = readOnly
+	}
+}
+
+// WithIgnoreFields ignores specific JSON fields during comparison.
+// Bare names are ignored at any depth, while paths such as "data.user.created_at"
+// or "items[*].id" only ignore the field at that location.
+// Example: WithIgnoreFields("created_at", "items[*].id").
 func WithIgnoreFields(fields ...string) Option {
 	return func(o *Options) {
 		o.IgnoreFields = fields
 	}
 }
 
+// WithIgnoreExtraFields ignores JSON fields present in the actual value but
+// absent from the golden file, while still failing on missing or changed
+// fields. This locks down a contract while upstream services add fields.
+func WithIgnoreExtraFields(ignore bool) Option {
+	return func(o *Options) {
+		o.IgnoreExtraFields = ignore
+	}
+}
+
+// WithNullAsMissing controls whether a JSON field explicitly set to null is
+// considered equal to the field being absent. By default they differ, which
+// matters for APIs whose contract distinguishes the two.
+func WithNullAsMissing(equal bool) Option {
+	return func(o *Options) {
+		o.NullAsMissing = equal
+	}
+}
+
+// WithIgnoreValuesMatching ignores every string value matching the regexp
+// pattern in full, regardless of its key name or path, and occurrences of
+// the pattern in text. The shapes in comparator.Placeholders can be reused,
+// e.g. WithIgnoreValuesMatching(comparator.Placeholders["UUID"].String()).
+// New fails the test if pattern does not compile.
+func WithIgnoreValuesMatching(pattern string) Option {
+	return func(o *Options) {
+		if err := comparator.ValidateIgnoreValue(pattern); err != nil {
+			o.err = errors.Join(o.err, err)
+
+			return
+		}
+
+		o.IgnoreValues = append(o.IgnoreValues, pattern)
+	}
+}
+
+// WithIgnoreTimestamps ignores every RFC3339 timestamp, regardless of its
+// key name or path.
+func WithIgnoreTimestamps() Option {
+	return WithIgnoreValuesMatching(comparator.Placeholders["RFC3339"].String())
+}
+
 // WithIgnoreOrder controls array order sensitivity (default: true for JSON).
 func WithIgnoreOrder(ignore bool) Option {
 	return func(o *Options) {
@@ -52,6 +209,529 @@ func WithIgnoreOrder(ignore bool) Option {
 	}
 }
 
+// WithUnorderedRecords controls whether the records of newline-delimited JSON
+// are compared regardless of their order. By default record order matters,
+// as it usually reflects the order of events; WithIgnoreOrder only applies to
+// the arrays within records.
+func WithUnorderedRecords(unordered bool) Option {
+	return func(o *Options) {
+		o.UnorderedRecords = unordered
+	}
+}
+
+// WithIgnoreWhitespace ignores whitespace-only differences, both when
+// comparing and when rendering the diff.
+func WithIgnoreWhitespace(ignore bool) Option {
+	return func(o *Options) {
+		o.IgnoreWhitespace = ignore
+	}
+}
+
+// WithCaseInsensitive ignores letter case differences in strings, useful for
+// user-facing text whose casing varies across data sources.
+func WithCaseInsensitive(ignore bool) Option {
+	return func(o *Options) {
+		o.CaseInsensitive = ignore
+	}
+}
+
+// WithLocale sets the BCP 47 locale, e.g. "tr", whose case rules are used by
+// WithCaseInsensitive. Unicode case folding is used when unset.
+func WithLocale(locale string) Option {
+	return func(o *Options) {
+		o.Locale = locale
+	}
+}
+
+// WithSortedLines sorts the lines of both sides before text comparison and
+// diffs the sorted views. Useful for inherently unordered line-oriented output.
+func WithSortedLines() Option {
+	return func(o *Options) {
+		o.SortedLines = true
+	}
+}
+
+// WithDeduplication stores golden content once per content hash in a shared
+// file, mapping each golden name to it. Useful for large table tests where
+// many cases produce identical output. Update mode removes shared files no
+// longer mapped to.
+func WithDeduplication(enabled bool) Option {
+	return func(o *Options) {
+		o.Deduplicate = enabled
+	}
+}
+
+// WithPlaceholders enables placeholder tokens in golden files, such as
+// "<<UUID>>", "<<RFC3339>>" or "<<ANY>>", which match any actual value of
+// that shape. See comparator.Placeholders for the supported names.
+func WithPlaceholders(enabled bool) Option {
+	return func(o *Options) {
+		o.Placeholders = enabled
+	}
+}
+
+// WithVars expands ${NAME} placeholders in golden files to the given values
+// when comparing, and writes the values back as placeholders in update mode.
+// Useful when output unavoidably contains machine-local values such as
+// absolute paths. Placeholders of other names are left as is.
+func WithVars(vars map[string]string) Option {
+	return func(o *Options) {
+		if o.Vars == nil {
+			o.Vars = make(map[string]string, len(vars))
+		}
+
+		for name, value := range vars {
+			if value != "" {
+				o.Vars[name] = value
+			}
+		}
+	}
+}
+
+// WithEnvVars is like WithVars for the environment variables names. The
+// built-in PROJECT_ROOT, the directory of the closest go.mod, and HOSTNAME
+// are available even if not set in the environment. Unset variables are
+// skipped.
+func WithEnvVars(names ...string) Option {
+	vars := make(map[string]string, len(names))
+	for _, name := range names {
+		vars[name] = envVar(name)
+	}
+
+	return WithVars(vars)
+}
+
+// WithTimestampTolerance compares RFC3339 or Unix timestamps at path with
+// the given tolerance instead of exactly.
+// Example: WithTimestampTolerance("data.created_at", 2*time.Second).
+func WithTimestampTolerance(path string, tolerance time.Duration) Option {
+	return func(o *Options) {
+		o.Timestamps = append(o.Timestamps, comparator.TimestampRule{Path: path, Tolerance: tolerance})
+	}
+}
+
+// WithTimestampToken normalizes valid RFC3339 or Unix timestamps at path to
+// token before comparison, still failing on values that are not timestamps.
+func WithTimestampToken(path, token string) Option {
+	return func(o *Options) {
+		o.Timestamps = append(o.Timestamps, comparator.TimestampRule{Path: path, Token: token})
+	}
+}
+
+// WithFieldComparer compares the JSON values at path with fn instead of
+// exactly, leaving the rest of the document to the default comparison.
+// Example: WithFieldComparer("data.signature", verifySignature).
+func WithFieldComparer(path string, fn func(expected, actual interface{}) bool) Option {
+	return func(o *Options) {
+		o.FieldComparers = append(o.FieldComparers, comparator.FieldComparer{Path: path, Equal: fn})
+	}
+}
+
+// WithOrderKey sorts the arrays of objects at path by their field value
+// before comparison, e.g. WithOrderKey("items", "id"), which gives a
+// deterministic and meaningful order compared to WithIgnoreOrder.
+func WithOrderKey(path, field string) Option {
+	return func(o *Options) {
+		o.OrderKeys = append(o.OrderKeys, comparator.OrderKey{Path: path, Field: field})
+	}
+}
+
+// WithCmpOptions compares structured values with go-cmp using opts, such as
+// cmpopts.IgnoreFields or custom transformers, instead of the semantic JSON
+// comparison. The golden file still holds the JSON representation, which is
+// decoded into a value of the asserted type, and failures show both the
+// golden diff and the go-cmp diff. Strings and byte slices are unaffected.
+func WithCmpOptions(opts ...cmp.Option) Option {
+	return func(o *Options) {
+		o.CmpOptions = append(o.CmpOptions, opts...)
+	}
+}
+
+// WithStrictJSON rejects JSON with duplicate object keys, invalid UTF-8,
+// unpaired surrogate escapes or trailing data, which encoding/json accepts
+// silently, reporting the line and column of the problem.
+func WithStrictJSON(strict bool) Option {
+	return func(o *Options) {
+		o.StrictJSON = strict
+	}
+}
+
+// WithScrubbers rewrites the serialized value with scrubbers, in order,
+// before it is written to or compared with the golden file. This reaches
+// volatile content in plain text output that WithIgnoreFields cannot:
+//
+//	golden.WithScrubbers(
+//		golden.ScrubTempDir(),
+//		golden.ScrubRegexp(`localhost:\d+`, "localhost:<PORT>"),
+//	)
+func WithScrubbers(scrubbers ...Scrubber) Option {
+	return func(o *Options) {
+		o.Scrubbers = append(o.Scrubbers, scrubbers...)
+	}
+}
+
+// WithComparator replaces the built-in comparator with c, which may be
+// composed from the comparator package:
+//
+//	c := comparator.NewWithOptions(comparator.Options{IgnoreOrder: true})
+//	golden.WithComparator(comparator.Transform(scrub, comparator.FirstMatch(
+//		comparator.Func(c.CompareJSON),
+//		comparator.Func(c.CompareText),
+//	)))
+func WithComparator(c comparator.Interface) Option {
+	return func(o *Options) {
+		o.Comparator = c
+	}
+}
+
+// WithFieldDecoder decodes the string values at path with decoder before
+// comparison, so that encoded blobs are compared and reported by their
+// content, e.g. WithFieldDecoder("token", comparator.DecodeJWT). Built-in
+// decoders are comparator.DecodeBase64, DecodeGzip and DecodeJWT.
+func WithFieldDecoder(path string, decoder comparator.Decoder) Option {
+	return func(o *Options) {
+		o.FieldDecoders = append(o.FieldDecoders, comparator.FieldDecoder{Path: path, Decode: decoder})
+	}
+}
+
+// WithHTML compares golden content as HTML documents, ignoring attribute
+// order, self-closing syntax and insignificant whitespace. Attributes named
+// in stripAttributes, such as "nonce" or "data-csrf", are ignored.
+func WithHTML(stripAttributes ...string) Option {
+	return func(o *Options) {
+		o.HTML = true
+		o.StripAttributes = append(o.StripAttributes, stripAttributes...)
+	}
+}
+
+// WithTokenComparison compares golden content token by token, ignoring
+// formatting-only differences, which suits code generator output. With
+// formatGo, Go source is also run through go/format before comparing, so
+// that differences between gofmt versions do not fail the build.
+func WithTokenComparison(formatGo bool) Option {
+	return func(o *Options) {
+		o.Tokens = true
+		o.FormatGo = formatGo
+	}
+}
+
+// WithFormat selects how structs, maps and slices are serialized into golden
+// files. With YAML or TOML, golden content is also compared semantically in
+// that format, so comments added by reviewers do not affect comparison,
+// although update mode rewrites the file without them.
+func WithFormat(format Format) Option {
+	return func(o *Options) {
+		o.Format = format
+	}
+}
+
+// WithJSONIndent sets the prefix and indentation JSON golden content is
+// written with, e.g. WithJSONIndent("", "\t") to match existing fixtures
+// indented with tabs. JSON is still compared semantically.
+func WithJSONIndent(prefix, indent string) Option {
+	return func(o *Options) {
+		o.JSONPrefix = prefix
+		o.JSONIndent = indent
+		o.CompactJSON = false
+	}
+}
+
+// WithCompactJSON writes JSON golden content minified on a single line.
+func WithCompactJSON(enabled bool) Option {
+	return func(o *Options) {
+		o.CompactJSON = enabled
+	}
+}
+
+// WithEscapeHTML escapes <, > and & in JSON strings as \u003c, \u003e and
+// \u0026, as json.Marshal does. By default they are written as they are,
+// which keeps HTML-bearing JSON readable.
+func WithEscapeHTML(enabled bool) Option {
+	return func(o *Options) {
+		o.EscapeHTML = enabled
+	}
+}
+
+// WithUnexportedFields includes unexported struct fields in the output of
+// the GoDump format, which JSON silently drops.
+func WithUnexportedFields(enabled bool) Option {
+	return func(o *Options) {
+		o.UnexportedFields = enabled
+	}
+}
+
+// WithTimeFormat renders every time.Time in layout and loc, and every
+// time.Duration in its human form, e.g. "1h30m0s", instead of what
+// encoding/json produces, so that golden files do not depend on the time
+// zone of the machine. An empty layout means time.RFC3339 and a nil loc
+// means UTC. It applies to the JSON, YAML and TOML formats.
+func WithTimeFormat(layout string, loc *time.Location) Option {
+	return func(o *Options) {
+		o.FormatTimes = true
+		o.TimeLayout = layout
+		o.TimeLocation = loc
+	}
+}
+
+// WithPreferStringer snapshots values implementing encoding.TextMarshaler
+// or fmt.Stringer through those methods instead of serializing them. Nested
+// values are rendered as strings in the JSON, YAML and TOML formats.
+func WithPreferStringer(enabled bool) Option {
+	return func(o *Options) {
+		o.PreferStringer = enabled
+	}
+}
+
+// WithImageTolerance makes AssertImage tolerate pixels whose channels differ
+// by at most channel, out of 255, and up to maxDiffPercent percent of pixels
+// differing by more, e.g. WithImageTolerance(2, 0.5) for anti-aliasing noise.
+func WithImageTolerance(channel uint8, maxDiffPercent float64) Option {
+	return func(o *Options) {
+		o.ImageTolerance = channel
+		o.ImageMaxDiffPercent = maxDiffPercent
+	}
+}
+
+// WithHTTPHeaders limits the headers snapshotted by AssertHTTPResponse and
+// AssertResponseRecorder to names.
+func WithHTTPHeaders(names ...string) Option {
+	return func(o *Options) {
+		o.HTTPHeaders = append(o.HTTPHeaders, names...)
+	}
+}
+
+// WithDirExcludes skips the files and directories matching patterns in
+// AssertDir. Patterns use path.Match syntax and match either the
+// slash-separated path relative to the root or the base name, e.g. ".git"
+// or "*.log".
+func WithDirExcludes(patterns ...string) Option {
+	return func(o *Options) {
+		o.DirExcludes = append(o.DirExcludes, patterns...)
+	}
+}
+
+// WithFileScrubbers applies scrubbers to the files matching pattern in
+// AssertDir, before the scrubbers set with WithScrubbers. Patterns match
+// like those of WithDirExcludes.
+func WithFileScrubbers(pattern string, scrubbers ...Scrubber) Option {
+	return func(o *Options) {
+		o.FileScrubbers = append(o.FileScrubbers, FileScrubber{Pattern: pattern, Scrubbers: scrubbers})
+	}
+}
+
+// WithArchiveContents stores the content of the archive entries matching
+// patterns in AssertArchive, in addition to the listing of all entries.
+// Patterns match like those of WithDirExcludes.
+func WithArchiveContents(patterns ...string) Option {
+	return func(o *Options) {
+		o.ArchiveContents = append(o.ArchiveContents, patterns...)
+	}
+}
+
+// WithNamingStrategy names golden files with s instead of
+// manager.DefaultNaming, e.g. manager.NestedNaming for a directory per test,
+// or a custom strategy following an existing fixture layout such as
+// "<test>/<name>.json". Filenames are relative to the base directory.
+func WithNamingStrategy(s manager.NamingStrategy) Option {
+	return func(o *Options) {
+		o.NamingStrategy = s
+	}
+}
+
+// WithFS reads golden files from fsys instead of the local disk, e.g. from
+// testdata embedded with go:embed, so that golden comparisons also run in
+// binaries built without the source checkout. fsys is rooted at the package
+// directory, as embedded files are: with the default base directory, golden
+// files are read from fsys under testdata/. Update mode still writes to the
+// local disk, refreshing the files embedded by the next build when run from
+// the checkout.
+func WithFS(fsys fs.FS) Option {
+	return func(o *Options) {
+		o.FS = fsys
+	}
+}
+
+// WithMemory reads and writes golden files in mem instead of on disk, e.g.
+// for tests of helpers built on Golden that should not touch testdata, or
+// for dry runs of update mode whose would-be writes are then listed with
+// mem.Files.
+func WithMemory(mem *manager.MemoryFS) Option {
+	return func(o *Options) {
+		o.Memory = mem
+	}
+}
+
+// WithRemoteBaseline fetches golden files missing locally from baseURL, so
+// that outputs can be compared with those of e.g. the last released build
+// without committing them. Every golden file must be published along with
+// its sha256 checksum, as baseURL/<path>.sha256 for baseURL/<path>, where
+// <path> is relative to the base directory. Baselines are cached by checksum
+// in the user cache directory. See manager.Manager.SetRemote.
+func WithRemoteBaseline(baseURL string) Option {
+	return func(o *Options) {
+		o.RemoteBaseline = baseURL
+	}
+}
+
+// WithPlatform creates golden files specific to the current platform, such
+// as name.linux.golden.go with manager.PlatformOS or
+// name.windows_amd64.golden.go with manager.PlatformOSArch, for output that
+// legitimately differs between platforms, e.g. paths or error messages.
+// Whatever the option, the most specific existing variant is used, falling
+// back to the generic golden file, so platform variants only need to exist
+// where output differs.
+func WithPlatform(p manager.Platform) Option {
+	return func(o *Options) {
+		o.Platform = p
+	}
+}
+
+// WithMaxFileSize sets the maximum size in bytes of golden files, 50MB by
+// default. Assertions whose output exceeds it fail instead of writing the
+// golden file, which protects the repository from accidental snapshots of
+// huge values. Zero or less disables the limit.
+func WithMaxFileSize(size int64) Option {
+	return func(o *Options) {
+		o.maxFileSize = size
+	}
+}
+
+// WithSizeBudget fails update mode when a golden file would exceed budget
+// bytes, keeping fixtures small. Unlike WithMaxFileSize, a safety limit that
+// also applies when reading, it only concerns updates, so existing golden
+// files over budget keep being compared until they are next updated. Zero or
+// less disables the budget, which is the default. The golden-footprint
+// command reports the golden files of a repository against a budget.
+func WithSizeBudget(budget int64) Option {
+	return func(o *Options) {
+		o.SizeBudget = budget
+	}
+}
+
+// WithMetadataHeader prefixes the golden files written in update mode with
+// a comment header recording the library version, test name, creation time
+// and content format, giving reviewers and tooling the provenance of
+// fixtures. Headers are stripped before comparison, whatever the option, and
+// are not added to binary, blob or deduplicated golden files. See
+// manager.ParseHeader to read them.
+func WithMetadataHeader(enabled bool) Option {
+	return func(o *Options) {
+		o.MetadataHeader = enabled
+	}
+}
+
+// WithFileExtensions names new golden files with an extension matching
+// their content, e.g. .golden.json, .golden.yaml, .golden.txt or .golden.bin,
+// instead of .golden.go, so that editors and code review tools highlight
+// them. Existing .golden.go files keep being used. It does not apply with
+// WithCompression or naming strategies that choose their own extension.
+func WithFileExtensions(enabled bool) Option {
+	return func(o *Options) {
+		o.FileExtensions = enabled
+	}
+}
+
+// WithCompression gzips golden files, which are then named *.golden.gz, to
+// keep large golden files small in the repository. Existing *.golden.gz
+// files are read transparently without it.
+func WithCompression(enabled bool) Option {
+	return func(o *Options) {
+		o.Compression = enabled
+	}
+}
+
+// WithBlobThreshold stores golden content larger than size bytes in a
+// content-addressed blob, named by its sha256 under the blobs directory, and
+// keeps only a small reference file in its place. This keeps giant binary
+// snapshots out of normal diffs. See Golden.CollectBlobs for removing blobs
+// that are no longer referenced.
+func WithBlobThreshold(size int) Option {
+	return func(o *Options) {
+		o.BlobThreshold = size
+	}
+}
+
+// WithOpenAPIIgnoreDocs ignores info.version and the descriptions and
+// summaries of AssertOpenAPI documents, which churn without changing the
+// API.
+func WithOpenAPIIgnoreDocs(enabled bool) Option {
+	return func(o *Options) {
+		o.OpenAPIIgnoreDocs = enabled
+	}
+}
+
+// WithWhitespacePolicy sets the whitespace normalizations AssertTemplate
+// applies, DefaultWhitespacePolicy by default. Use KeepWhitespace to store
+// the output as rendered.
+func WithWhitespacePolicy(policy WhitespacePolicy) Option {
+	return func(o *Options) {
+		o.WhitespacePolicy = policy
+	}
+}
+
+// WithTableIgnoreColumns leaves the columns with the given headers out of
+// AssertTable comparisons. The golden file still records them.
+func WithTableIgnoreColumns(columns ...string) Option {
+	return func(o *Options) {
+		o.TableIgnoreColumns = append(o.TableIgnoreColumns, columns...)
+	}
+}
+
+// WithUnorderedRows compares the rows of AssertTable regardless of their
+// order, keeping the header first.
+func WithUnorderedRows(enabled bool) Option {
+	return func(o *Options) {
+		o.UnorderedRows = enabled
+	}
+}
+
+// WithSQLParams sets how AssertSQL renders bind parameters.
+func WithSQLParams(mode SQLParams) Option {
+	return func(o *Options) {
+		o.SQLParams = mode
+	}
+}
+
+// WithRawBytes stores strings and byte slices exactly as passed and compares
+// them byte for byte, instead of reformatting and comparing content that
+// looks like JSON semantically, e.g. log lines starting with "[".
+func WithRawBytes(enabled bool) Option {
+	return func(o *Options) {
+		o.RawBytes = enabled
+	}
+}
+
+// WithEncoding declares the encoding of golden content, which is decoded to
+// UTF-8 for comparison and diffs. Without it, UTF-8 and UTF-16 BOMs, UTF-16
+// of ASCII text and Latin-1 are detected, and other binary content is
+// diffed as hex dumps.
+func WithEncoding(enc encoding.Encoding) Option {
+	return func(o *Options) {
+		o.Encoding = enc
+	}
+}
+
+// WithSerializer serializes structs, maps and slices with s instead of the
+// serializer of the configured format, e.g. to write MessagePack or CBOR.
+// Registering s with RegisterSerializer is not required.
+func WithSerializer(s Serializer) Option {
+	return func(o *Options) {
+		o.Serializer = s
+	}
+}
+
+// WithYAML compares golden content as a stream of "---" separated YAML
+// documents, semantically like JSON. Documents are paired by index, or by
+// the values of documentKeys when given, e.g. WithYAML("kind",
+// "metadata.name") for rendered Kubernetes manifests, so that reordering
+// documents does not matter and each document is diffed on its own.
+func WithYAML(documentKeys ...string) Option {
+	return func(o *Options) {
+		o.YAML = true
+		o.YAMLDocumentKeys = append(o.YAMLDocumentKeys, documentKeys...)
+	}
+}
+
 // WithCustomCompare sets custom comparison function for special cases.
 func WithCustomCompare(fn func(expected, actual []byte) bool) Option {
 	return func(o *Options) {
@@ -59,6 +739,31 @@ func WithCustomCompare(fn func(expected, actual []byte) bool) Option {
 	}
 }
 
+// WithDiffAnchorKey aligns elements of JSON arrays of objects by the given key
+// (e.g. "id") when rendering diffs, instead of by index.
+func WithDiffAnchorKey(key string) Option {
+	return func(o *Options) {
+		o.DiffAnchorKey = key
+	}
+}
+
+// WithDiffAlgorithmName selects a diff algorithm registered with differ.Register,
+// or one of the built-in "simple", "myers" and "auto", taking precedence over
+// WithDiffAnchorKey. New fails the test if no algorithm is registered under name.
+func WithDiffAlgorithmName(name string) Option {
+	return func(o *Options) {
+		o.DiffAlgorithm = name
+	}
+}
+
+// WithDetectMoves renders blocks of at least two lines that were moved
+// rather than added and removed with a distinct "moved" marker in diffs.
+func WithDetectMoves(enabled bool) Option {
+	return func(o *Options) {
+		o.DetectMoves = enabled
+	}
+}
+
 // WithBaseDir sets a custom base directory for golden files.
 // Default is "testdata".
 func WithBaseDir(dir string) Option {
@@ -67,15 +772,66 @@ func WithBaseDir(dir string) Option {
 	}
 }
 
+// WithFollowSymlinks controls whether golden paths may traverse symlinks.
+// Disable it in security-sensitive environments to refuse symlinked
+// testdata directories and golden files.
+func WithFollowSymlinks(follow bool) Option {
+	return func(o *Options) {
+		o.FollowSymlinks = follow
+	}
+}
+
+// WithSymlinkRoots allows followed symlinks to point into dirs, such as a
+// shared fixtures repository, besides the base directory. Golden paths
+// linking anywhere else fail, as do golden names escaping the base
+// directory with "..".
+func WithSymlinkRoots(dirs ...string) Option {
+	return func(o *Options) {
+		o.SymlinkRoots = append(o.SymlinkRoots, dirs...)
+	}
+}
+
+// WithHyperlinks controls whether failure output renders the golden file
+// path as a clickable terminal hyperlink, along with a golden://accept link
+// handled by cmd/golden-accept, overriding terminal detection.
+func WithHyperlinks(enabled bool) Option {
+	return func(o *Options) {
+		o.Hyperlinks = enabled
+	}
+}
+
+// WithDiffWidth sets the width diff lines are soft-wrapped to.
+// Zero disables wrapping. The default is taken from the COLUMNS environment variable.
+func WithDiffWidth(width int) Option {
+	return func(o *Options) {
+		o.DiffWidth = width
+	}
+}
+
 // defaultOptions returns default configuration.
 func defaultOptions() *Options {
 	return &Options{
 		// Default values
-		Update: isUpdateModeFromEnv(), // Check GOLDEN_UPDATE environment variable
+		Update:         isUpdateModeFromEnv(),                  // Check GOLDEN_UPDATE environment variable
+		ForceImmutable: isEnvEnabled("GOLDEN_FORCE_IMMUTABLE"), // Check GOLDEN_FORCE_IMMUTABLE environment variable
+		ReadOnly:       isReadOnlyFromEnv(),                    // Check GOLDEN_READ_ONLY and CI environment variables
 
 		// JSON comparison defaults
 		IgnoreOrder: true, // Ignore array order for JSON
 
+		// Serialization defaults
+		JSONIndent: "  ",
+
+		// Template defaults
+		WhitespacePolicy: DefaultWhitespacePolicy,
+
+		// Path defaults
+		FollowSymlinks: true,
+
+		// Output defaults
+		Hyperlinks: supportsHyperlinks(),
+		DiffWidth:  terminalWidth(),
+
 		// Internal settings
 		contextLines: 3,                // Context lines in diff
 		bufferSize:   8192,             // File buffer size
@@ -87,7 +843,22 @@ func defaultOptions() *Options {
 
 // isUpdateModeFromEnv checks if update mode is enabled via GOLDEN_UPDATE environment variable.
 func isUpdateModeFromEnv() bool {
-	env := os.Getenv("GOLDEN_UPDATE")
+	return isEnvEnabled("GOLDEN_UPDATE")
+}
+
+// isReadOnlyFromEnv checks if golden files are read-only via the
+// GOLDEN_READ_ONLY environment variable, defaulting to true on CI.
+func isReadOnlyFromEnv() bool {
+	if _, ok := os.LookupEnv("GOLDEN_READ_ONLY"); ok {
+		return isEnvEnabled("GOLDEN_READ_ONLY")
+	}
+
+	return isEnvEnabled("CI")
+}
+
+// isEnvEnabled checks if the environment variable is set to "true".
+func isEnvEnabled(name string) bool {
+	env := os.Getenv(name)
 	if env == "" {
 		return false
 	}
@@ -96,3 +867,13 @@ func isUpdateModeFromEnv() bool {
 
 	return env == "true"
 }
+
+// terminalWidth returns the terminal width from the COLUMNS environment variable, or 0 if unknown.
+func terminalWidth() int {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || width < 0 {
+		return 0
+	}
+
+	return width
+}
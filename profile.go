@@ -0,0 +1,114 @@
+package golden
+
+import (
+	"bytes"
+	"regexp"
+	"runtime/pprof"
+	"slices"
+	"strings"
+)
+
+// profileRewrites normalize the lines of goroutine dumps and text profiles.
+// They are applied in order to every line.
+var profileRewrites = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// Goroutine headers, dropping wait times: "goroutine 7 [chan receive, 2 minutes]:"
+	{regexp.MustCompile(`^goroutine \d+ \[([^,\]]+)[^\]]*\]:$`), "goroutine <ID> [$1]:"},
+	{regexp.MustCompile(` in goroutine \d+$`), " in goroutine <ID>"},
+	// Source positions of frames: "\t/usr/local/go/src/testing/testing.go:1690 +0x1d"
+	{regexp.MustCompile(`^\t(?:.*/)?([^/\s]+\.go):\d+(?: \+0x[0-9a-f]+)?$`), "\t$1"},
+	// Call arguments of frames: "main.worker(0xc000010000, 0x2)"
+	{regexp.MustCompile(`^(\S.*)\([^()]*\)$`), "$1(...)"},
+	// Text profile headers and records: "goroutine profile: total 4", "1 @ 0x43a5b6 0x4056ac"
+	{regexp.MustCompile(`^(\w+ profile: total )\d+$`), "${1}<N>"},
+	{regexp.MustCompile(`^(\w+ profile: )\d+: \d+ \[\d+: \d+\] @ `), "${1}<N>: <N> [<N>: <N>] @ "},
+	{regexp.MustCompile(`^\d+ @(?: 0x[0-9a-f]+)*$`), "<N> @"},
+	{regexp.MustCompile(`^\d+: \d+ \[\d+: \d+\] @(?: 0x[0-9a-f]+)*$`), "<N>: <N> [<N>: <N>] @"},
+	// Frames of text profiles: "#\t0x4f2f25\truntime/pprof.writeRuntimeProfile+0xa5\t/src/pprof.go:796"
+	{regexp.MustCompile(`^#\t0x[0-9a-f]+\t(\S+?)\+0x[0-9a-f]+\t+(?:.*/)?([^/\s]+\.go):\d+$`), "#\t$1\t$2"},
+}
+
+// AssertGoroutines compares the stacks of the running goroutines with the
+// golden file name, normalized as by AssertProfile. Goroutines of tests
+// running in parallel are included, so it is only reliable in tests that
+// do not run in parallel.
+func (g *Golden) AssertGoroutines(name string) {
+	g.t.Helper()
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		g.t.Fatalf("Failed to dump goroutines: %v", err)
+	}
+
+	g.AssertProfile(name, buf.Bytes())
+}
+
+// AssertProfile compares a goroutine dump, as written by panics or by the
+// goroutine profile with debug=2, or a pprof text profile, written with
+// debug=1, with the golden file name. Goroutine IDs, wait times, addresses,
+// call arguments, line numbers and sample counts are normalized, runtime
+// memory statistics are dropped, and identical stacks are listed once in
+// sorted order, so that the snapshot only changes with the set of stacks.
+func (g *Golden) AssertProfile(name string, profile []byte) {
+	g.t.Helper()
+
+	g.assertBytes(name, normalizeProfile(profile))
+}
+
+// normalizeProfile normalizes the lines of profile and deduplicates and
+// sorts its blank line separated stacks.
+func normalizeProfile(profile []byte) []byte {
+	text := string(profile)
+
+	// Memory statistics at the end of heap profiles change on every run
+	if i := strings.Index(text, "\n# runtime.MemStats"); i >= 0 {
+		text = text[:i]
+	}
+
+	var header string
+
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) > 0 && strings.Contains(lines[0], " profile: ") {
+		header, lines = normalizeProfileLine(lines[0]), lines[1:]
+	}
+
+	var (
+		blocks []string
+		block  []string
+	)
+
+	for _, line := range append(lines, "") {
+		if strings.TrimSpace(line) != "" {
+			block = append(block, normalizeProfileLine(line))
+
+			continue
+		}
+
+		if len(block) > 0 {
+			blocks = append(blocks, strings.Join(block, "\n"))
+			block = nil
+		}
+	}
+
+	slices.Sort(blocks)
+	blocks = slices.Compact(blocks)
+
+	if header != "" {
+		blocks = append([]string{header}, blocks...)
+	}
+
+	return ScrubMemoryAddresses()([]byte(strings.Join(blocks, "\n\n") + "\n"))
+}
+
+// normalizeProfileLine applies profileRewrites to line.
+func normalizeProfileLine(line string) string {
+	line = strings.TrimRight(line, " \r")
+
+	for _, rewrite := range profileRewrites {
+		line = rewrite.pattern.ReplaceAllString(line, rewrite.replacement)
+	}
+
+	return line
+}
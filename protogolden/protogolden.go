@@ -0,0 +1,60 @@
+// Package protogolden provides golden testing of protocol buffer messages.
+//
+// Messages are serialized with protojson using the original proto field
+// names, so ignore-field paths passed to golden.WithIgnoreFields are written
+// as proto field names, e.g. "options.java_package".
+package protogolden
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sivchari/golden"
+	"github.com/sivchari/golden/comparator"
+)
+
+// Marshal serializes msg to deterministic, indented JSON. protojson output
+// deliberately varies in whitespace between runs, so it is rewritten in the
+// canonical form golden files are written in: keys sorted, two-space
+// indentation and numbers as protojson wrote them.
+func Marshal(msg proto.Message) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %w", msg, err)
+	}
+
+	parsed, err := comparator.DecodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %w", msg, err)
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(parsed); err != nil {
+		return nil, fmt.Errorf("failed to format %T: %w", msg, err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Assert compares msg with the golden file name of g, semantically and
+// honoring the options g was created with.
+func Assert(tb testing.TB, g *golden.Golden, name string, msg proto.Message) {
+	tb.Helper()
+
+	data, err := Marshal(msg)
+	if err != nil {
+		tb.Fatalf("Failed to serialize %s: %v", name, err)
+	}
+
+	g.Assert(name, data)
+}
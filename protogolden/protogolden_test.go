@@ -0,0 +1,81 @@
+package protogolden
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/sivchari/golden"
+)
+
+func TestMarshalUsesProtoNames(t *testing.T) {
+	t.Parallel()
+
+	msg := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("user.proto"),
+		Options: &descriptorpb.FileOptions{JavaPackage: proto.String("com.example")},
+	}
+
+	first, err := Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	for range 5 {
+		again, err := Marshal(msg)
+		if err != nil || string(again) != string(first) {
+			t.Fatalf("Marshal() is not deterministic: %s != %s", again, first)
+		}
+	}
+
+	if !strings.Contains(string(first), `"java_package": "com.example"`) {
+		t.Errorf("Marshal() = %s, want proto field names", first)
+	}
+}
+
+func TestAssertIgnoresProtoFields(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	msg := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("user.proto"),
+		Options: &descriptorpb.FileOptions{JavaPackage: proto.String("com.example"), GoPackage: proto.String("example/v1")},
+	}
+
+	Assert(t, golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false), golden.WithBaseDir(dir)), "file", msg)
+
+	msg.Options.JavaPackage = proto.String("org.example")
+	Assert(t, golden.New(t, golden.WithUpdate(false), golden.WithBaseDir(dir), golden.WithIgnoreFields("options.java_package")), "file", msg)
+}
+
+func TestMarshalMatchesGoldenFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	msg := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("user.proto"),
+		Package:    proto.String("user.v1"),
+		Dependency: []string{"b.proto", "a.proto"},
+		Options:    &descriptorpb.FileOptions{JavaPackage: proto.String("com.example"), OptimizeFor: descriptorpb.FileOptions_SPEED.Enum()},
+	}
+
+	data, err := Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	Assert(t, golden.New(t, golden.WithUpdate(true), golden.WithReadOnly(false), golden.WithBaseDir(dir)), "file", msg)
+
+	written, err := os.ReadFile(filepath.Join(dir, "protogolden_test_TestMarshalMatchesGoldenFile_file.golden.go"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	if string(written) != string(data) {
+		t.Errorf("golden file = %s, want Marshal() output %s", written, data)
+	}
+}
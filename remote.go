@@ -0,0 +1,16 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// remoteCacheDir returns the directory caching remote baselines.
+func remoteCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "golden", "baselines")
+}
@@ -0,0 +1,424 @@
+package golden
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sivchari/golden/differ"
+)
+
+// reportEnv is the environment variable naming the archive Main writes the
+// run report to.
+const reportEnv = "GOLDEN_REPORT"
+
+// Statuses of the golden files in a run report.
+const (
+	reportCompared = "compared"
+	reportUpdated  = "updated"
+	reportFailed   = "failed"
+)
+
+// ansiPattern matches the escape sequences coloring diffs and linking
+// files in them.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m|\x1b\]8;;[^\x1b]*\x1b\\`)
+
+// ReportEntry describes a golden file touched during the run, as listed in
+// the manifest of the report.
+type ReportEntry struct {
+	Test   string `json:"test"`
+	Name   string `json:"name"`
+	Golden string `json:"golden"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`   // Path of the diff in the report
+	Actual string `json:"actual,omitempty"` // Path of the actual output in the report
+
+	// Magnitude of a failure: the number of paths whose values differ, for
+	// structured formats, and of changed lines in the diff
+	ChangedPaths int `json:"changedPaths,omitempty"`
+	ChangedLines int `json:"changedLines,omitempty"`
+
+	diff   string
+	actual []byte
+	read   func(filename string) ([]byte, error) // Reads Golden through the manager that produced it
+}
+
+// Report is the manifest of a run report.
+type Report struct {
+	Files    []ReportEntry `json:"files"`              // Golden files touched by the run, in order
+	Failures []ReportEntry `json:"failures,omitempty"` // Failed golden files, most changed first
+}
+
+var (
+	// reportMu guards report.
+	reportMu sync.Mutex
+	// report records the golden files touched by the run, in order.
+	report []*ReportEntry
+)
+
+// recordTouch records that the test is about to compare or update filename.
+func (g *Golden) recordTouch(name, filename string) {
+	status := reportCompared
+	if g.options.Update {
+		status = reportUpdated
+	}
+
+	reportMu.Lock()
+	defer reportMu.Unlock()
+
+	report = append(report, &ReportEntry{
+		Test: g.t.Name(), Name: name, Golden: filename, Status: status,
+		read: g.manager.ReadFile,
+	})
+}
+
+// recordFailure records the diff and, if known, the actual output of a
+// failed comparison with filename.
+func recordFailure(filename, diff string, actual []byte) {
+	reportMu.Lock()
+	defer reportMu.Unlock()
+
+	for i := len(report) - 1; i >= 0; i-- {
+		if entry := report[i]; entry.Golden == filename {
+			entry.Status = reportFailed
+			entry.diff += ansiPattern.ReplaceAllString(diff, "")
+
+			if actual != nil {
+				entry.actual = actual
+			}
+
+			return
+		}
+	}
+}
+
+// recordMagnitude adds the changed paths and lines of a failed comparison
+// with filename to its report entry.
+func recordMagnitude(filename string, paths int, diff *differ.Diff) {
+	lines := 0
+
+	for _, chunk := range diff.Chunks {
+		if chunk.Type != differ.ChunkEqual {
+			lines += max(chunk.CountA, chunk.CountB)
+		}
+	}
+
+	reportMu.Lock()
+	defer reportMu.Unlock()
+
+	for i := len(report) - 1; i >= 0; i-- {
+		if entry := report[i]; entry.Golden == filename {
+			entry.ChangedPaths += paths
+			entry.ChangedLines += lines
+
+			return
+		}
+	}
+}
+
+// rankFailures returns the failed entries of files, those with the most
+// changed paths, then lines, first.
+func rankFailures(files []ReportEntry) []ReportEntry {
+	var failures []ReportEntry
+
+	for _, entry := range files {
+		if entry.Status == reportFailed {
+			failures = append(failures, entry)
+		}
+	}
+
+	sort.SliceStable(failures, func(i, j int) bool {
+		if failures[i].ChangedPaths != failures[j].ChangedPaths {
+			return failures[i].ChangedPaths > failures[j].ChangedPaths
+		}
+
+		return failures[i].ChangedLines > failures[j].ChangedLines
+	})
+
+	return failures
+}
+
+// WriteFailures writes the failures of the report to w, most changed first,
+// so that the most substantive regressions are looked at first.
+func (r *Report) WriteFailures(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d golden file(s) failed, most changed first:\n", len(r.Failures))
+
+	for _, entry := range r.Failures {
+		fmt.Fprintf(&b, "  %4d path(s) %4d line(s)  %s (%s)\n", entry.ChangedPaths, entry.ChangedLines, entry.Golden, entry.Test)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write failures: %w", err)
+	}
+
+	return nil
+}
+
+// ReportComparison compares the failures of a run report with those of a
+// previous run, e.g. the report attached to the previous CI run. Golden
+// files are identified by path.
+type ReportComparison struct {
+	NewlyBroken  []ReportEntry // Failed now, not before
+	Fixed        []ReportEntry // Failed before, touched without failing now
+	StillFailing []ReportEntry // Failed in both runs
+}
+
+// CompareReports compares the failures of current with those of previous.
+// Failures of previous whose golden file is not touched by current, e.g.
+// because its test was removed or skipped, are neither fixed nor failing.
+func CompareReports(previous, current *Report) *ReportComparison {
+	failedBefore := make(map[string]bool)
+	for _, entry := range previous.Failures {
+		failedBefore[entry.Golden] = true
+	}
+
+	comparison := &ReportComparison{}
+	failedNow := make(map[string]bool)
+
+	for _, entry := range current.Failures {
+		failedNow[entry.Golden] = true
+
+		if failedBefore[entry.Golden] {
+			comparison.StillFailing = append(comparison.StillFailing, entry)
+		} else {
+			comparison.NewlyBroken = append(comparison.NewlyBroken, entry)
+		}
+	}
+
+	touched := make(map[string]bool)
+	for _, entry := range current.Files {
+		touched[entry.Golden] = true
+	}
+
+	for _, entry := range previous.Failures {
+		if touched[entry.Golden] && !failedNow[entry.Golden] {
+			comparison.Fixed = append(comparison.Fixed, entry)
+			failedNow[entry.Golden] = true // List each golden file once
+		}
+	}
+
+	return comparison
+}
+
+// Write writes the comparison to w, each list most changed first.
+func (c *ReportComparison) Write(w io.Writer) error {
+	var b strings.Builder
+
+	for _, group := range []struct {
+		title   string
+		entries []ReportEntry
+	}{
+		{"Newly broken", c.NewlyBroken},
+		{"Still failing", c.StillFailing},
+		{"Fixed", c.Fixed},
+	} {
+		fmt.Fprintf(&b, "%s: %d\n", group.title, len(group.entries))
+
+		for _, entry := range group.entries {
+			fmt.Fprintf(&b, "  %s (%s)\n", entry.Golden, entry.Test)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write report comparison: %w", err)
+	}
+
+	return nil
+}
+
+// currentReport returns the report of the tests run so far in the process,
+// without the paths of diffs and actual outputs set when archiving it.
+func currentReport() *Report {
+	reportMu.Lock()
+	defer reportMu.Unlock()
+
+	files := make([]ReportEntry, len(report))
+	for i, entry := range report {
+		files[i] = *entry
+	}
+
+	return &Report{Files: files, Failures: rankFailures(files)}
+}
+
+// ReadReport reads the manifest of the report archive path written by
+// WriteReport.
+func ReadReport(path string) (*Report, error) {
+	file, err := os.Open(path) //nolint:gosec // G304: The report path is chosen by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report %s: %w", path, err)
+	}
+
+	defer file.Close()
+
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+
+	tr := tar.NewReader(gr)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("report %s has no manifest.json", path)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+		}
+
+		if header.Name != "manifest.json" {
+			continue
+		}
+
+		var r Report
+		if err := json.NewDecoder(tr).Decode(&r); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest of report %s: %w", path, err)
+		}
+
+		return &r, nil
+	}
+}
+
+// WriteReport bundles the golden files touched by the tests run so far in
+// the process, with the diffs and actual outputs of failed comparisons,
+// into the tar.gz archive path, e.g. to attach it to a CI run. The archive
+// holds manifest.json, a Report listing for every golden file the test that
+// produced it, its status (compared, updated or failed), the paths of its
+// diff and actual output under failures/ and the magnitude of its failure,
+// and the golden files under golden/. It is written by Main when the
+// GOLDEN_REPORT environment variable names the archive, and read back by
+// ReadReport.
+func WriteReport(path string) error {
+	entries := currentReport().Files
+
+	file, err := os.Create(path) //nolint:gosec // G304: The report path is chosen by the caller
+	if err != nil {
+		return fmt.Errorf("failed to create report %s: %w", path, err)
+	}
+
+	defer file.Close()
+
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+
+	if err := writeReportEntries(tw, entries); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+
+	return file.Close() //nolint:wrapcheck // Closing twice is harmless, the deferred close is a fallback
+}
+
+// writeReportEntries writes the golden files, failures and manifest of
+// entries to tw.
+func writeReportEntries(tw *tar.Writer, entries []ReportEntry) error {
+	written := make(map[string]bool)
+
+	for i := range entries {
+		entry := &entries[i]
+
+		if entry.Status == reportFailed {
+			base := fmt.Sprintf("failures/%04d", i+1)
+			entry.Diff = base + ".diff"
+
+			if err := writeTarFile(tw, entry.Diff, []byte(entry.diff)); err != nil {
+				return err
+			}
+
+			if entry.actual != nil {
+				entry.Actual = base + ".actual"
+
+				if err := writeTarFile(tw, entry.Actual, entry.actual); err != nil {
+					return err
+				}
+			}
+		}
+
+		name := archiveName(entry.Golden)
+		if written[name] {
+			continue
+		}
+
+		read := entry.read
+		if read == nil {
+			read = os.ReadFile
+		}
+
+		// Golden files missing, e.g. never created, are only listed
+		data, err := read(entry.Golden)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read golden file %s: %w", entry.Golden, err)
+		}
+
+		if err := writeTarFile(tw, name, data); err != nil {
+			return err
+		}
+
+		written[name] = true
+	}
+
+	manifest, err := json.MarshalIndent(Report{Files: entries, Failures: rankFailures(entries)}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	return writeTarFile(tw, "manifest.json", append(manifest, '\n'))
+}
+
+// archiveName returns where the golden file filename is stored in the
+// report, under golden/ whether filename is absolute or outside the working
+// directory.
+func archiveName(filename string) string {
+	name := path.Clean(filepath.ToSlash(filename))
+	for strings.HasPrefix(name, "../") {
+		name = name[len("../"):]
+	}
+
+	return "golden/" + strings.TrimLeft(name, "/")
+}
+
+// writeTarFile adds a regular file to tw.
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	return nil
+}
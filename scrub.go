@@ -0,0 +1,108 @@
+package golden
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Scrubber rewrites serialized output before it is written to or compared
+// with a golden file, e.g. to replace temporary paths, hostnames, ports or
+// memory addresses that vary between runs.
+type Scrubber func(data []byte) []byte
+
+var (
+	// memoryAddressPattern matches hexadecimal pointers such as 0xc000012345.
+	memoryAddressPattern = regexp.MustCompile(`0x[0-9a-fA-F]{6,16}\b`)
+	// timestampPattern matches RFC 3339 timestamps and the log package's
+	// "2006/01/02 15:04:05" format, with optional fractional seconds.
+	timestampPattern = regexp.MustCompile(`\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+	// goroutineIDPattern matches goroutine IDs as written in stack traces
+	// and log attributes, e.g. "goroutine 42" or "goroutine=42".
+	goroutineIDPattern = regexp.MustCompile(`(goroutine(?:[ =:]|": ?))\d+`)
+	// fileLinePattern matches Go source positions such as "pkg/file.go:42".
+	fileLinePattern = regexp.MustCompile(`[\w./\\-]*\w\.go:\d+(?::\d+)?`)
+	// durationPattern matches durations formatted by time.Duration.String.
+	durationPattern = regexp.MustCompile(`\b(?:\d+(?:\.\d+)?(?:ns|us|µs|ms|h|m|s))+\b`)
+)
+
+// ScrubRegexp replaces the matches of pattern with replacement, which may
+// refer to submatches as in regexp.Regexp.ReplaceAll. It panics if pattern
+// does not compile, like regexp.MustCompile.
+func ScrubRegexp(pattern, replacement string) Scrubber {
+	re := regexp.MustCompile(pattern)
+	repl := []byte(replacement)
+
+	return func(data []byte) []byte {
+		return re.ReplaceAll(data, repl)
+	}
+}
+
+// ScrubTempDir replaces the system temporary directory, as returned by
+// os.TempDir, with "<TMPDIR>".
+func ScrubTempDir() Scrubber {
+	dirs := [][]byte{[]byte(os.TempDir())}
+
+	// The directory may be reported through a symlink, e.g. /var -> /private/var on macOS
+	if resolved, err := filepath.EvalSymlinks(os.TempDir()); err == nil && resolved != os.TempDir() {
+		dirs = append([][]byte{[]byte(resolved)}, dirs...)
+	}
+
+	return func(data []byte) []byte {
+		for _, dir := range dirs {
+			data = bytes.ReplaceAll(data, dir, []byte("<TMPDIR>"))
+		}
+
+		return data
+	}
+}
+
+// ScrubMemoryAddresses replaces hexadecimal pointers such as 0xc000012345
+// with "<ADDR>".
+func ScrubMemoryAddresses() Scrubber {
+	return func(data []byte) []byte {
+		return memoryAddressPattern.ReplaceAll(data, []byte("<ADDR>"))
+	}
+}
+
+// ScrubTimestamps replaces RFC 3339 timestamps, such as those of slog, and
+// timestamps in the log package's default format with "<TIMESTAMP>".
+func ScrubTimestamps() Scrubber {
+	return func(data []byte) []byte {
+		return timestampPattern.ReplaceAll(data, []byte("<TIMESTAMP>"))
+	}
+}
+
+// ScrubGoroutineIDs replaces goroutine IDs, e.g. in "goroutine 42 [running]",
+// with "<ID>".
+func ScrubGoroutineIDs() Scrubber {
+	return func(data []byte) []byte {
+		return goroutineIDPattern.ReplaceAll(data, []byte("${1}<ID>"))
+	}
+}
+
+// ScrubDurations replaces durations formatted by time.Duration.String, such
+// as "1.5s" or "2m3s", with "<DURATION>".
+func ScrubDurations() Scrubber {
+	return func(data []byte) []byte {
+		return durationPattern.ReplaceAll(data, []byte("<DURATION>"))
+	}
+}
+
+// ScrubFileLines replaces Go source positions, such as "pkg/file.go:42" in
+// stack traces or error messages, with "<FILE:LINE>".
+func ScrubFileLines() Scrubber {
+	return func(data []byte) []byte {
+		return fileLinePattern.ReplaceAll(data, []byte("<FILE:LINE>"))
+	}
+}
+
+// scrub applies the configured scrubbers in order.
+func (g *Golden) scrub(data []byte) []byte {
+	for _, scrubber := range g.options.Scrubbers {
+		data = scrubber(data)
+	}
+
+	return data
+}
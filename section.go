@@ -0,0 +1,42 @@
+package golden
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/sivchari/golden/manager"
+)
+
+// AssertSection compares value with the section of the golden file file,
+// so that related snapshots, e.g. a request, its response and the logs,
+// share one golden file. Sections are delimited by "-- section --" lines,
+// and update mode rewrites only the asserted section.
+func (g *Golden) AssertSection(file, section string, value interface{}) {
+	g.t.Helper()
+
+	filename := g.goldenFilename(file, "txt")
+	actual := g.scrub(g.formatValue(value))
+
+	if g.options.Update {
+		g.checkStrict(filename, actual)
+		g.checkWritable(filename)
+		g.checkMutable(filename)
+
+		if err := g.manager.WriteSection(filename, section, actual); err != nil {
+			g.t.Fatalf("Failed to write section %s of golden file %s: %v", section, filename, err)
+		}
+
+		return
+	}
+
+	expected, err := g.manager.ReadSection(filename, section)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, manager.ErrSectionNotFound) {
+			g.t.Fatalf("Section %s of golden file %s does not exist. Run with update mode to create it.", section, filename)
+		}
+
+		g.t.Fatalf("Failed to read section %s of golden file %s: %v", section, filename, err)
+	}
+
+	g.verify(filename, section, expected, actual, nil)
+}
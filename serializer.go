@@ -0,0 +1,142 @@
+package golden
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sync"
+)
+
+// Serializer converts structured values into golden file content.
+// Extension names the format, e.g. "json" or "msgpack", and is the key the
+// serializer is registered under.
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Extension() string
+}
+
+var (
+	serializersMu sync.RWMutex
+	serializers   = map[string]Serializer{
+		"json":   jsonSerializer{defaultJSONStyle},
+		"yaml":   yamlSerializer{},
+		"toml":   tomlSerializer{},
+		"xml":    xmlSerializer{},
+		"txt":    textSerializer{},
+		"godump": dumpSerializer{},
+	}
+)
+
+// RegisterSerializer registers s under its extension, replacing any
+// serializer registered before, including the built-in ones. It is safe for
+// concurrent use, but is best called from init or TestMain.
+func RegisterSerializer(s Serializer) {
+	serializersMu.Lock()
+	defer serializersMu.Unlock()
+
+	serializers[s.Extension()] = s
+}
+
+// LookupSerializer returns the serializer registered under extension.
+func LookupSerializer(extension string) (Serializer, bool) {
+	serializersMu.RLock()
+	defer serializersMu.RUnlock()
+
+	s, ok := serializers[extension]
+
+	return s, ok
+}
+
+// extension returns the extension the serializer of f is registered under.
+func (f Format) extension() string {
+	switch f {
+	case YAML:
+		return "yaml"
+	case TOML:
+		return "toml"
+	case XML:
+		return "xml"
+	case Text:
+		return "txt"
+	case GoDump:
+		return "godump"
+	default:
+		return "json"
+	}
+}
+
+// serializer returns the serializer set with WithSerializer, or the one
+// registered for the configured format.
+func (g *Golden) serializer() Serializer {
+	if g.options.Serializer != nil {
+		return g.options.Serializer
+	}
+
+	s, ok := LookupSerializer(g.options.Format.extension())
+	if !ok {
+		return jsonSerializer{g.jsonStyle()}
+	}
+
+	// Built-in serializers follow their options
+	switch s.(type) {
+	case jsonSerializer:
+		return jsonSerializer{g.jsonStyle()}
+	case dumpSerializer:
+		return dumpSerializer{unexported: g.options.UnexportedFields}
+	default:
+		return s
+	}
+}
+
+// jsonStyle returns the JSON layout set by the options.
+func (g *Golden) jsonStyle() jsonStyle {
+	return jsonStyle{
+		prefix:     g.options.JSONPrefix,
+		indent:     g.options.JSONIndent,
+		compact:    g.options.CompactJSON,
+		escapeHTML: g.options.EscapeHTML,
+	}
+}
+
+// jsonSerializer writes JSON in its style.
+type jsonSerializer struct {
+	style jsonStyle
+}
+
+func (s jsonSerializer) Marshal(v interface{}) ([]byte, error) {
+	return marshalJSON(v, s.style)
+}
+
+func (jsonSerializer) Extension() string { return "json" }
+
+// yamlSerializer writes YAML, see marshalYAML.
+type yamlSerializer struct{}
+
+func (yamlSerializer) Marshal(v interface{}) ([]byte, error) { return marshalYAML(v) }
+
+func (yamlSerializer) Extension() string { return "yaml" }
+
+// tomlSerializer writes TOML, see marshalTOML.
+type tomlSerializer struct{}
+
+func (tomlSerializer) Marshal(v interface{}) ([]byte, error) { return marshalTOML(v) }
+
+func (tomlSerializer) Extension() string { return "toml" }
+
+// xmlSerializer writes indented XML.
+type xmlSerializer struct{}
+
+func (xmlSerializer) Marshal(v interface{}) ([]byte, error) {
+	return xml.MarshalIndent(v, "", "  ") //nolint:wrapcheck // Callers fall back on any error
+}
+
+func (xmlSerializer) Extension() string { return "xml" }
+
+// textSerializer writes Go's default representation of values. It is also
+// the fallback of every other serializer.
+type textSerializer struct{}
+
+func (textSerializer) Marshal(v interface{}) ([]byte, error) {
+	return []byte(fmt.Sprintf("%+v", v)), nil
+}
+
+func (textSerializer) Extension() string { return "txt" }
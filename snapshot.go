@@ -0,0 +1,54 @@
+package golden
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// snapshotName is the golden name of the snapshots taken by Snapshot,
+// followed by their number within the test.
+const snapshotName = "snapshot"
+
+var (
+	// snapshotMu guards snapshotCounts.
+	snapshotMu sync.Mutex
+	// snapshotCounts counts the snapshots taken by every running test.
+	snapshotCounts = make(map[testing.TB]int)
+)
+
+// Snapshot compares value with the next golden file of the test, like
+// Assert with a generated name, so that quick tests do not need to invent
+// names. The n-th snapshot of a test, counting those of all its Golden
+// instances, gets the golden name snapshot_<n>, which the naming strategy
+// combines with the test and subtest names as usual, e.g.
+// render_test_TestRender__empty_snapshot_1.golden.go. Inserting a snapshot
+// renumbers the following ones, so prefer Assert for long-lived tests.
+func (g *Golden) Snapshot(value any) {
+	g.t.Helper()
+
+	g.Assert(snapshotName+"_"+strconv.Itoa(g.nextSnapshot()), value)
+}
+
+// nextSnapshot returns the number of the next snapshot of the test. Counts
+// are dropped when the test finishes, so that reruns, e.g. with -count=2,
+// start over.
+func (g *Golden) nextSnapshot() int {
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+
+	count, started := snapshotCounts[g.t]
+	if !started {
+		g.t.Cleanup(func() {
+			snapshotMu.Lock()
+			defer snapshotMu.Unlock()
+
+			delete(snapshotCounts, g.t)
+		})
+	}
+
+	count++
+	snapshotCounts[g.t] = count
+
+	return count
+}
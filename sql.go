@@ -0,0 +1,273 @@
+package golden
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SQLParams selects how AssertSQL renders bind parameters.
+type SQLParams int
+
+const (
+	// SQLParamsList keeps placeholders and lists the arguments after the
+	// query. It is the default.
+	SQLParamsList SQLParams = iota
+	// SQLParamsInline replaces placeholders with their arguments as SQL
+	// literals.
+	SQLParamsInline
+	// SQLParamsLabel keeps placeholders and labels each with its argument
+	// in a comment, e.g. "$1 /* 'alice' */".
+	SQLParamsLabel
+)
+
+// sqlKeywords are the keywords AssertSQL uppercases.
+var sqlKeywords = map[string]bool{
+	"all": true, "alter": true, "and": true, "as": true, "asc": true, "between": true, "by": true,
+	"case": true, "create": true, "cross": true, "delete": true, "desc": true, "distinct": true,
+	"drop": true, "else": true, "end": true, "except": true, "exists": true, "false": true,
+	"fetch": true, "for": true, "from": true, "full": true, "group": true, "having": true,
+	"ilike": true, "in": true, "inner": true, "insert": true, "intersect": true, "into": true,
+	"is": true, "join": true, "left": true, "like": true, "limit": true, "not": true, "null": true,
+	"offset": true, "on": true, "or": true, "order": true, "outer": true, "returning": true,
+	"right": true, "select": true, "set": true, "table": true, "then": true, "true": true,
+	"union": true, "update": true, "using": true, "values": true, "when": true, "where": true,
+	"with": true,
+}
+
+// AssertSQL compares query with the golden file name after normalizing it:
+// whitespace is collapsed and keywords are uppercased outside of literals,
+// quoted identifiers and comments. args are rendered as set with
+// WithSQLParams. Placeholders may be written as ?, $1, :name or @name, the
+// latter two taking their value from a sql.NamedArg.
+func (g *Golden) AssertSQL(name, query string, args ...any) {
+	g.t.Helper()
+
+	g.assertBytes(name, []byte(formatSQL(query, args, g.options.SQLParams)))
+}
+
+// formatSQL normalizes query and renders its args in mode.
+func formatSQL(query string, args []any, mode SQLParams) string {
+	var (
+		out  strings.Builder
+		next int    // Index of the next positional argument
+		sep  string // Separator owed before the next token
+	)
+
+	for i := 0; i < len(query); {
+		r := rune(query[i])
+		end := sqlTokenEnd(query, i)
+		token := query[i:end]
+
+		switch {
+		case unicode.IsSpace(r):
+			if sep == "" && out.Len() > 0 {
+				sep = " "
+			}
+
+			i = end
+
+			continue
+		case isPlaceholder(query, i):
+			arg, ok := placeholderArg(token, args, &next)
+			token = renderPlaceholder(token, arg, ok, mode)
+		case isIdentStart(r) && sqlKeywords[strings.ToLower(token)]:
+			token = strings.ToUpper(token)
+		}
+
+		out.WriteString(sep)
+		out.WriteString(token)
+
+		// A line comment must not swallow the rest of the query
+		sep = ""
+		if strings.HasPrefix(token, "--") {
+			sep = "\n"
+		}
+
+		i = end
+	}
+
+	if mode == SQLParamsList {
+		for i, arg := range args {
+			fmt.Fprintf(&out, "\n-- %s", argLabel(i, arg))
+		}
+	}
+
+	return out.String()
+}
+
+// sqlTokenEnd returns the end of the token of query starting at i.
+func sqlTokenEnd(query string, i int) int {
+	switch c := query[i]; {
+	case c == '\'' || c == '"' || c == '`':
+		return quotedEnd(query, i, c)
+	case strings.HasPrefix(query[i:], "--"):
+		if n := strings.IndexByte(query[i:], '\n'); n >= 0 {
+			return i + n
+		}
+
+		return len(query)
+	case strings.HasPrefix(query[i:], "/*"):
+		if n := strings.Index(query[i+2:], "*/"); n >= 0 {
+			return i + 2 + n + 2
+		}
+
+		return len(query)
+	case c == '?' || c == '$' || c == ':' || c == '@' || isIdentStart(rune(c)) || unicode.IsSpace(rune(c)):
+		end := i + 1
+		for end < len(query) && sameTokenClass(rune(c), rune(query[end])) {
+			end++
+		}
+
+		return end
+	default:
+		return i + 1
+	}
+}
+
+// sameTokenClass reports whether r continues a token starting with first.
+func sameTokenClass(first, r rune) bool {
+	switch {
+	case unicode.IsSpace(first):
+		return unicode.IsSpace(r)
+	case first == '?':
+		return false
+	default:
+		return isIdentStart(r) || unicode.IsDigit(r)
+	}
+}
+
+// quotedEnd returns the end of the literal or identifier quoted with quote
+// at i. Doubled quotes escape the quote.
+func quotedEnd(query string, i int, quote byte) int {
+	for j := i + 1; j < len(query); j++ {
+		if query[j] != quote {
+			continue
+		}
+
+		if j+1 < len(query) && query[j+1] == quote {
+			j++
+
+			continue
+		}
+
+		return j + 1
+	}
+
+	return len(query)
+}
+
+// isPlaceholder reports whether a bind parameter starts at i. ":" is only a
+// placeholder when followed by a name and not part of a "::" cast.
+func isPlaceholder(query string, i int) bool {
+	switch query[i] {
+	case '?':
+		return true
+	case '$':
+		return i+1 < len(query) && unicode.IsDigit(rune(query[i+1]))
+	case ':', '@':
+		if i > 0 && (query[i-1] == ':' || isIdentStart(rune(query[i-1]))) {
+			return false
+		}
+
+		return i+1 < len(query) && isIdentStart(rune(query[i+1]))
+	default:
+		return false
+	}
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// placeholderArg returns the argument bound to placeholder.
+func placeholderArg(placeholder string, args []any, next *int) (any, bool) {
+	index := *next
+
+	switch placeholder[0] {
+	case '$':
+		n, err := strconv.Atoi(placeholder[1:])
+		if err != nil {
+			return nil, false
+		}
+
+		index = n - 1
+	case ':', '@':
+		for _, arg := range args {
+			if named, ok := arg.(sql.NamedArg); ok && named.Name == placeholder[1:] {
+				return named.Value, true
+			}
+		}
+	}
+
+	*next = index + 1
+
+	if index < 0 || index >= len(args) {
+		return nil, false
+	}
+
+	if named, ok := args[index].(sql.NamedArg); ok {
+		return named.Value, true
+	}
+
+	return args[index], true
+}
+
+// renderPlaceholder renders placeholder bound to arg in mode.
+func renderPlaceholder(placeholder string, arg any, ok bool, mode SQLParams) string {
+	if !ok {
+		return placeholder
+	}
+
+	switch mode {
+	case SQLParamsInline:
+		return sqlLiteral(arg)
+	case SQLParamsLabel:
+		return placeholder + " /* " + sqlLiteral(arg) + " */"
+	default:
+		return placeholder
+	}
+}
+
+// argLabel labels the argument at index i for SQLParamsList.
+func argLabel(i int, arg any) string {
+	if named, ok := arg.(sql.NamedArg); ok {
+		return ":" + named.Name + " = " + sqlLiteral(named.Value)
+	}
+
+	return "$" + strconv.Itoa(i+1) + " = " + sqlLiteral(arg)
+}
+
+// sqlLiteral renders arg as an SQL literal.
+func sqlLiteral(arg any) string {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return fmt.Sprintf("<%v>", err)
+		}
+
+		arg = value
+	}
+
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return "X'" + hex.EncodeToString(v) + "'"
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	case time.Time:
+		return "'" + v.Format(time.RFC3339Nano) + "'"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	default:
+		return sqlLiteral(fmt.Sprint(v))
+	}
+}
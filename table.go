@@ -0,0 +1,299 @@
+package golden
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// errUnsupportedTable is returned for tables of an unsupported type.
+var errUnsupportedTable = errors.New("unsupported table type")
+
+// XLSXSheet selects a sheet of an xlsx workbook for AssertTable.
+type XLSXSheet struct {
+	Path  string // Path of the workbook
+	Sheet string // Name of the sheet, the first sheet if empty
+}
+
+// AssertTable compares a table with the golden file name. table is a
+// [][]string whose first row is the header, a *sql.Rows, which is consumed
+// and closed, or an XLSXSheet. The table is stored with aligned columns and
+// numbers in a stable format, e.g. "1.50" as "1.5". WithTableIgnoreColumns
+// and WithUnorderedRows apply to both sides when comparing, so the golden
+// file keeps the full table.
+func (g *Golden) AssertTable(name string, table any) {
+	g.t.Helper()
+
+	rows, err := readTable(table)
+	if err != nil {
+		g.t.Fatalf("Failed to read table: %v", err)
+	}
+
+	filename := g.goldenFilename(name, "txt")
+	actual := g.scrub(formatTable(normalizeTable(rows)))
+
+	if g.options.Update {
+		g.checkMutable(filename)
+		g.writeGolden(filename, actual)
+
+		return
+	}
+
+	expected, err := g.readGolden(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			g.t.Fatalf("Golden file %s does not exist. Run with update mode to create it.", filename)
+		}
+
+		g.t.Fatalf("Failed to read golden file %s: %v", filename, err)
+	}
+
+	g.verify(filename, "", g.tableView(expected), g.tableView(actual), nil)
+}
+
+// readTable returns the rows of table, header first.
+func readTable(table any) ([][]string, error) {
+	switch t := table.(type) {
+	case [][]string:
+		return t, nil
+	case *sql.Rows:
+		return readSQLRows(t)
+	case XLSXSheet:
+		return readXLSX(t.Path, t.Sheet)
+	default:
+		return nil, fmt.Errorf("%w: %T", errUnsupportedTable, table)
+	}
+}
+
+// readSQLRows reads the column names and values of rows and closes it.
+func readSQLRows(rows *sql.Rows) ([][]string, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	table := [][]string{columns}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = sqlCell(v)
+		}
+
+		table = append(table, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return table, nil
+}
+
+// sqlCell renders a scanned column value.
+func sqlCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// normalizeTable pads rows to the same width and formats decimal numbers
+// stably.
+func normalizeTable(rows [][]string) [][]string {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	normalized := make([][]string, len(rows))
+
+	for i, row := range rows {
+		normalized[i] = make([]string, width)
+		for j, cell := range row {
+			normalized[i][j] = normalizeNumber(cell)
+		}
+	}
+
+	return normalized
+}
+
+// normalizeNumber formats cells holding a decimal or exponent number in
+// their shortest form, the way encoding/json formats floats. Integers are
+// kept as is, so that identifiers such as "007" survive.
+func normalizeNumber(cell string) string {
+	if !strings.ContainsAny(cell, ".eE") {
+		return cell
+	}
+
+	f, err := strconv.ParseFloat(cell, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return cell
+	}
+
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		return strconv.FormatFloat(f, 'e', -1, 64)
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// tableView returns the formatted table data without ignored columns and,
+// with WithUnorderedRows, with sorted rows.
+func (g *Golden) tableView(data []byte) []byte {
+	rows := parseTable(data)
+	if len(rows) == 0 {
+		return data
+	}
+
+	if len(g.options.TableIgnoreColumns) > 0 {
+		rows = dropColumns(rows, g.options.TableIgnoreColumns)
+	}
+
+	if g.options.UnorderedRows {
+		slices.SortFunc(rows[1:], slices.Compare)
+	}
+
+	return formatTable(rows)
+}
+
+// dropColumns removes the columns whose header is in names.
+func dropColumns(rows [][]string, names []string) [][]string {
+	kept := make([][]string, len(rows))
+
+	for i, row := range rows {
+		for j, cell := range row {
+			if j >= len(rows[0]) || !slices.Contains(names, rows[0][j]) {
+				kept[i] = append(kept[i], cell)
+			}
+		}
+	}
+
+	return kept
+}
+
+// formatTable renders rows as a table with aligned columns and a separator
+// below the header. "|", "\" and line breaks in cells are escaped.
+func formatTable(rows [][]string) []byte {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	cells := make([][]string, len(rows))
+	widths := make([]int, width)
+
+	for i, row := range rows {
+		cells[i] = make([]string, len(row))
+		for j, cell := range row {
+			cells[i][j] = escapeCell(cell)
+			widths[j] = max(widths[j], utf8.RuneCountInString(cells[i][j]))
+		}
+	}
+
+	var buf strings.Builder
+
+	for i, row := range cells {
+		buf.WriteString("|")
+
+		for j, cell := range row {
+			fmt.Fprintf(&buf, " %s%s |", cell, strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell)))
+		}
+
+		buf.WriteString("\n")
+
+		if i == 0 {
+			buf.WriteString("|")
+
+			for _, w := range widths {
+				buf.WriteString(strings.Repeat("-", w+2) + "|")
+			}
+
+			buf.WriteString("\n")
+		}
+	}
+
+	return []byte(buf.String())
+}
+
+// parseTable parses the output of formatTable. Cells are trimmed of
+// surrounding whitespace.
+func parseTable(data []byte) [][]string {
+	var rows [][]string
+
+	for i, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		if line == "" || (i == 1 && strings.Trim(line, "|-") == "") {
+			continue
+		}
+
+		line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+
+		var (
+			row  []string
+			cell strings.Builder
+		)
+
+		for j := 0; j < len(line); j++ {
+			switch {
+			case line[j] == '\\' && j+1 < len(line):
+				j++
+				cell.WriteByte(unescapeCell(line[j]))
+			case line[j] == '|':
+				row = append(row, strings.TrimSpace(cell.String()))
+				cell.Reset()
+			default:
+				cell.WriteByte(line[j])
+			}
+		}
+
+		rows = append(rows, append(row, strings.TrimSpace(cell.String())))
+	}
+
+	return rows
+}
+
+// escapeCell escapes the characters of cell that would break the table.
+func escapeCell(cell string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", `\n`, "\r", `\r`).Replace(cell)
+}
+
+// unescapeCell returns the character escaped as c by escapeCell.
+func unescapeCell(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	default:
+		return c
+	}
+}
@@ -0,0 +1,305 @@
+package golden
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureTB is the testing.TB of assertions run apart from the test
+// goroutine: by Check, to report failures as errors of the test, by Compare,
+// outside of tests, and by Watcher, to print failures without failing the
+// watching test. It records failures, stops the assertion at the first
+// fatal one and runs cleanups once the assertion is done.
+//
+// Methods needing a test, such as Setenv, are delegated to the test the
+// assertion runs for, and fail the assertion when there is none.
+type captureTB struct {
+	testing.TB // Test the assertion runs for, or nil outside of tests
+
+	out io.Writer // Where failures and logs are printed, if not nil
+
+	ctx    context.Context //nolint:containedctx // Canceled when the assertion is done, like testing.T.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	failed   bool
+	skipped  bool
+	failures []string
+	cleanups []func()
+}
+
+// newCaptureTB returns a captureTB running assertions for parent, which may
+// be nil outside of tests, printing failures and logs to out if not nil.
+func newCaptureTB(parent testing.TB, out io.Writer) *captureTB {
+	ctx := context.Background()
+	if parent != nil {
+		ctx = parent.Context()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &captureTB{TB: parent, out: out, ctx: ctx, cancel: cancel}
+}
+
+// run runs fn in its own goroutine, so that FailNow and SkipNow stop fn
+// rather than the caller, then runs the registered cleanups.
+func (c *captureTB) run(fn func(tb testing.TB)) {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer c.runCleanups()
+		defer c.cancel()
+
+		fn(c)
+	}()
+
+	<-done
+}
+
+// Helper is a no-op, as failures are reported without locations.
+func (c *captureTB) Helper() {}
+
+// Name returns the name of the test, or an empty name outside of tests, so
+// that golden files are named after the caller.
+func (c *captureTB) Name() string {
+	if c.TB == nil {
+		return ""
+	}
+
+	return c.TB.Name()
+}
+
+// Log prints args, logs them to the test or discards them.
+func (c *captureTB) Log(args ...any) {
+	c.log(fmt.Sprintln(args...))
+}
+
+// Logf prints a formatted message, logs it to the test or discards it.
+func (c *captureTB) Logf(format string, args ...any) {
+	c.log(fmt.Sprintf(format, args...))
+}
+
+// Error records args as a failure.
+func (c *captureTB) Error(args ...any) {
+	c.record(fmt.Sprint(args...))
+}
+
+// Errorf records a formatted failure.
+func (c *captureTB) Errorf(format string, args ...any) {
+	c.record(fmt.Sprintf(format, args...))
+}
+
+// Fatal records args as a failure and stops the assertion.
+func (c *captureTB) Fatal(args ...any) {
+	c.Error(args...)
+	c.FailNow()
+}
+
+// Fatalf records a formatted failure and stops the assertion.
+func (c *captureTB) Fatalf(format string, args ...any) {
+	c.Errorf(format, args...)
+	c.FailNow()
+}
+
+// Fail marks the assertion failed.
+func (c *captureTB) Fail() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failed = true
+}
+
+// FailNow marks the assertion failed and stops it.
+func (c *captureTB) FailNow() {
+	c.Fail()
+	runtime.Goexit()
+}
+
+// Failed reports whether the assertion failed.
+func (c *captureTB) Failed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.failed
+}
+
+// Skip logs args and stops the assertion.
+func (c *captureTB) Skip(args ...any) {
+	c.Log(args...)
+	c.SkipNow()
+}
+
+// Skipf logs a formatted message and stops the assertion.
+func (c *captureTB) Skipf(format string, args ...any) {
+	c.Logf(format, args...)
+	c.SkipNow()
+}
+
+// SkipNow marks the assertion skipped and stops it.
+func (c *captureTB) SkipNow() {
+	c.mu.Lock()
+	c.skipped = true
+	c.mu.Unlock()
+
+	runtime.Goexit()
+}
+
+// Skipped reports whether the assertion was skipped.
+func (c *captureTB) Skipped() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.skipped
+}
+
+// Cleanup registers fn to run once the assertion is done.
+func (c *captureTB) Cleanup(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cleanups = append(c.cleanups, fn)
+}
+
+// Context returns a context canceled once the assertion is done, before
+// the cleanups run.
+func (c *captureTB) Context() context.Context {
+	return c.ctx
+}
+
+// TempDir returns a directory of the test, or a new directory removed once
+// the assertion is done outside of tests.
+func (c *captureTB) TempDir() string {
+	if c.TB != nil {
+		return c.TB.TempDir()
+	}
+
+	dir, err := os.MkdirTemp("", "golden")
+	if err != nil {
+		c.Fatalf("TempDir: %v", err)
+	}
+
+	c.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	return dir
+}
+
+// Setenv sets an environment variable for the test. Outside of tests it
+// fails the assertion, as it would change the environment of the process.
+func (c *captureTB) Setenv(key, value string) {
+	if c.TB == nil {
+		c.unsupported("Setenv")
+	}
+
+	c.TB.Setenv(key, value)
+}
+
+// Chdir changes the working directory for the test. Outside of tests it
+// fails the assertion, as it would change the working directory of the
+// process.
+func (c *captureTB) Chdir(dir string) {
+	if c.TB == nil {
+		c.unsupported("Chdir")
+	}
+
+	c.TB.Chdir(dir)
+}
+
+// ArtifactDir returns the artifact directory of the test. Outside of tests,
+// or with a Go version without artifacts, it fails the assertion.
+func (c *captureTB) ArtifactDir() string {
+	tb, ok := c.TB.(interface{ ArtifactDir() string })
+	if !ok {
+		c.unsupported("ArtifactDir")
+	}
+
+	return tb.ArtifactDir()
+}
+
+// Attr records an attribute on the test, or logs it outside of tests.
+func (c *captureTB) Attr(key, value string) {
+	if tb, ok := c.TB.(interface{ Attr(key, value string) }); ok {
+		tb.Attr(key, value)
+
+		return
+	}
+
+	c.Logf("=== ATTR %s %s", key, value)
+}
+
+// Output returns a writer for log output, like Log.
+func (c *captureTB) Output() io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		c.log(string(p))
+
+		return len(p), nil
+	})
+}
+
+// unsupported fails the assertion for calling method outside of tests.
+func (c *captureTB) unsupported(method string) {
+	c.Fatalf("%s is not supported outside of tests", method)
+}
+
+// record records msg as a failure, printing it if there is an output.
+func (c *captureTB) record(msg string) {
+	if c.out != nil {
+		c.log(msg)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failed = true
+	c.failures = append(c.failures, msg)
+}
+
+// log prints msg to the output, terminated by a newline, or logs it to the
+// test. Outside of tests without an output, msg is discarded.
+func (c *captureTB) log(msg string) {
+	switch {
+	case c.out != nil:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		fmt.Fprint(c.out, strings.TrimSuffix(msg, "\n")+"\n")
+	case c.TB != nil:
+		c.TB.Log(strings.TrimSuffix(msg, "\n"))
+	}
+}
+
+// messages returns the recorded failures.
+func (c *captureTB) messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.failures
+}
+
+// runCleanups runs the registered cleanups in reverse order.
+func (c *captureTB) runCleanups() {
+	c.mu.Lock()
+	cleanups := c.cleanups
+	c.cleanups = nil
+	c.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// writerFunc adapts a function to io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+// Write calls f.
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
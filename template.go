@@ -0,0 +1,97 @@
+package golden
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// WhitespacePolicy selects the whitespace normalizations AssertTemplate
+// applies to rendered output before storing and comparing it.
+type WhitespacePolicy int
+
+const (
+	// TrimTrailingSpace removes spaces and tabs at the end of lines.
+	TrimTrailingSpace WhitespacePolicy = 1 << iota
+	// CollapseBlankLines reduces runs of blank lines to a single one.
+	CollapseBlankLines
+	// TrimBlankEdges removes blank lines at the start and end of the output.
+	TrimBlankEdges
+
+	// KeepWhitespace stores the output as rendered.
+	KeepWhitespace WhitespacePolicy = 0
+	// DefaultWhitespacePolicy applies all normalizations. It is the default.
+	DefaultWhitespacePolicy = TrimTrailingSpace | CollapseBlankLines | TrimBlankEdges
+)
+
+// Template is implemented by both html/template and text/template templates.
+type Template interface {
+	Execute(w io.Writer, data any) error
+}
+
+// AssertTemplate executes tmpl with data and compares the output with the
+// golden file name, after normalizing its whitespace as set with
+// WithWhitespacePolicy, so that template refactors do not churn
+// insignificant whitespace. To render a named template, pass the result of
+// its Lookup method.
+func (g *Golden) AssertTemplate(name string, tmpl Template, data any) {
+	g.t.Helper()
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		g.t.Fatalf("Failed to execute template for %s: %v", name, err)
+	}
+
+	g.assertBytes(name, []byte(normalizeWhitespace(buf.String(), g.options.WhitespacePolicy)))
+}
+
+// normalizeWhitespace applies policy to text.
+func normalizeWhitespace(text string, policy WhitespacePolicy) string {
+	if policy == KeepWhitespace {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	kept := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if policy&TrimTrailingSpace != 0 {
+			line = strings.TrimRight(line, " \t\r")
+		}
+
+		blank := strings.TrimSpace(line) == ""
+		if blank && policy&CollapseBlankLines != 0 && len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+			continue
+		}
+
+		kept = append(kept, line)
+	}
+
+	text = strings.Join(kept, "\n")
+	if policy&TrimBlankEdges != 0 {
+		text = trimBlankLines(text)
+	}
+
+	return text
+}
+
+// trimBlankLines removes the blank lines at the start and end of text,
+// keeping a final newline if text has content.
+func trimBlankLines(text string) string {
+	lines := strings.Split(text, "\n")
+
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	if start == end {
+		return ""
+	}
+
+	return strings.Join(lines[start:end], "\n") + "\n"
+}
@@ -0,0 +1,51 @@
+package golden
+
+import (
+	"reflect"
+	"time"
+)
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// timeFormat renders times in one layout and location, and durations in
+// their human form, e.g. "1h30m0s", so that golden files do not depend on
+// the time zone of the machine writing them.
+type timeFormat struct {
+	layout   string
+	location *time.Location
+}
+
+// format renders v if it is a time.Time or time.Duration. A nil format
+// renders nothing.
+func (f *timeFormat) format(v reflect.Value) (string, bool) {
+	if f == nil || !v.CanInterface() {
+		return "", false
+	}
+
+	switch v.Type() {
+	case timeType:
+		return v.Interface().(time.Time).In(f.location).Format(f.layout), true //nolint:forcetypeassert // Checked by the switch
+	case durationType:
+		return v.Interface().(time.Duration).String(), true //nolint:forcetypeassert // Checked by the switch
+	default:
+		return "", false
+	}
+}
+
+// timeFormat returns the format set with WithTimeFormat, or nil.
+func (g *Golden) timeFormat() *timeFormat {
+	if !g.options.FormatTimes {
+		return nil
+	}
+
+	f := &timeFormat{layout: g.options.TimeLayout, location: g.options.TimeLocation}
+	if f.layout == "" {
+		f.layout = time.RFC3339
+	}
+
+	if f.location == nil {
+		f.location = time.UTC
+	}
+
+	return f
+}
@@ -0,0 +1,100 @@
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// variantEnv is the environment variable selecting the golden variant.
+const variantEnv = "GOLDEN_VARIANT"
+
+var (
+	variantMu sync.RWMutex
+	variant   string
+)
+
+// Main runs the package tests with all golden files namespaced under
+// testdata/<variant>, so that variant suites (e.g. unit and integration)
+// cannot overwrite each other's fixtures. Call it from TestMain, typically
+// with a variant constant defined in files guarded by build tags:
+//
+//	func TestMain(m *testing.M) {
+//		golden.Main(m, buildVariant)
+//	}
+//
+// An empty variant falls back to the GOLDEN_VARIANT environment variable.
+// When several golden files fail, they are listed most changed first after
+// the tests. When GOLDEN_REPORT names a file, the run report is written to it
+// after the tests, see WriteReport.
+func Main(m *testing.M, name string) {
+	if name == "" {
+		name = os.Getenv(variantEnv)
+	}
+
+	if err := SetVariant(name); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	if r := currentReport(); len(r.Failures) > 1 {
+		if err := r.WriteFailures(os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	if path := os.Getenv(reportEnv); path != "" {
+		if err := WriteReport(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
+
+// SetVariant sets the variant used to derive the default base directory of
+// all Golden instances in the package. An empty name removes the variant.
+func SetVariant(name string) error {
+	if name != "" && (name != filepath.Base(name) || name == ".." || strings.ContainsAny(name, `/\`)) {
+		return fmt.Errorf("invalid golden variant %q: must be a single directory name", name)
+	}
+
+	variantMu.Lock()
+	defer variantMu.Unlock()
+
+	variant = name
+
+	return nil
+}
+
+// defaultBaseDir returns the base directory used when none is configured.
+func defaultBaseDir() string {
+	variantMu.RLock()
+	defer variantMu.RUnlock()
+
+	return filepath.Join("testdata", variant)
+}
+
+// anchorBaseDir joins baseDir to testDir, the directory of the test source
+// file, so that golden files are found whatever the working directory, e.g.
+// with IDE runners or bazel. baseDir is kept relative when the tests run in
+// testDir, as go test does, or when testDir is unknown or not absolute, as
+// with -trimpath.
+func anchorBaseDir(testDir, baseDir string) string {
+	if !filepath.IsAbs(testDir) {
+		return baseDir
+	}
+
+	if cwd, err := os.Getwd(); err == nil && filepath.Clean(cwd) == filepath.Clean(testDir) {
+		return baseDir
+	}
+
+	return filepath.Join(testDir, baseDir)
+}
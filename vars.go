@@ -0,0 +1,100 @@
+package golden
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// varPattern matches ${NAME} placeholders in golden files.
+var varPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// builtinVars resolve the variables WithEnvVars accepts beyond the
+// environment.
+var builtinVars = map[string]func() string{
+	"PROJECT_ROOT": projectRoot,
+	"HOSTNAME": func() string {
+		hostname, _ := os.Hostname()
+
+		return hostname
+	},
+}
+
+// envVar returns the value of the environment variable name, falling back
+// to the built-in variable of that name.
+func envVar(name string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	if resolve, ok := builtinVars[name]; ok {
+		return resolve()
+	}
+
+	return ""
+}
+
+// projectRoot returns the closest directory containing a go.mod file,
+// starting from the working directory.
+func projectRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// expandVars replaces the ${NAME} placeholders of configured variables in
+// golden content with their values. Other placeholders are kept as is.
+func (g *Golden) expandVars(data []byte) []byte {
+	if len(g.options.Vars) == 0 {
+		return data
+	}
+
+	return varPattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		if value, ok := g.options.Vars[string(match[2:len(match)-1])]; ok {
+			return []byte(value)
+		}
+
+		return match
+	})
+}
+
+// collapseVars replaces the values of configured variables in actual
+// output with their ${NAME} placeholders, longest values first, so that
+// written golden files stay portable.
+func (g *Golden) collapseVars(data []byte) []byte {
+	names := make([]string, 0, len(g.options.Vars))
+	for name := range g.options.Vars {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, b := g.options.Vars[names[i]], g.options.Vars[names[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		data = bytes.ReplaceAll(data, []byte(g.options.Vars[name]), []byte("${"+name+"}"))
+	}
+
+	return data
+}
@@ -0,0 +1,133 @@
+package golden
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// defaultWatchInterval is how often a Watcher polls for changes by default.
+const defaultWatchInterval = 500 * time.Millisecond
+
+// Watcher re-runs golden comparisons whenever watched files change, giving
+// a tight feedback loop while iterating on generator output. It is meant for
+// long-running tests enabled explicitly, e.g.:
+//
+//	func TestWatch(t *testing.T) {
+//		if os.Getenv("GOLDEN_WATCH") == "" {
+//			t.Skip("set GOLDEN_WATCH=1 to watch")
+//		}
+//
+//		w := &golden.Watcher{Paths: []string{"testdata", "templates"}}
+//		w.Run(t.Context(), t, func(tb testing.TB) {
+//			golden.New(tb).Assert("output", generate())
+//		})
+//	}
+//
+// run with go test -run TestWatch -timeout 0.
+type Watcher struct {
+	Paths    []string      // Files and directories to watch, recursively
+	Interval time.Duration // Polling interval (default: 500ms)
+	Output   io.Writer     // Where results are printed (default: os.Stdout)
+}
+
+// Run runs fn, then runs it again every time a watched file changes, until
+// ctx is done. Failures of fn, including their diffs, are printed instead of
+// failing tb, so that the loop keeps going.
+func (w *Watcher) Run(ctx context.Context, tb testing.TB, fn func(tb testing.TB)) {
+	tb.Helper()
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	out := w.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for run := 1; ; run++ {
+		w.runOnce(tb, out, run, fn)
+
+		// Files written by fn itself, e.g. updated golden files, are not changes
+		last := w.snapshot()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if !sameSnapshot(last, w.snapshot()) {
+				break
+			}
+		}
+	}
+}
+
+// runOnce runs fn with a captureTB printing to out and prints its outcome.
+func (w *Watcher) runOnce(tb testing.TB, out io.Writer, run int, fn func(tb testing.TB)) {
+	wtb := newCaptureTB(tb, out)
+	wtb.run(fn)
+
+	status := "PASS"
+	if wtb.Failed() {
+		status = "FAIL"
+	}
+
+	fmt.Fprintf(out, "=== golden watch: run %d: %s\n", run, status)
+}
+
+// fileState identifies a version of a watched file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshot returns the state of the files under the watched paths.
+// Unreadable paths are skipped, as they may be rewritten at the moment.
+func (w *Watcher) snapshot() map[string]fileState {
+	files := make(map[string]fileState)
+
+	for _, root := range w.Paths {
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || strings.HasSuffix(path, ".tmp") {
+				return nil //nolint:nilerr // Skip files that disappear while walking
+			}
+
+			if info, err := entry.Info(); err == nil {
+				files[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+			}
+
+			return nil
+		})
+	}
+
+	return files
+}
+
+// sameSnapshot reports whether two snapshots hold the same file states.
+func sameSnapshot(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for path, state := range a {
+		if other, ok := b[path]; !ok || other.size != state.size || !other.modTime.Equal(state.modTime) {
+			return false
+		}
+	}
+
+	return true
+}
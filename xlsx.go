@@ -0,0 +1,210 @@
+package golden
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// errSheetNotFound is returned for a sheet missing from a workbook.
+var errSheetNotFound = errors.New("sheet not found")
+
+// xlsxWorkbook is xl/workbook.xml.
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships is xl/_rels/workbook.xml.rels.
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a rich or plain text element.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String concatenates the text and its runs.
+func (t xlsxText) String() string {
+	var buf strings.Builder
+
+	buf.WriteString(t.T)
+
+	for _, run := range t.Runs {
+		buf.WriteString(run.T)
+	}
+
+	return buf.String()
+}
+
+// xlsxSharedStrings is xl/sharedStrings.xml.
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxWorksheet is a worksheet part.
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// xlsxCell is a worksheet cell.
+type xlsxCell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline xlsxText `xml:"is"`
+}
+
+// readXLSX reads the rows of sheet, or of the first sheet if empty, from
+// the xlsx workbook at file. Cells are read as stored, so dates appear as
+// serial numbers and formulas as their cached result.
+func readXLSX(file, sheet string) ([][]string, error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook %s: %w", file, err)
+	}
+
+	defer zr.Close()
+
+	part, err := xlsxSheetPart(&zr.Reader, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if err := decodeZipXML(&zr.Reader, "xl/sharedStrings.xml", &shared); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var ws xlsxWorksheet
+	if err := decodeZipXML(&zr.Reader, part, &ws); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(ws.Rows))
+
+	for _, r := range ws.Rows {
+		var row []string
+
+		for i, c := range r.Cells {
+			col := xlsxColumn(c.Ref, i)
+			for len(row) <= col {
+				row = append(row, "")
+			}
+
+			row[col] = xlsxValue(c, shared.Items)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// xlsxSheetPart returns the zip entry holding sheet.
+func xlsxSheetPart(zr *zip.Reader, sheet string) (string, error) {
+	var wb xlsxWorkbook
+	if err := decodeZipXML(zr, "xl/workbook.xml", &wb); err != nil {
+		return "", err
+	}
+
+	var rels xlsxRelationships
+	if err := decodeZipXML(zr, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, s := range wb.Sheets {
+		if sheet != "" && s.Name != sheet {
+			continue
+		}
+
+		for _, rel := range rels.Relationships {
+			if rel.ID != s.RID {
+				continue
+			}
+
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", errSheetNotFound, sheet)
+}
+
+// decodeZipXML decodes the XML entry name of zr into v.
+func decodeZipXML(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+
+	defer f.Close()
+
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// xlsxColumn returns the zero-based column of a cell reference such as
+// "AB12", or fallback if the reference is missing.
+func xlsxColumn(ref string, fallback int) int {
+	col := 0
+
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+
+		col = col*26 + int(r-'A') + 1
+	}
+
+	if col == 0 {
+		return fallback
+	}
+
+	return col - 1
+}
+
+// xlsxValue returns the text of c.
+func xlsxValue(c xlsxCell, shared []xlsxText) string {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(c.Value)
+		if err != nil || i < 0 || i >= len(shared) {
+			return c.Value
+		}
+
+		return shared[i].String()
+	case "inlineStr":
+		return c.Inline.String()
+	case "b":
+		if c.Value == "1" {
+			return "TRUE"
+		}
+
+		return "FALSE"
+	default:
+		return c.Value
+	}
+}